@@ -0,0 +1,102 @@
+package npmstart
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// WriteFileAtomic writes the file at path with the content that write
+// produces. The content goes to a temporary file in the same directory,
+// which is synced and then renamed over path, so that path either holds the
+// complete content or is left as it was: a build that fails or is killed
+// partway never leaves a truncated file behind.
+func WriteFileAtomic(path string, perm os.FileMode, write func(io.Writer) error) error {
+	dir := filepath.Dir(path)
+
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	err = writeTemp(file, perm, write)
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+func writeTemp(file *os.File, perm os.FileMode, write func(io.Writer) error) error {
+	err := write(file)
+	if err == nil {
+		err = file.Chmod(perm)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+
+	return closeErr
+}
+
+// syncDir syncs the directory entry of a renamed file, so that the rename
+// outlives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// copyFileAtomic copies the file at source to destination with
+// WriteFileAtomic, keeping the permissions of source.
+func copyFileAtomic(source, destination string) error {
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(destination, info.Mode().Perm(), func(w io.Writer) error {
+		_, err := io.Copy(w, file)
+		return err
+	})
+}
+
+// resetLayer returns the named layer emptied for rewriting. The metadata file
+// that a previous build left for the layer is removed along with its
+// content: packit writes it anew only when the build succeeds, so a build
+// that fails partway leaves no metadata claiming the layer is complete.
+func resetLayer(layers packit.Layers, name string) (packit.Layer, error) {
+	layer, err := layers.Get(name)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	err = os.Remove(layer.Path + ".toml")
+	if err != nil && !os.IsNotExist(err) {
+		return packit.Layer{}, fmt.Errorf("failed to remove the metadata of the %s layer: %w", name, err)
+	}
+
+	return layer.Reset()
+}
@@ -0,0 +1,91 @@
+package npmstart_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testAtomic(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		dir  string
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "atomic")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(dir, "start-command.sh")
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	it("writes the file with the given permissions", func() {
+		err := npmstart.WriteFileAtomic(path, 0755, func(w io.Writer) error {
+			_, err := io.WriteString(w, "some-content")
+			return err
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal("some-content"))
+
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+
+		entries, err := os.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+
+	context("when the write fails partway", func() {
+		it("leaves no partial file behind", func() {
+			err := npmstart.WriteFileAtomic(path, 0755, func(w io.Writer) error {
+				_, err := io.WriteString(w, "some-")
+				Expect(err).NotTo(HaveOccurred())
+
+				return errors.New("some-write-error")
+			})
+			Expect(err).To(MatchError("some-write-error"))
+
+			entries, err := os.ReadDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+
+		it("keeps the previous content of the file", func() {
+			Expect(os.WriteFile(path, []byte("previous-content"), 0600)).To(Succeed())
+
+			err := npmstart.WriteFileAtomic(path, 0755, func(w io.Writer) error {
+				_, err := io.WriteString(w, "some-")
+				Expect(err).NotTo(HaveOccurred())
+
+				return errors.New("some-write-error")
+			})
+			Expect(err).To(MatchError("some-write-error"))
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("previous-content"))
+
+			entries, err := os.ReadDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+		})
+	})
+}
@@ -1,113 +1,1079 @@
 package npmstart
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
 	"github.com/paketo-buildpacks/packit/v2/scribe"
 )
 
 func Build(pathParser PathParser, logger scribe.Emitter) packit.BuildFunc {
+	return BuildWithContext(context.Background(), pathParser, logger)
+}
+
+// BuildWithContext is Build with a context that can cancel the filesystem
+// calls made while resolving the project and its node_modules.
+func BuildWithContext(ctx context.Context, pathParser PathParser, logger scribe.Emitter) packit.BuildFunc {
+	return BuildWithNode(ctx, pathParser, pexec.NewExecutable("node"), logger)
+}
+
+// BuildWithNode is BuildWithContext with the node executable that runs the
+// BP_NPM_START_COMMAND_HOOK script.
+func BuildWithNode(ctx context.Context, pathParser PathParser, node Executable, logger scribe.Emitter) packit.BuildFunc {
 	return func(context packit.BuildContext) (packit.BuildResult, error) {
 		logger.Title("%s %s", context.BuildpackInfo.Name, context.BuildpackInfo.Version)
 
-		projectPath, err := pathParser.Get(context.WorkingDir)
+		launch, err := computeLaunch(ctx, launchInputs{
+			WorkingDir: context.WorkingDir,
+			LayersPath: context.Layers.Path,
+			Plan:       context.Plan,
+			Env:        processEnvironment,
+			Environ:    os.Environ(),
+			PathParser: pathParser,
+			CNBPath:    context.CNBPath,
+			Node:       node,
+		}, logger)
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
 
-		var pkg *PackageJson
+		err = removeStaleNodeModules(launch.projectPath, filepath.Dir(context.Layers.Path), launch.strict, logger)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
 
-		pkg, err = NewPackageJsonFromPath(filepath.Join(projectPath, "package.json"))
+		startLayer, err := resetLayer(context.Layers, "start")
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
 
-		command := "node"
-		arg := fmt.Sprintf("node %s", filepath.Join(context.WorkingDir, "server.js"))
+		startLayer.Launch = true
+		startLayer.LaunchEnv = launch.startLayer.LaunchEnv
+		startLayer.ProcessLaunchEnv = launch.startLayer.ProcessLaunchEnv
+		startLayer.Metadata = launch.metadata.layer
 
-		if pkg.Scripts.Start != "" {
-			command = "bash"
-			arg = pkg.Scripts.Start
+		if launch.script != "" {
+			err = WriteFileAtomic(filepath.Join(startLayer.Path, StartCommandScript), 0755, func(w io.Writer) error {
+				_, err := io.WriteString(w, launch.script)
+				return err
+			})
+			if err != nil {
+				return packit.BuildResult{}, fmt.Errorf("failed to write the start command script: %w", err)
+			}
 		}
 
-		if pkg.Scripts.PreStart != "" {
-			command = "bash"
-			arg = fmt.Sprintf("%s && %s", pkg.Scripts.PreStart, arg)
+		layers := []packit.Layer{startLayer}
+
+		if launch.maintenance {
+			layer, err := installMaintenanceServer(context)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			layers = append(layers, layer)
 		}
 
-		if pkg.Scripts.PostStart != "" {
-			command = "bash"
-			arg = fmt.Sprintf("%s && %s", arg, pkg.Scripts.PostStart)
+		// The launch helpers get a layer of their own, apart from the
+		// app-specific files of the start layer, so that every app that
+		// installs the same helpers gets the same layer. It is reset even
+		// when there are none, so that no stale one is reused.
+		helpersLayer, err := resetLayer(context.Layers, "helpers")
+		if err != nil {
+			return packit.BuildResult{}, err
 		}
 
-		// Ideally we would like the lifecycle to support setting a custom working
-		// directory to run the launch process.  Until that happens we will cd in.
-		if projectPath != context.WorkingDir {
-			command = "bash"
-			arg = fmt.Sprintf("cd %s && %s", projectPath, arg)
+		if len(launch.helpers) > 0 {
+			helpersLayer.Launch = true
+			for _, helper := range launch.helpers {
+				helpersLayer.ExecD = append(helpersLayer.ExecD, filepath.Join(context.CNBPath, "bin", helper))
+			}
+
+			layers = append(layers, helpersLayer)
 		}
 
-		args := []string{arg}
-		switch command {
-		case "bash":
-			args = []string{"-c", arg}
-		case "node":
-			args = []string{filepath.Join(context.WorkingDir, "server.js")}
+		for i := range layers {
+			err = writeLayerFiles(&layers[i])
+			if err != nil {
+				return packit.BuildResult{}, fmt.Errorf("failed to write the launch files of the %s layer: %w", layers[i].Name, err)
+			}
 		}
 
-		processes := []packit.Process{
-			{
-				Type:    "web",
-				Command: command,
-				Args:    args,
-				Default: true,
-				Direct:  true,
-			},
+		for _, layer := range layers {
+			err = zeroTimestamps(layer.Path)
+			if err != nil {
+				return packit.BuildResult{}, fmt.Errorf("failed to normalize timestamps in %s layer: %w", layer.Name, err)
+			}
 		}
 
-		shouldReload, err := checkLiveReloadEnabled()
-		if err != nil {
-			return packit.BuildResult{}, err
+		if path := launch.metadata.deprecationsFile; path != "" {
+			logger.Process("Writing the deprecation timeline to %s", path)
+			logger.Break()
+
+			err = writeDeprecations(path, launch.deprecations)
+			if err != nil {
+				return packit.BuildResult{}, fmt.Errorf("failed to write BP_NPM_START_DEPRECATIONS_FILE: %w", err)
+			}
 		}
 
-		if shouldReload {
-			processes = []packit.Process{
-				{
-					Type:    "web",
-					Command: "watchexec",
-					Args: append([]string{
-						"--restart",
-						"--shell", "none",
-						"--watch", projectPath,
-						"--ignore", filepath.Join(projectPath, "package.json"),
-						"--ignore", filepath.Join(projectPath, "package-lock.json"),
-						"--ignore", filepath.Join(projectPath, "node_modules"),
-						"--",
-						command,
-					}, args...),
-					Default: true,
-					Direct:  true,
-				},
-				{
-					Type:    "no-reload",
-					Command: command,
-					Args:    args,
-					Direct:  true,
-				},
-			}
-		}
-
-		logger.LaunchProcesses(processes)
+		// Labels are persisted in the image metadata, where secrets from the
+		// build environment must not end up.
+		labels := NewRedactor(os.Environ()).RedactLabels(launch.metadata.labels)
 
 		return packit.BuildResult{
 			Plan: packit.BuildpackPlan{
 				Entries: []packit.BuildpackPlanEntry{},
 			},
+			Layers: layers,
 			Launch: packit.LaunchMetadata{
-				Processes: processes,
+				Processes: launch.processes,
+				Labels:    labels,
 			},
 		}, nil
 	}
 }
+
+// launchInputs are the inputs from which computeLaunch computes the launch
+// processes of an app.
+type launchInputs struct {
+	WorkingDir string
+	LayersPath string
+	Plan       packit.BuildpackPlan
+	Env        Environment
+	PathParser PathParser
+
+	// Environ holds the variables of Env in the form returned by
+	// os.Environ, so that their secrets are redacted from logs and errors.
+	Environ []string
+
+	// CNBPath is the buildpack directory, which holds the configuration of
+	// the builder. Preview leaves it empty.
+	CNBPath string
+
+	// Node runs the command hook. Preview leaves it nil, as it never runs
+	// anything.
+	Node Executable
+}
+
+// launch is the outcome of computeLaunch.
+type launch struct {
+	// startLayer carries the launch environment of the start layer.
+	startLayer  packit.Layer
+	projectPath string
+	processes   []packit.Process
+
+	// script is the content of the StartCommandScript file of the start
+	// layer, or empty when the start command is passed inline.
+	script string
+
+	// sources holds the ProcessSource of each of the processes.
+	sources []string
+
+	// helpers are the exec.d helpers, shipped in the buildpack bin
+	// directory, that run ahead of every process.
+	helpers []string
+
+	// maintenance indicates that the maintenance server must be installed.
+	maintenance bool
+
+	// strict is the value of BP_NPM_START_STRICT, which Build applies to the
+	// node_modules that previous builds left behind.
+	strict bool
+
+	// deprecations holds the identifiers of the Deprecations that the app
+	// relies on.
+	deprecations map[string]bool
+
+	metadata optionalMetadata
+}
+
+// optionalMetadata is the metadata that Build emits beyond the processes and
+// the files they run. Every such emission goes through it, so that
+// BP_NPM_START_SLIM can leave all of it out.
+type optionalMetadata struct {
+	// labels are the image labels.
+	labels map[string]string
+
+	// layer is the metadata of the start layer.
+	layer map[string]interface{}
+
+	// deprecationsFile is the path that the deprecation timeline is written
+	// to, or empty when it is not written.
+	deprecationsFile string
+}
+
+// computeLaunch resolves the project and computes the processes that the app
+// is launched with. It reads the file system but never writes to it, so that
+// Build and Preview share it: Build writes the layers that the processes
+// rely on afterwards.
+func computeLaunch(ctx context.Context, in launchInputs, logger scribe.Emitter) (launch, error) {
+	// Problems with the configuration are reported along with those of the
+	// other build options below.
+	config, warnings, configErr := ParseConfiguration(in.Env)
+
+	// Scripts are quoted with the excerpt length and the secrets of the
+	// build environment, never with those of the process.
+	excerpts := newExcerpter(config, in.Environ)
+
+	var (
+		projectPath string
+		runner      MonorepoRunner
+		isMonorepo  bool
+	)
+	err := cancellable(ctx, "resolving the project path", func() error {
+		var err error
+		projectPath, runner, isMonorepo, err = resolveProjectPath(in.PathParser, in.WorkingDir)
+		return err
+	})
+	if err != nil {
+		return launch{}, err
+	}
+
+	var pkg *PackageJson
+	err = cancellable(ctx, "reading package.json", func() error {
+		var err error
+		pkg, err = NewPackageJsonFromPath(filepath.Join(projectPath, "package.json"))
+		if errors.Is(err, os.ErrNotExist) {
+			return classify(ErrMissingPackageJson, err)
+		}
+		return err
+	})
+	if err != nil {
+		return launch{}, err
+	}
+
+	traceScripts(ctx, pkg.Scripts)
+
+	if len(pkg.transcoded) > 0 {
+		logger.Process("WARNING: package.json is not valid UTF-8")
+		logger.Subprocess("The bytes at offsets %s were read as Latin-1 characters.", formatOffsets(pkg.transcoded))
+		logger.Subprocess("Save package.json as UTF-8 to silence this warning.")
+		logger.Break()
+	}
+
+	if isMonorepo {
+		logger.Process("Starting the workspace with %s", runner.Name)
+		logger.Subprocess("The start script at the workspace root runs %s, which selects the packages to start", runner.Name)
+		if _, ok := in.Env("BP_NODE_PROJECT_PATH"); ok {
+			logger.Subprocess("BP_NODE_PROJECT_PATH is ignored for the start command")
+		}
+		logger.Break()
+
+		pkg.Scripts.Start = runner.withFilter(pkg.Scripts.Start, config.Filter)
+	}
+
+	// The command of an application descriptor takes precedence over the
+	// start script, and is rewritten by the command hook like it.
+	startScript := pkg.Scripts.Start
+	descriptorPath, descriptorApp, err := applyDescriptor(config, pkg, in.WorkingDir)
+	if err != nil {
+		return launch{}, err
+	}
+
+	if descriptorPath != "" {
+		logger.Process("Starting the app with the command of application %q in %s (BP_NPM_START_DESCRIPTOR)", descriptorApp.Name, filepath.Base(descriptorPath))
+		if startScript != "" {
+			logger.Subprocess("It takes precedence over the start script %s of package.json.", excerpts.quote(startScript))
+		}
+		if config.CommandHook != "" {
+			logger.Subprocess("BP_NPM_START_COMMAND_HOOK is applied to it afterwards.")
+		}
+		logger.Break()
+	}
+
+	// The environment of the start layer is assembled in memory; Build
+	// writes it to the layer once the processes are known.
+	startLayer := packit.Layer{
+		Name:             "start",
+		Path:             filepath.Join(in.LayersPath, "start"),
+		SharedEnv:        packit.Environment{},
+		BuildEnv:         packit.Environment{},
+		LaunchEnv:        packit.Environment{},
+		ProcessLaunchEnv: map[string]packit.Environment{},
+		Launch:           true,
+	}
+
+	var (
+		nodeModules string
+		found       bool
+	)
+	err = cancellable(ctx, "locating node_modules", func() error {
+		var err error
+		nodeModules, found, err = locateNodeModules(in.WorkingDir, projectPath, pkg.Dependencies)
+		return err
+	})
+	if err != nil {
+		return launch{}, err
+	}
+
+	checks := newCheckSet(config)
+	logWarnings(logger, warnings)
+
+	if !found && len(pkg.Dependencies) > 0 && checks.enabled(CheckMissingNodeModules) {
+		warn(logger, CheckMissingNodeModules, "package.json declares dependencies but no node_modules directory containing them was found")
+		logger.Subprocess("The app may fail to resolve its modules at launch.")
+		logger.Break()
+	}
+
+	if found && nodeModules != filepath.Join(projectPath, NodeModules) {
+		logger.Process("Resolving node_modules")
+		logger.Subprocess("%s has no node_modules directory, using %s", projectPath, nodeModules)
+		logger.Break()
+
+		startLayer.LaunchEnv.Default("NODE_PATH", nodeModules)
+	}
+
+	if found && len(pkg.DevDependencies) > DevDependencyWarningThreshold && checks.enabled(CheckDevDeps) {
+		installed, err := installedDevDependencies(nodeModules, pkg.DevDependencies)
+		if err != nil {
+			return launch{}, err
+		}
+
+		if len(installed) > DevDependencyWarningThreshold {
+			var dirs []string
+			for _, name := range installed {
+				dirs = append(dirs, filepath.Join(nodeModules, filepath.FromSlash(name)))
+			}
+
+			size, complete := estimateSize(dirs, time.Now().Add(devDependencySizeBudget))
+			estimate := formatSize(size)
+			if !complete {
+				estimate = "at least " + estimate
+			}
+
+			warn(logger, CheckDevDeps, "%d devDependencies are installed in %s (%s)", len(installed), nodeModules, estimate)
+			logger.Subprocess("They are not needed at launch and increase the size of the image.")
+			logger.Subprocess("Configure the npm-install buildpack to prune devDependencies: https://github.com/paketo-buildpacks/npm-install#readme")
+			logger.Subprocess("Set BP_NPM_START_DISABLED_CHECKS=%s to silence this warning.", CheckDevDeps)
+			logger.Break()
+		}
+	}
+
+	// The start command is not run through npm, so the locally installed
+	// executables that npm would have put on the PATH are added here.
+	var binPaths []string
+	err = cancellable(ctx, "resolving local bin paths", func() error {
+		var err error
+		binPaths, err = localBinPaths(in.WorkingDir, projectPath, *pkg)
+		return err
+	})
+	if err != nil {
+		return launch{}, err
+	}
+
+	startLayer.LaunchEnv.Append("PATH", strings.Join(binPaths, ":"), ":")
+
+	// npm exports the config of the package.json to the scripts it runs;
+	// the start command is run without npm, so the build exports it.
+	for name, value := range PackageConfigEnv(pkg.Config) {
+		startLayer.LaunchEnv.Default(name, value)
+	}
+
+	// The build options are validated together, so that every problem with
+	// them is reported by a single build.
+	var problems []error
+	problems = append(problems, configErr)
+
+	argsOverridable, argsLocked, err := checkArgsPolicy(in.Env, config.ArgsPolicy)
+	problems = append(problems, err)
+
+	// Live reload is enabled by the plan rather than by the build
+	// environment, so that the command matches the requirements that
+	// Detect made.
+	reloadEntry, shouldReload := lookupLiveReloadPlanEntry(in.Plan)
+
+	if configErr == nil && config.LiveReload != shouldReload {
+		planned := "disabled"
+		if shouldReload {
+			planned = "enabled"
+		}
+
+		logger.Process("WARNING: BP_LIVE_RELOAD_ENABLED is %t in the build environment but was %t during detection", config.LiveReload, shouldReload)
+		logger.Subprocess("Live reload stays %s, as planned during detection.", planned)
+		logger.Break()
+	}
+
+	isWorker := config.WorkloadType == WorkloadWorker
+
+	enabledOptions := map[string]bool{
+		OptionLiveReload:       shouldReload,
+		OptionMaintenance:      config.Maintenance,
+		OptionRunPrepare:       config.RunPrepare,
+		OptionForwardPort:      config.ForwardPort,
+		OptionNoDefault:        config.NoDefaultProcess,
+		OptionWorker:           isWorker,
+		OptionSilent:           config.Silent,
+		OptionMinimal:          config.MinimalLaunch,
+		OptionDebug:            config.DebugProcesses,
+		OptionEngines:          config.EnginesStrict,
+		OptionSlim:             config.Slim,
+		OptionLabels:           config.Labels != "",
+		OptionHealthcheck:      config.HealthcheckScript != "",
+		OptionCommandHook:      config.CommandHook != "",
+		OptionExplain:          config.Explain,
+		OptionArgsPolicy:       config.ArgsPolicy != "",
+		OptionJobs:             config.Jobs != "",
+		OptionDescriptor:       config.Descriptor != "",
+		OptionRevision:         config.Revision != "",
+		OptionDescriptorApp:    config.DescriptorApp != "",
+		OptionMaintenancePage:  config.MaintenancePage != "",
+		OptionShell:            config.Shell == "bash",
+		OptionTraceWrappers:    config.TraceWrappers,
+		OptionExcerptLength:    config.ExcerptLength != DefaultExcerptLength,
+		OptionStrict:           config.Strict,
+		OptionRejectDevServers: config.RejectDevServers,
+		OptionForce:            config.Force,
+		OptionSuppressWarnings: config.SuppressWarnings,
+		OptionDisabledChecks:   len(config.DisabledChecks) > 0,
+		OptionFilter:           config.Filter != "",
+		OptionProcessEnv:       config.ProcessEnv != "",
+		OptionDeprecationsFile: config.DeprecationsFile != "",
+	}
+	problems = append(problems, OptionCompatibility.Validate(enabledOptions))
+
+	err = JoinErrors("the build configuration", problems...)
+	if err != nil {
+		return launch{}, err
+	}
+
+	traceFeatures(ctx, enabledOptions)
+
+	// The optional launch helpers cost start-up time even when they have
+	// nothing to do, so a minimal launch leaves them out. wait-for-bindings
+	// is installed unconditionally otherwise, as the BPL_ variable that it
+	// reads is only set at launch.
+	helpers := []string{WaitForBindings}
+	if config.MinimalLaunch {
+		logger.Process("Omitting the optional launch helpers (BP_NPM_START_MINIMAL_LAUNCH=true)")
+		logger.Subprocess("%s: BPL_NPM_START_WAIT_FOR_BINDINGS has no effect at launch", WaitForBindings)
+		logger.Break()
+
+		helpers = nil
+	}
+
+	var layerMetadata map[string]interface{}
+
+	// The range is recorded at build time, as the package.json is not read
+	// at launch.
+	if config.EnginesStrict {
+		if pkg.Engines.Node == "" {
+			logger.Process("Not checking the node version at launch")
+			logger.Subprocess("BP_NPM_START_ENGINES_STRICT is set, but package.json has no engines.node range.")
+			logger.Break()
+		} else {
+			helpers = append(helpers, CheckEngines)
+			startLayer.LaunchEnv.Default(EnginesNodeEnv, pkg.Engines.Node)
+			layerMetadata = map[string]interface{}{EnginesNodeMetadata: pkg.Engines.Node}
+		}
+	}
+
+	entrypoint := filepath.Join(in.WorkingDir, "server.js")
+	startReason := "runs the package.json start script"
+	startSource := ProcessSourceStartScript
+	if descriptorPath != "" {
+		startReason = fmt.Sprintf("runs the command of application %q in %s", descriptorApp.Name, filepath.Base(descriptorPath))
+		startSource = ProcessSourceDescriptor
+	}
+	if pkg.Scripts.Start == "" {
+		startReason = "runs server.js, as package.json has no start script"
+		startSource = ProcessSourceEntrypoint
+
+		bin, ok, err := pkg.soleBin()
+		if err != nil {
+			return launch{}, classify(ErrNoStartScript, err)
+		}
+
+		if ok {
+			entrypoint = filepath.Join(projectPath, bin)
+			_, err = os.Stat(entrypoint)
+			if err != nil {
+				return launch{}, fmt.Errorf("failed to find the bin entrypoint: %w", err)
+			}
+			startReason = "runs the sole package.json bin entry " + bin
+			startSource = ProcessSourceBin
+		}
+	}
+
+	// Running a package with npx installs it from the network at launch
+	// unless npx finds it locally, so a locally installed package is run
+	// directly instead.
+	if npx, ok := ParseNpxInvocation(pkg.Scripts.Start); ok {
+		bin, installed := npx.localBin(projectPath, binPaths)
+		switch {
+		case installed && npx.Version == "":
+			rewritten := strings.Join(append([]string{bin}, npx.Args...), " ")
+			logger.Process("Running %s from node_modules instead of through npx", npx.Package)
+			logger.Subprocess("Rewrote the start script %s to %s", excerpts.quote(pkg.Scripts.Start), excerpts.quote(rewritten))
+			logger.Break()
+
+			pkg.Scripts.Start = rewritten
+			startReason += fmt.Sprintf(", running the locally installed %s instead of npx", npx.Package)
+		case installed:
+			logger.Process("Leaving the start script %s to npx", excerpts.quote(pkg.Scripts.Start))
+			logger.Subprocess("It pins %s@%s; npx runs the installed %s only if it satisfies that version.", npx.Package, npx.Version, bin)
+			logger.Break()
+		default:
+			if config.Strict {
+				return launch{}, fmt.Errorf("start script %s installs %s from the network at launch, as it is not in node_modules (BP_NPM_START_STRICT=true)", excerpts.quote(pkg.Scripts.Start), npx.Package)
+			}
+
+			if checks.enabled(CheckNpxNetwork) {
+				warn(logger, CheckNpxNetwork, "the start script %s installs %s from the network at launch, as it is not in node_modules", excerpts.quote(pkg.Scripts.Start), npx.Package)
+				logger.Subprocess("This fails without network access and slows down every start.")
+				logger.Subprocess("Run \"npm install --save %s\" to add it to the dependencies in package.json.", npx.Package)
+				logger.Break()
+			}
+		}
+	}
+
+	options := StartChainOptions{
+		Entrypoint:  entrypoint,
+		RunPrepare:  config.RunPrepare,
+		ForwardArgs: argsOverridable,
+		LockArgs:    argsLocked,
+		ForwardPort: config.ForwardPort,
+	}
+
+	if projectPath != in.WorkingDir {
+		options.WorkingDir = projectPath
+	}
+
+	chain := NewStartChain(*pkg, options)
+	if err := chain.validate(excerpts); err != nil {
+		return launch{}, err
+	}
+
+	if separators := chain.ArgsSeparators(); separators > 0 && (options.ForwardPort || options.ForwardArgs) {
+		logger.Debug.Process("The start script already passes arguments after a \"--\" separator")
+		if separators > 1 {
+			logger.Debug.Subprocess("The forwarded arguments are appended after the last of its %d separators rather than after a new one", separators)
+		} else {
+			logger.Debug.Subprocess("The forwarded arguments are appended after it rather than after a new one")
+		}
+		logger.Debug.Break()
+	}
+
+	if err := chain.checkPortForwarding(excerpts); err != nil {
+		return launch{}, err
+	}
+
+	if pkg.Scripts.Start != "" && ClassifyScript(pkg.Scripts.Start) == ScriptClassBuild {
+		if config.Strict {
+			return launch{}, fmt.Errorf("start script %s appears to be a build step rather than a server (BP_NPM_START_STRICT=true)", excerpts.quote(pkg.Scripts.Start))
+		}
+
+		if checks.enabled(CheckBuildStep) {
+			warn(logger, CheckBuildStep, "the start script %s appears to be a build step rather than a server", excerpts.quote(pkg.Scripts.Start))
+			logger.Subprocess("It will exit once the build completes and the platform will keep restarting it.")
+			logger.Subprocess("Move it to a build script and run it with the node-run-script buildpack: https://github.com/paketo-buildpacks/node-run-script#readme")
+			logger.Break()
+		}
+	}
+
+	for _, name := range missingCommands(pkg.Scripts.Start, binPaths) {
+		switch {
+		case GlobalPackages[name] && checks.enabled(CheckGlobalBin):
+			warn(logger, CheckGlobalBin, "the start script runs %s, which looks like a global npm package; add it to dependencies or use npx", excerpts.quote(name))
+			logger.Subprocess("Run \"npm install --save %s\" to add it to the dependencies in package.json.", name)
+			logger.Break()
+		case !GlobalPackages[name] && checks.enabled(CheckMissingCommand):
+			warn(logger, CheckMissingCommand, "the start script runs %s, which is not installed in node_modules/.bin", excerpts.quote(name))
+			logger.Subprocess("The app may fail to start with \"command not found\" unless the run image or a later buildpack provides it.")
+			logger.Break()
+		}
+	}
+
+	// node resolves a directory entrypoint, as in "node .", through its
+	// package.json. The start script is run as written; the resolved file is
+	// only checked and reported.
+	if invocation, ok := ParseNodeInvocation(pkg.Scripts.Start); ok {
+		resolved, err := invocation.ResolveEntrypoint(projectPath)
+
+		// File names are case-sensitive on the run image, so an entrypoint
+		// that differs from a file only in case fails to start there even
+		// when it worked on the machine the app was written on.
+		var caseErr EntrypointCaseError
+		if errors.As(err, &caseErr) && len(caseErr.Variants) == 1 {
+			if config.Strict {
+				return launch{}, fmt.Errorf("start script runs %s with node, but the file is named %s, and file names are case-sensitive at launch (BP_NPM_START_STRICT=true)", excerpts.quote(caseErr.Entrypoint), excerpts.quote(caseErr.Variants[0]))
+			}
+		}
+
+		switch {
+		case len(caseErr.Variants) == 1 && checks.enabled(CheckMissingEntrypoint):
+			warn(logger, CheckMissingEntrypoint, "the start script runs %s with node, but the file is named %s", excerpts.quote(caseErr.Entrypoint), excerpts.quote(caseErr.Variants[0]))
+			logger.Subprocess("File names are case-sensitive at launch, so node cannot find the file. Rename the file or change the start script to match.")
+			logger.Break()
+		case err != nil && checks.enabled(CheckMissingEntrypoint):
+			warn(logger, CheckMissingEntrypoint, "the start script runs %s with node, which cannot resolve it", excerpts.quote(invocation.Entrypoint))
+			logger.Subprocess("node looks for the file as named or with a .js, .json or .node extension, and in a directory for the main file of its package.json or an index file.")
+			if len(caseErr.Variants) > 1 {
+				var variants []string
+				for _, variant := range caseErr.Variants {
+					variants = append(variants, excerpts.quote(variant))
+				}
+				logger.Subprocess("%s differ from it only in case; file names are case-sensitive at launch.", strings.Join(variants, ", "))
+			}
+			logger.Subprocess("The app fails to start unless a later buildpack provides the file.")
+			logger.Break()
+		case err == nil && resolved != invocation.Entrypoint:
+			startReason += fmt.Sprintf(", where node resolves %s to %s", invocation.Entrypoint, resolved)
+		}
+	}
+
+	command, args := chain.Executable()
+
+	// The wrappers of the start command, innermost first. The script file
+	// must hold the plain start chain, the hook sees the command that the
+	// app runs with, and live reload restarts whatever the hook made of it.
+	var (
+		script   string
+		noReload Command
+	)
+	wrappers := WrapperPipeline{
+		{
+			Name:    WrapperScriptFile,
+			Enabled: !chain.Inline(),
+			Wrap: func(Command) (Command, error) {
+				path := filepath.Join(startLayer.Path, StartCommandScript)
+				script = chain.Script()
+
+				logger.Process("Writing the start command to %s", path)
+				logger.Subprocess("The command is %d bytes long, more than the %d bytes that are passed to the shell inline.", len(chain.String()), MaxInlineCommandSize)
+				logger.Break()
+
+				command, args := chain.ScriptExecutable(path)
+				return Command{Executable: command, Args: args}, nil
+			},
+		},
+		{
+			Name:    WrapperCommandHook,
+			Enabled: config.CommandHook != "",
+			Wrap: func(c Command) (Command, error) {
+				if in.Node == nil {
+					return Command{}, errors.New("BP_NPM_START_COMMAND_HOOK cannot be previewed, as the hook runs during the build")
+				}
+
+				hook := config.CommandHook
+				if !filepath.IsAbs(hook) {
+					hook = filepath.Join(in.WorkingDir, hook)
+				}
+
+				command, args, err := applyCommandHook(in.Node, logger, excerpts, hook, projectPath, c.Executable, c.Args)
+				if err != nil {
+					return Command{}, err
+				}
+				startReason += ", as rewritten by BP_NPM_START_COMMAND_HOOK"
+				startSource = ProcessSourceCommandHook
+
+				return Command{Executable: command, Args: args}, nil
+			},
+		},
+		{
+			Name:    WrapperLiveReload,
+			Enabled: shouldReload,
+			Wrap: func(c Command) (Command, error) {
+				noReload = c
+
+				provider := reloadEntry.Name
+				commandTemplate := config.LiveReloadCommandTemplate
+
+				signal, library, err := lookupReloadSignal(config.LiveReloadSignal, *pkg)
+				if err != nil {
+					return Command{}, err
+				}
+
+				// entr cannot send another signal, so a signal chosen on
+				// behalf of a library is dropped rather than failing the
+				// build.
+				if library != "" && provider == Entr && commandTemplate == "" {
+					signal, library = "", ""
+				}
+
+				if library != "" {
+					logger.Process("Live reload will restart the app with %s", signal)
+					logger.Subprocess("The %q dependency restarts gracefully on %s. Set BP_LIVE_RELOAD_SIGNAL to override.", library, signal)
+					logger.Break()
+				}
+
+				reload, err := reloadProcess(provider, commandTemplate, signal, projectPath, c.Executable, c.Args)
+				if err != nil {
+					return Command{}, err
+				}
+
+				return Command{Executable: reload.Command, Args: reload.Args}, nil
+			},
+		},
+	}
+
+	start, err := wrappers.apply(Command{Executable: command, Args: args}, config.TraceWrappers, excerpts, logger)
+	if err != nil {
+		return launch{}, err
+	}
+
+	processes := []packit.Process{
+		{
+			Type:    "web",
+			Command: start.Executable,
+			Args:    start.Args,
+			Default: true,
+			Direct:  true,
+		},
+	}
+	reasons := []string{startReason}
+	sources := []string{startSource}
+
+	if shouldReload {
+		processes = append(processes, packit.Process{
+			Type:    "no-reload",
+			Command: noReload.Executable,
+			Args:    noReload.Args,
+			Direct:  true,
+		})
+		reasons = []string{
+			fmt.Sprintf("%s, restarted by %s when files change (BP_LIVE_RELOAD_ENABLED=true)", startReason, reloadEntry.Name),
+			startReason + " without live reload",
+		}
+		sources = []string{ProcessSourceLiveReload, startSource}
+	}
+
+	if config.Maintenance {
+		process, err := maintenanceProcess(config.MaintenancePage, in.WorkingDir, in.LayersPath)
+		if err != nil {
+			return launch{}, err
+		}
+
+		processes = append(processes, process)
+		reasons = append(reasons, "serves a 503 page on $PORT (BP_NPM_START_MAINTENANCE=true)")
+		sources = append(sources, ProcessSourceMaintenance)
+	}
+
+	// A worker does not listen on $PORT, so its process is not named
+	// "web" and the image is labelled for the platform to skip HTTP
+	// health checks.
+	var labels map[string]string
+	if isWorker {
+		for i := range processes {
+			if processes[i].Type == "web" {
+				processes[i].Type = WorkloadWorker
+				reasons[i] += " (BP_NPM_START_WORKLOAD_TYPE=worker)"
+			}
+		}
+
+		labels = map[string]string{WorkloadLabel: WorkloadWorker}
+	}
+
+	// The revision and version are only defaults of the launch
+	// environment, so that the platform can still set them at launch.
+	revision, revisionSource, err := lookupRevision(config.Revision, in.WorkingDir)
+	if err != nil {
+		logger.Process("WARNING: failed to read the git revision of %s", in.WorkingDir)
+		logger.Subprocess("%s", err)
+		logger.Subprocess("%s is not set; set BP_NPM_START_REVISION to set it.", RevisionEnv)
+		logger.Break()
+	}
+
+	if revision != "" || pkg.Version != "" {
+		logger.Process("Setting the revision and version of the app")
+		if labels == nil {
+			labels = map[string]string{}
+		}
+
+		if revision != "" {
+			startLayer.LaunchEnv.Default(RevisionEnv, revision)
+			labels[RevisionLabel] = revision
+			logger.Subprocess("%s: %s, from %s", RevisionEnv, revision, revisionSource)
+		}
+
+		if pkg.Version != "" {
+			startLayer.LaunchEnv.Default(VersionEnv, pkg.Version)
+			labels[VersionLabel] = pkg.Version
+			logger.Subprocess("%s: %s, from the version field of package.json", VersionEnv, pkg.Version)
+		}
+		logger.Break()
+	}
+
+	// Labels for the platform, eg. to configure autoscaling, are passed
+	// through as given, except over the labels of the buildpack.
+	userLabels, err := ParseLabels(config.Labels)
+	if err != nil {
+		return launch{}, err
+	}
+
+	for key, value := range userLabels {
+		if key == WorkloadLabel {
+			return launch{}, classify(ErrInvalidConfiguration, fmt.Errorf("BP_NPM_START_LABELS cannot set the %s label, use BP_NPM_START_WORKLOAD_TYPE instead", WorkloadLabel))
+		}
+
+		if strings.HasPrefix(key, BuildpackLabelPrefix) {
+			return launch{}, classify(ErrInvalidConfiguration, fmt.Errorf("BP_NPM_START_LABELS cannot set the %s label, labels starting with %s are set by the buildpack", key, BuildpackLabelPrefix))
+		}
+
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = value
+	}
+
+	// Images for platforms that inject their own entrypoint carry the
+	// start command as a non-default "app" process instead of "web".
+	if config.NoDefaultProcess {
+		for i := range processes {
+			if processes[i].Type == "web" {
+				processes[i].Type = "app"
+				reasons[i] += " (BP_NPM_START_NO_DEFAULT_PROCESS=true)"
+			}
+			processes[i].Default = false
+		}
+	}
+
+	if config.DebugProcesses {
+		processes = append(processes, debugShellProcess(config.Shell, in.WorkingDir, projectPath))
+		reasons = append(reasons, fmt.Sprintf("opens an interactive %s in the project path (BP_NPM_START_DEBUG_PROCESSES=true)", config.Shell))
+		sources = append(sources, ProcessSourceDebugShell)
+	}
+
+	// Probes run the healthcheck script often, so it is run without npm
+	// whenever the script allows it.
+	if name := config.HealthcheckScript; name != "" {
+		process, npmReason, err := healthcheckProcess(name, *pkg, in.WorkingDir, projectPath, config.Silent, excerpts)
+		if err != nil {
+			return launch{}, err
+		}
+
+		reason := fmt.Sprintf("runs the %s script for probes (BP_NPM_START_HEALTHCHECK_SCRIPT)", name)
+		if npmReason != "" {
+			reason = fmt.Sprintf("runs the %s script with npm for probes, as %s (BP_NPM_START_HEALTHCHECK_SCRIPT)", name, npmReason)
+
+			if checks.enabled(CheckHealthcheckNpm) {
+				warn(logger, CheckHealthcheckNpm, "the %s process runs the %s script with npm, as %s", HealthcheckProcess, name, npmReason)
+				logger.Subprocess("Starting npm adds several hundred milliseconds to every probe that runs the process.")
+				logger.Break()
+			}
+		}
+
+		processes = append(processes, process)
+		reasons = append(reasons, reason)
+		sources = append(sources, ProcessSourceHealthcheck)
+
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[HealthcheckLabel] = HealthcheckProcess
+	}
+
+	// Jobs run a script once and exit, so they are never the default
+	// process and run without live reload or the start lifecycle scripts.
+	jobs, err := ParseJobs(config.Jobs, pkg.Scripts)
+	if err != nil {
+		return launch{}, err
+	}
+
+	for _, name := range jobs {
+		if hasProcess(processes, name) {
+			return launch{}, classify(ErrInvalidConfiguration, fmt.Errorf("BP_NPM_START_JOBS names the script %q, which clashes with the %s process of the buildpack", name, name))
+		}
+
+		processes = append(processes, packit.Process{
+			Type:    name,
+			Command: "npm",
+			Args:    jobArgs(name, in.WorkingDir, projectPath, config.Silent),
+			Direct:  true,
+		})
+		reasons = append(reasons, fmt.Sprintf("runs the %s script once and exits (BP_NPM_START_JOBS)", name))
+		sources = append(sources, ProcessSourceJob)
+
+		startLayer.ProcessLaunchEnv[name] = packit.Environment{}
+		startLayer.ProcessLaunchEnv[name].Override(JobEnv, "true")
+	}
+
+	processEnv, err := ParseProcessEnv(config.ProcessEnv)
+	if err != nil {
+		return launch{}, err
+	}
+
+	for _, v := range processEnv {
+		if !hasProcess(processes, v.Process) {
+			return launch{}, classify(ErrInvalidConfiguration, fmt.Errorf("BP_NPM_START_PROCESS_ENV refers to unknown process type %q", v.Process))
+		}
+
+		if _, ok := startLayer.ProcessLaunchEnv[v.Process]; !ok {
+			startLayer.ProcessLaunchEnv[v.Process] = packit.Environment{}
+		}
+
+		// Several entries can contribute NODE_OPTIONS to a process; they are
+		// merged so that a repeated flag is not passed twice.
+		value := v.Value
+		if previous, ok := startLayer.ProcessLaunchEnv[v.Process]["NODE_OPTIONS.override"]; ok && v.Name == "NODE_OPTIONS" {
+			value, err = MergeNodeOptions(previous, v.Value)
+			if err != nil {
+				return launch{}, classify(ErrInvalidConfiguration, fmt.Errorf("failed to merge BP_NPM_START_PROCESS_ENV entries for the %s process: %w", v.Process, err))
+			}
+		}
+		startLayer.ProcessLaunchEnv[v.Process].Override(v.Name, value)
+	}
+
+	// The builder can mandate node flags for every process. They are
+	// appended to the NODE_OPTIONS of the launch environment, and merged
+	// last into the NODE_OPTIONS that BP_NPM_START_PROCESS_ENV gives a
+	// process, whose override would drop them otherwise.
+	mandatoryNodeArgs, err := readMandatoryNodeArgs(in.CNBPath)
+	if err != nil {
+		return launch{}, err
+	}
+
+	if mandatoryNodeArgs != "" {
+		startLayer.LaunchEnv.Append("NODE_OPTIONS", mandatoryNodeArgs, " ")
+
+		for process, env := range startLayer.ProcessLaunchEnv {
+			if value, ok := env["NODE_OPTIONS.override"]; ok {
+				merged, err := MergeNodeOptions(value, mandatoryNodeArgs)
+				if err != nil {
+					return launch{}, classify(ErrInvalidConfiguration, fmt.Errorf("failed to merge the mandatory node arguments into NODE_OPTIONS of the %s process: %w", process, err))
+				}
+				env.Override("NODE_OPTIONS", merged)
+			}
+		}
+
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[MandatoryNodeArgsLabel] = mandatoryNodeArgs
+
+		logger.Process("Appending the mandatory node arguments of the builder to NODE_OPTIONS")
+		logger.Subprocess("%s", mandatoryNodeArgs)
+		logger.Break()
+	}
+
+	// The debug shell gets the environment of the start process, below the
+	// variables that BP_NPM_START_PROCESS_ENV gives the shell itself.
+	if config.DebugProcesses {
+		for name, value := range startLayer.ProcessLaunchEnv[processes[0].Type] {
+			if _, ok := startLayer.ProcessLaunchEnv[DebugShellProcess]; !ok {
+				startLayer.ProcessLaunchEnv[DebugShellProcess] = packit.Environment{}
+			}
+
+			if _, ok := startLayer.ProcessLaunchEnv[DebugShellProcess][name]; !ok {
+				startLayer.ProcessLaunchEnv[DebugShellProcess][name] = value
+			}
+		}
+	}
+
+	deprecations := reliedOnDeprecations(DeprecationInputs{
+		Package:     *pkg,
+		ProjectPath: projectPath,
+		LiveReload:  shouldReload,
+	})
+	if checks.enabled(CheckDeprecated) {
+		logDeprecations(logger, deprecations)
+	}
+
+	metadata := optionalMetadata{
+		labels: labels,
+		layer:  layerMetadata,
+	}
+	if path := config.DeprecationsFile; path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(in.WorkingDir, path)
+		}
+		metadata.deprecationsFile = path
+	}
+
+	if config.Slim {
+		metadata = optionalMetadata{}
+		logger.Process("Slim build: emitting no image labels, layer metadata or report files (BP_NPM_START_SLIM=true)")
+		logger.Break()
+	}
+
+	// The environment is logged once it is complete, with the environment
+	// of each process alongside the process.
+	logger.EnvironmentVariables(startLayer)
+	logger.LaunchProcesses(excerpts.processes(processes), startLayer.ProcessLaunchEnv)
+
+	if config.Explain {
+		var rationales []Rationale
+		for i, process := range processes {
+			rationales = append(rationales, Rationale{Subject: process.Type, Reason: reasons[i]})
+		}
+		logRationales(logger, "the launch processes", rationales)
+
+		if mandatoryNodeArgs != "" {
+			logRationales(logger, "the builder configuration", []Rationale{
+				{Subject: "NODE_OPTIONS", Reason: fmt.Sprintf("%s is appended for every process, as listed in %s of the buildpack", mandatoryNodeArgs, MandatoryNodeArgsFile)},
+			})
+		}
+
+		// BP_NPM_START_EXPLAIN is enabled whenever the combinations are
+		// explained, so its own pairs are left out of them.
+		combinable := map[string]bool{}
+		for name, on := range enabledOptions {
+			combinable[name] = on && name != OptionExplain
+		}
+
+		if combined := OptionCompatibility.Explain(combinable); len(combined) > 0 {
+			logRationales(logger, "the combined build options", combined)
+		}
+	}
+
+	return launch{
+		startLayer:   startLayer,
+		projectPath:  projectPath,
+		processes:    processes,
+		script:       script,
+		helpers:      helpers,
+		sources:      sources,
+		maintenance:  config.Maintenance,
+		strict:       config.Strict,
+		deprecations: deprecations,
+		metadata:     metadata,
+	}, nil
+}
+
+func hasProcess(processes []packit.Process, processType string) bool {
+	for _, process := range processes {
+		if process.Type == processType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeStaleNodeModules removes a node_modules symlink in the project path
+// that points into a layer that will not exist at launch, so that node fails
+// to resolve the dependencies with a clear message rather than ENOENT. With
+// BP_NPM_START_STRICT the build fails instead.
+func removeStaleNodeModules(projectPath, layersRoot string, strict bool, logger scribe.Emitter) error {
+	target, owner, stale, err := staleNodeModulesLink(projectPath, layersRoot)
+	if err != nil {
+		return err
+	}
+
+	if !stale {
+		return nil
+	}
+
+	if strict {
+		return fmt.Errorf("node_modules in %s is a symlink to %s, which the %s buildpack created in a previous build but is not part of this build (BP_NPM_START_STRICT=true)", projectPath, target, owner)
+	}
+
+	path := filepath.Join(projectPath, NodeModules)
+	err = os.Remove(path)
+	if err != nil {
+		return fmt.Errorf("failed to remove stale symlink %s: %w", path, err)
+	}
+
+	logger.Process("WARNING: removed node_modules, a symlink to %s, which does not exist in this build", target)
+	logger.Subprocess("The %s buildpack created it in a previous build but is not part of this build.", owner)
+	logger.Subprocess("Add a buildpack that installs node_modules, such as npm-install, if the app needs its dependencies.")
+	logger.Break()
+
+	return nil
+}
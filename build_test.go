@@ -2,14 +2,21 @@ package npmstart_test
 
 import (
 	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	npmstart "github.com/paketo-buildpacks/npm-start"
 	"github.com/paketo-buildpacks/npm-start/fakes"
 	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
 	"github.com/paketo-buildpacks/packit/v2/scribe"
 	"github.com/sclevine/spec"
 
@@ -25,6 +32,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		cnbDir     string
 		buffer     *bytes.Buffer
 		pathParser *fakes.PathParser
+		node       *fakes.Executable
 
 		build packit.BuildFunc
 	)
@@ -37,6 +45,11 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		cnbDir, err = os.MkdirTemp("", "cnb")
 		Expect(err).NotTo(HaveOccurred())
 
+		Expect(os.Mkdir(filepath.Join(cnbDir, "bin"), os.ModePerm)).To(Succeed())
+		for _, helper := range []string{"wait-for-bindings", "check-engines"} {
+			Expect(os.WriteFile(filepath.Join(cnbDir, "bin", helper), []byte(helper+"-contents"), 0755)).To(Succeed())
+		}
+
 		workingDir, err = os.MkdirTemp("", "working-dir")
 		Expect(err).NotTo(HaveOccurred())
 
@@ -56,7 +69,9 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		pathParser = &fakes.PathParser{}
 		pathParser.GetCall.Returns.ProjectPath = filepath.Join(workingDir, "some-project-dir")
 
-		build = npmstart.Build(pathParser, logger)
+		node = &fakes.Executable{}
+
+		build = npmstart.BuildWithNode(gocontext.Background(), pathParser, node, logger)
 	})
 
 	it.After(func() {
@@ -65,6 +80,83 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(os.RemoveAll(workingDir)).To(Succeed())
 	})
 
+	// launchEnv reads the launch environment that Build wrote into the
+	// layer, or that of the given process.
+	launchEnv := func(layer packit.Layer, process ...string) packit.Environment {
+		dir := filepath.Join(append([]string{layer.Path, "env.launch"}, process...)...)
+
+		env := packit.Environment{}
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			return env
+		}
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			Expect(err).NotTo(HaveOccurred())
+			env[entry.Name()] = string(content)
+		}
+
+		return env
+	}
+
+	// processLaunchEnv reads the launch environment of every process that
+	// Build wrote into the layer.
+	processLaunchEnv := func(layer packit.Layer) map[string]packit.Environment {
+		envs := map[string]packit.Environment{}
+		entries, err := os.ReadDir(filepath.Join(layer.Path, "env.launch"))
+		if os.IsNotExist(err) {
+			return envs
+		}
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				envs[entry.Name()] = launchEnv(layer, entry.Name())
+			}
+		}
+
+		return envs
+	}
+
+	// execD lists the exec.d executables that Build installed into the
+	// layer.
+	execD := func(layer packit.Layer) []string {
+		entries, err := os.ReadDir(filepath.Join(layer.Path, "exec.d"))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		Expect(err).NotTo(HaveOccurred())
+
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+
+		return names
+	}
+
+	// reloadPlan is the buildpack plan that Detect makes when it enables live
+	// reload with the given provider.
+	reloadPlan := func(provider string) packit.BuildpackPlan {
+		return packit.BuildpackPlan{
+			Entries: []packit.BuildpackPlanEntry{
+				{
+					Name: provider,
+					Metadata: map[string]interface{}{
+						"launch":      true,
+						"live-reload": true,
+					},
+				},
+			},
+		}
+	}
+
 	it("returns a result that builds correctly", func() {
 		result, err := build(packit.BuildContext{
 			WorkingDir: workingDir,
@@ -85,6 +177,26 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			Plan: packit.BuildpackPlan{
 				Entries: []packit.BuildpackPlanEntry{},
 			},
+			Layers: []packit.Layer{
+				{
+					Path:             filepath.Join(layersDir, "start"),
+					Name:             "start",
+					Launch:           true,
+					SharedEnv:        packit.Environment{},
+					BuildEnv:         packit.Environment{},
+					LaunchEnv:        packit.Environment{},
+					ProcessLaunchEnv: map[string]packit.Environment{},
+				},
+				{
+					Path:             filepath.Join(layersDir, "helpers"),
+					Name:             "helpers",
+					Launch:           true,
+					SharedEnv:        packit.Environment{},
+					BuildEnv:         packit.Environment{},
+					LaunchEnv:        packit.Environment{},
+					ProcessLaunchEnv: map[string]packit.Environment{},
+				},
+			},
 			Launch: packit.LaunchMetadata{
 				Processes: []packit.Process{
 					{
@@ -101,6 +213,12 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			},
 		}))
 
+		Expect(launchEnv(result.Layers[0])).To(Equal(packit.Environment{
+			"PATH.append": filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin"),
+			"PATH.delim":  ":",
+		}))
+		Expect(execD(result.Layers[1])).To(Equal([]string{"0-wait-for-bindings"}))
+
 		Expect(buffer.String()).To(ContainSubstring("Some Buildpack some-version"))
 		Expect(buffer.String()).To(ContainSubstring("Assigning launch processes:"))
 	})
@@ -123,9 +241,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 					Name:    "Some Buildpack",
 					Version: "some-version",
 				},
-				Plan: packit.BuildpackPlan{
-					Entries: []packit.BuildpackPlanEntry{},
-				},
+				Plan:   reloadPlan("watchexec"),
 				Layers: packit.Layers{Path: layersDir},
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -160,70 +276,387 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			}))
 			Expect(pathParser.GetCall.Receives.Path).To(Equal(workingDir))
 		})
+
+		context("when the app depends on a graceful restart library", func() {
+			for _, library := range npmstart.GracefulRestartLibraries {
+				library := library
+
+				context(fmt.Sprintf("when it depends on %s", library.Dependency), func() {
+					it.Before(func() {
+						err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(fmt.Sprintf(`{
+							"dependencies": {
+								%q: "1.0.0"
+							},
+							"scripts": {
+								"start": "some-start-command"
+							}
+						}`, library.Dependency)), 0600)
+						Expect(err).NotTo(HaveOccurred())
+					})
+
+					it("restarts the app with the library's signal", func() {
+						result, err := build(packit.BuildContext{
+							WorkingDir: workingDir,
+							CNBPath:    cnbDir,
+							Stack:      "some-stack",
+							BuildpackInfo: packit.BuildpackInfo{
+								Name:    "Some Buildpack",
+								Version: "some-version",
+							},
+							Plan:   reloadPlan("watchexec"),
+							Layers: packit.Layers{Path: layersDir},
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(result.Launch.Processes[0].Args[:3]).To(Equal([]string{"--restart", "--signal", library.Signal}))
+						Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Live reload will restart the app with %s", library.Signal)))
+						Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("The %q dependency restarts gracefully on %s", library.Dependency, library.Signal)))
+					})
+				})
+			}
+
+			context("when BP_LIVE_RELOAD_SIGNAL is set", func() {
+				it.Before(func() {
+					os.Setenv("BP_LIVE_RELOAD_SIGNAL", "hup")
+					err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+						"dependencies": {
+							"naught": "1.0.0"
+						},
+						"scripts": {
+							"start": "some-start-command"
+						}
+					}`), 0600)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it.After(func() {
+					os.Unsetenv("BP_LIVE_RELOAD_SIGNAL")
+				})
+
+				it("restarts the app with the given signal", func() {
+					result, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Stack:      "some-stack",
+						BuildpackInfo: packit.BuildpackInfo{
+							Name:    "Some Buildpack",
+							Version: "some-version",
+						},
+						Plan:   reloadPlan("watchexec"),
+						Layers: packit.Layers{Path: layersDir},
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(result.Launch.Processes[0].Args[:3]).To(Equal([]string{"--restart", "--signal", "SIGHUP"}))
+					Expect(buffer.String()).NotTo(ContainSubstring("Live reload will restart the app with"))
+				})
+			})
+
+			context("when BP_LIVE_RELOAD_PROVIDER=entr", func() {
+				it.Before(func() {
+					os.Setenv("BP_LIVE_RELOAD_PROVIDER", "entr")
+					err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+						"dependencies": {
+							"naught": "1.0.0"
+						},
+						"scripts": {
+							"start": "some-start-command"
+						}
+					}`), 0600)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it.After(func() {
+					os.Unsetenv("BP_LIVE_RELOAD_PROVIDER")
+				})
+
+				it("keeps the default restart signal of entr", func() {
+					result, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Stack:      "some-stack",
+						BuildpackInfo: packit.BuildpackInfo{
+							Name:    "Some Buildpack",
+							Version: "some-version",
+						},
+						Plan:   reloadPlan("entr"),
+						Layers: packit.Layers{Path: layersDir},
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(result.Launch.Processes[0].Args[1]).To(ContainSubstring("entr -n -r"))
+					Expect(buffer.String()).NotTo(ContainSubstring("Live reload will restart the app with"))
+				})
+			})
+		})
+
+		context("when BP_LIVE_RELOAD_PROVIDER=entr", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_PROVIDER", "entr")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_PROVIDER")
+			})
+
+			it("wraps the start command with entr", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan:   reloadPlan("entr"),
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				projectDir := filepath.Join(workingDir, "some-project-dir")
+				Expect(result.Launch.Processes[0]).To(Equal(packit.Process{
+					Type:    "web",
+					Command: "bash",
+					Args: []string{
+						"-c",
+						fmt.Sprintf(`find %[1]s '(' -path %[1]s/node_modules -o -path %[1]s/package.json -o -path %[1]s/package-lock.json ')' -prune -o -type f -print | entr -n -r bash -c 'cd %[1]s && some-prestart-command && some-start-command && some-poststart-command'`, projectDir),
+					},
+					Default: true,
+					Direct:  true,
+				}))
+				Expect(result.Launch.Processes[1].Type).To(Equal("no-reload"))
+			})
+		})
+
+		context("when BP_LIVE_RELOAD_COMMAND_TEMPLATE is set", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_PROVIDER", "some-reloader")
+				os.Setenv("BP_LIVE_RELOAD_COMMAND_TEMPLATE", "some-reloader --dir {{watch}} -- {{command}}")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_PROVIDER")
+				os.Unsetenv("BP_LIVE_RELOAD_COMMAND_TEMPLATE")
+			})
+
+			it("renders the template with the watched path and the start command", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan:   reloadPlan("some-reloader"),
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes[0]).To(Equal(packit.Process{
+					Type:    "web",
+					Command: "bash",
+					Args: []string{
+						"-c",
+						fmt.Sprintf(`some-reloader --dir %[1]s/some-project-dir -- bash -c 'cd %[1]s/some-project-dir && some-prestart-command && some-start-command && some-poststart-command'`, workingDir),
+					},
+					Default: true,
+					Direct:  true,
+				}))
+			})
+		})
 	})
 
-	context("when there is no prestart script", func() {
+	context("when BP_LIVE_RELOAD_ENABLED differs between detection and build", func() {
+		context("and detection enabled live reload", func() {
+			it("follows the plan and warns about the mismatch", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan:   reloadPlan("watchexec"),
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes).To(HaveLen(2))
+				Expect(result.Launch.Processes[0].Command).To(Equal("watchexec"))
+				Expect(result.Launch.Processes[1].Type).To(Equal("no-reload"))
+
+				Expect(buffer.String()).To(ContainSubstring("WARNING: BP_LIVE_RELOAD_ENABLED is false in the build environment but was true during detection"))
+				Expect(buffer.String()).To(ContainSubstring("Live reload stays enabled, as planned during detection."))
+			})
+		})
+
+		context("and detection did not enable live reload", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+			})
+
+			it("follows the plan and warns about the mismatch", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes).To(HaveLen(1))
+				Expect(result.Launch.Processes[0].Command).To(Equal("bash"))
+
+				Expect(buffer.String()).To(ContainSubstring("WARNING: BP_LIVE_RELOAD_ENABLED is true in the build environment but was false during detection"))
+				Expect(buffer.String()).To(ContainSubstring("Live reload stays disabled, as planned during detection."))
+			})
+		})
+	})
+
+	context("when the project declares dependencies", func() {
 		it.Before(func() {
 			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"dependencies": {
+					"some-dependency": "1.0.0",
+					"@some-scope/other-dependency": "2.0.0"
+				},
 				"scripts": {
-					"start": "some-start-command",
-					"poststart": "some-poststart-command"
+					"start": "some-start-command"
 				}
 			}`), 0600)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		it("specifies a valid start command", func() {
-			result, err := build(packit.BuildContext{
-				WorkingDir: workingDir,
-				CNBPath:    cnbDir,
-				Stack:      "some-stack",
-				BuildpackInfo: packit.BuildpackInfo{
-					Name:    "Some Buildpack",
-					Version: "some-version",
-				},
-				Plan: packit.BuildpackPlan{
-					Entries: []packit.BuildpackPlanEntry{},
-				},
-				Layers: packit.Layers{Path: layersDir},
+		context("and they are installed in the project path", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, "some-project-dir", "node_modules", "some-dependency"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin", "some-start-command"), nil, 0755)).To(Succeed())
 			})
-			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result).To(Equal(packit.BuildResult{
-				Plan: packit.BuildpackPlan{
-					Entries: []packit.BuildpackPlanEntry{},
-				},
-				Launch: packit.LaunchMetadata{
-					Processes: []packit.Process{
-						{
-							Type:    "web",
-							Command: "bash",
-							Args: []string{
-								"-c",
-								fmt.Sprintf("cd %s/some-project-dir && some-start-command && some-poststart-command", workingDir),
-							},
-							Direct:  true,
-							Default: true,
-						},
+			it("does not configure the launch environment", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
 					},
-				},
-			}))
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers).To(HaveLen(2))
+				Expect(launchEnv(result.Layers[0])).NotTo(HaveKey("NODE_PATH.default"))
+				Expect(buffer.String()).NotTo(ContainSubstring("WARNING"))
+			})
+		})
+
+		context("and they are installed in a parent directory", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, "node_modules", "some-dependency"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(workingDir, "node_modules", "@some-scope", "other-dependency"), os.ModePerm)).To(Succeed())
+			})
+
+			it("sets NODE_PATH to the parent node_modules directory", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers).To(HaveLen(2))
+				layer := result.Layers[0]
+				Expect(layer.Name).To(Equal("start"))
+				Expect(layer.Launch).To(BeTrue())
+				Expect(launchEnv(layer)).To(HaveKeyWithValue("NODE_PATH.default", filepath.Join(workingDir, "node_modules")))
+
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("%s/some-project-dir has no node_modules directory, using %s/node_modules", workingDir, workingDir)))
+			})
+		})
+
+		context("and no parent directory contains all of them", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, "node_modules", "some-dependency"), os.ModePerm)).To(Succeed())
+			})
+
+			it("warns that modules are missing", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers).To(HaveLen(2))
+				Expect(launchEnv(result.Layers[0])).NotTo(HaveKey("NODE_PATH.default"))
+				Expect(buffer.String()).To(ContainSubstring("WARNING: package.json declares dependencies but no node_modules directory containing them was found"))
+			})
 		})
 	})
 
-	context("when there is no poststart script", func() {
+	context("when devDependencies are installed in node_modules", func() {
 		it.Before(func() {
 			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"devDependencies": {
+					"some-dev-dependency": "1.0.0",
+					"other-dev-dependency": "1.0.0",
+					"@some-scope/dev-dependency": "1.0.0",
+					"another-dev-dependency": "1.0.0",
+					"missing-dev-dependency": "1.0.0"
+				},
 				"scripts": {
-					"prestart": "some-prestart-command",
 					"start": "some-start-command"
 				}
 			}`), 0600)
 			Expect(err).NotTo(HaveOccurred())
+
+			for _, name := range []string{"some-dev-dependency", "other-dev-dependency", "@some-scope/dev-dependency", "another-dev-dependency"} {
+				dir := filepath.Join(workingDir, "some-project-dir", "node_modules", name)
+				Expect(os.MkdirAll(dir, os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dir, "index.js"), make([]byte, 1024), 0600)).To(Succeed())
+			}
+
+			Expect(os.MkdirAll(filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin", "some-start-command"), nil, 0755)).To(Succeed())
 		})
 
-		it("specifies a valid start command", func() {
-			result, err := build(packit.BuildContext{
+		it("warns with the estimated size of the installed devDependencies", func() {
+			_, err := build(packit.BuildContext{
 				WorkingDir: workingDir,
 				CNBPath:    cnbDir,
 				Stack:      "some-stack",
@@ -238,40 +671,79 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result).To(Equal(packit.BuildResult{
-				Plan: packit.BuildpackPlan{
-					Entries: []packit.BuildpackPlanEntry{},
-				},
-				Launch: packit.LaunchMetadata{
-					Processes: []packit.Process{
-						{
-							Type:    "web",
-							Command: "bash",
-							Args: []string{
-								"-c",
-								fmt.Sprintf("cd %s/some-project-dir && some-prestart-command && some-start-command", workingDir),
-							},
-							Direct:  true,
-							Default: true,
-						},
+			Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("WARNING: 4 devDependencies are installed in %s/some-project-dir/node_modules (4.0 KB)", workingDir)))
+			Expect(buffer.String()).To(ContainSubstring("Configure the npm-install buildpack to prune devDependencies"))
+		})
+
+		context("when no more than the threshold are installed", func() {
+			it.Before(func() {
+				Expect(os.RemoveAll(filepath.Join(workingDir, "some-project-dir", "node_modules", "another-dev-dependency"))).To(Succeed())
+			})
+
+			it("does not warn", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
 					},
-				},
-			}))
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).NotTo(ContainSubstring("WARNING"))
+			})
+		})
+
+		context("when BP_NPM_START_SUPPRESS_WARNINGS=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_SUPPRESS_WARNINGS", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_SUPPRESS_WARNINGS")
+			})
+
+			it("does not warn", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).NotTo(ContainSubstring("WARNING"))
+			})
 		})
 	})
 
-	context("when there is no start script", func() {
+	context("when the package.json declares a custom bin directory", func() {
 		it.Before(func() {
 			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"directories": {
+					"bin": "./some-bin-dir"
+				},
 				"scripts": {
-					"prestart": "some-prestart-command",
-					"poststart": "some-poststart-command"
+					"start": "some-start-command"
 				}
 			}`), 0600)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		it("specifies a valid start command", func() {
+		it("appends it to the PATH after node_modules/.bin", func() {
 			result, err := build(packit.BuildContext{
 				WorkingDir: workingDir,
 				CNBPath:    cnbDir,
@@ -287,47 +759,56 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result).To(Equal(packit.BuildResult{
-				Plan: packit.BuildpackPlan{
-					Entries: []packit.BuildpackPlanEntry{},
-				},
-				Launch: packit.LaunchMetadata{
-					Processes: []packit.Process{
-						{
-							Type:    "web",
-							Command: "bash",
-							Args: []string{
-								"-c",
-								fmt.Sprintf("cd %[1]s/some-project-dir && some-prestart-command && node %[1]s/server.js && some-poststart-command", workingDir),
-							},
-							Direct:  true,
-							Default: true,
-						},
-					},
-				},
+			Expect(launchEnv(result.Layers[0])).To(Equal(packit.Environment{
+				"PATH.append": strings.Join([]string{
+					filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin"),
+					filepath.Join(workingDir, "some-project-dir", "some-bin-dir"),
+				}, ":"),
+				"PATH.delim": ":",
 			}))
 		})
 	})
 
-	context("when the project-path env var is not set", func() {
+	context("when the project path is a member of a workspace", func() {
 		it.Before(func() {
-			pathParser.GetCall.Returns.ProjectPath = workingDir
-
 			err := os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{
-				"scripts": {
-					"prestart": "some-prestart-command",
-					"start": "some-start-command",
-					"poststart": "some-poststart-command"
-				}
+				"workspaces": ["some-project-dir"]
 			}`), 0600)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		it.After(func() {
-			Expect(os.Remove(filepath.Join(workingDir, "package.json"))).To(Succeed())
+		it("appends the node_modules/.bin of both the project and the workspace root to the PATH", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(launchEnv(result.Layers[0])).To(Equal(packit.Environment{
+				"PATH.append": strings.Join([]string{
+					filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin"),
+					filepath.Join(workingDir, "node_modules", ".bin"),
+				}, ":"),
+				"PATH.delim": ":",
+			}))
 		})
+	})
 
-		it("returns a result with a valid start command", func() {
+	context("when the root package.json of the app is not a workspace", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{}`), 0600)).To(Succeed())
+		})
+
+		it("only appends the project node_modules/.bin to the PATH", func() {
 			result, err := build(packit.BuildContext{
 				WorkingDir: workingDir,
 				CNBPath:    cnbDir,
@@ -343,36 +824,61 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result).To(Equal(packit.BuildResult{
+			Expect(launchEnv(result.Layers[0])).To(Equal(packit.Environment{
+				"PATH.append": filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin"),
+				"PATH.delim":  ":",
+			}))
+		})
+	})
+
+	context("when the platform API supports overridable process arguments", func() {
+		it.Before(func() {
+			os.Setenv("CNB_PLATFORM_API", "0.10")
+		})
+
+		it.After(func() {
+			os.Unsetenv("CNB_PLATFORM_API")
+		})
+
+		it("forwards arguments appended at launch to the start command", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
 				Plan: packit.BuildpackPlan{
 					Entries: []packit.BuildpackPlanEntry{},
 				},
-				Launch: packit.LaunchMetadata{
-					Processes: []packit.Process{
-						{
-							Type:    "web",
-							Command: "bash",
-							Args: []string{
-								"-c",
-								"some-prestart-command && some-start-command && some-poststart-command",
-							},
-							Direct:  true,
-							Default: true,
-						},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "web",
+					Command: "bash",
+					Args: []string{
+						"-c",
+						fmt.Sprintf(`cd %s/some-project-dir && some-prestart-command && some-start-command "$@" && some-poststart-command`, workingDir),
+						"bash",
 					},
+					Default: true,
+					Direct:  true,
 				},
 			}))
 		})
-	})
 
-	context("failure cases", func() {
-		context("when the package.json file does not exist", func() {
+		context("when the start command runs node directly", func() {
 			it.Before(func() {
-				Expect(os.Remove(filepath.Join(workingDir, "some-project-dir", "package.json"))).To(Succeed())
+				pathParser.GetCall.Returns.ProjectPath = workingDir
+				Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{}`), 0600)).To(Succeed())
 			})
 
-			it("returns an error", func() {
-				_, err := build(packit.BuildContext{
+			it("leaves the arguments of the node process open for appending", func() {
+				result, err := build(packit.BuildContext{
 					WorkingDir: workingDir,
 					CNBPath:    cnbDir,
 					Stack:      "some-stack",
@@ -385,17 +891,33 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 					},
 					Layers: packit.Layers{Path: layersDir},
 				})
-				Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes).To(Equal([]packit.Process{
+					{
+						Type:    "web",
+						Command: "node",
+						Args:    []string{filepath.Join(workingDir, "server.js")},
+						Default: true,
+						Direct:  true,
+					},
+				}))
 			})
 		})
 
-		context("when the package.json is malformed", func() {
+		context("when the start script already passes arguments after npm's separator", func() {
+			var buffer *bytes.Buffer
+
 			it.Before(func() {
-				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte("%%%"), 0600)).To(Succeed())
+				pathParser.GetCall.Returns.ProjectPath = workingDir
+				Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{"scripts": {"start": "npm run serve -- --flag"}}`), 0600)).To(Succeed())
+
+				buffer = bytes.NewBuffer(nil)
+				build = npmstart.BuildWithNode(gocontext.Background(), pathParser, node, scribe.NewEmitter(buffer).WithLevel("DEBUG"))
 			})
 
-			it("returns an error", func() {
-				_, err := build(packit.BuildContext{
+			it("appends the arguments after it and notes it in the debug log", func() {
+				result, err := build(packit.BuildContext{
 					WorkingDir: workingDir,
 					CNBPath:    cnbDir,
 					Stack:      "some-stack",
@@ -408,7 +930,4812 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 					},
 					Layers: packit.Layers{Path: layersDir},
 				})
-				Expect(err).To(MatchError(ContainSubstring("invalid character '%'")))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes).To(HaveLen(1))
+				Expect(result.Launch.Processes[0].Args).To(ContainElement(ContainSubstring(`npm run serve -- --flag "$@"`)))
+
+				Expect(buffer.String()).To(ContainSubstring(`The start script already passes arguments after a "--" separator`))
+				Expect(buffer.String()).To(ContainSubstring("The forwarded arguments are appended after it rather than after a new one"))
+			})
+		})
+	})
+
+	context("when the platform API predates overridable process arguments", func() {
+		it.Before(func() {
+			os.Setenv("CNB_PLATFORM_API", "0.9")
+		})
+
+		it.After(func() {
+			os.Unsetenv("CNB_PLATFORM_API")
+		})
+
+		it("emits the process without argument forwarding", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "web",
+					Command: "bash",
+					Args: []string{
+						"-c",
+						fmt.Sprintf("cd %s/some-project-dir && some-prestart-command && some-start-command && some-poststart-command", workingDir),
+					},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+		})
+	})
+
+	context("when BP_NPM_START_ARGS_POLICY is set", func() {
+		var buildProcesses func() ([]packit.Process, error)
+
+		it.Before(func() {
+			buildProcesses = func() ([]packit.Process, error) {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				return result.Launch.Processes, err
+			}
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_ARGS_POLICY")
+			os.Unsetenv("CNB_PLATFORM_API")
+		})
+
+		context("to overridable", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_ARGS_POLICY", "overridable")
+			})
+
+			context("when the platform API supports overridable process arguments", func() {
+				it.Before(func() {
+					os.Setenv("CNB_PLATFORM_API", "0.11")
+				})
+
+				it("forwards arguments appended at launch to the start command", func() {
+					processes, err := buildProcesses()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes).To(Equal([]packit.Process{
+						{
+							Type:    "web",
+							Command: "bash",
+							Args: []string{
+								"-c",
+								fmt.Sprintf(`cd %s/some-project-dir && some-prestart-command && some-start-command "$@" && some-poststart-command`, workingDir),
+								"bash",
+							},
+							Default: true,
+							Direct:  true,
+						},
+					}))
+				})
+			})
+
+			context("when the platform API predates overridable process arguments", func() {
+				it.Before(func() {
+					os.Setenv("CNB_PLATFORM_API", "0.9")
+				})
+
+				it("returns an error", func() {
+					_, err := buildProcesses()
+					Expect(err).To(MatchError("BP_NPM_START_ARGS_POLICY=overridable requires platform API 0.10 or newer, but the platform implements 0.9, which does not let arguments be appended at launch"))
+					Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+				})
+			})
+
+			context("when the platform API is not advertised", func() {
+				it("returns an error", func() {
+					_, err := buildProcesses()
+					Expect(err).To(MatchError("BP_NPM_START_ARGS_POLICY=overridable requires platform API 0.10 or newer, but the platform does not advertise its API through CNB_PLATFORM_API"))
+				})
+			})
+		})
+
+		context("to locked", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_ARGS_POLICY", "locked")
+			})
+
+			context("when the platform API supports overridable process arguments", func() {
+				it.Before(func() {
+					os.Setenv("CNB_PLATFORM_API", "0.10")
+				})
+
+				it("does not forward arguments appended at launch", func() {
+					processes, err := buildProcesses()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes).To(Equal([]packit.Process{
+						{
+							Type:    "web",
+							Command: "bash",
+							Args: []string{
+								"-c",
+								fmt.Sprintf("cd %s/some-project-dir && some-prestart-command && some-start-command && some-poststart-command", workingDir),
+							},
+							Default: true,
+							Direct:  true,
+						},
+					}))
+				})
+
+				context("when the start command runs node directly", func() {
+					it.Before(func() {
+						pathParser.GetCall.Returns.ProjectPath = workingDir
+						Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{}`), 0600)).To(Succeed())
+					})
+
+					it("folds the node entrypoint into the shell command", func() {
+						processes, err := buildProcesses()
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(processes).To(Equal([]packit.Process{
+							{
+								Type:    "web",
+								Command: "bash",
+								Args:    []string{"-c", fmt.Sprintf("node %s", filepath.Join(workingDir, "server.js"))},
+								Default: true,
+								Direct:  true,
+							},
+						}))
+					})
+				})
+			})
+
+			context("when the platform API predates overridable process arguments", func() {
+				it.Before(func() {
+					os.Setenv("CNB_PLATFORM_API", "0.9")
+				})
+
+				it("does not forward arguments appended at launch", func() {
+					processes, err := buildProcesses()
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes).To(HaveLen(1))
+					Expect(processes[0].Args).To(Equal([]string{
+						"-c",
+						fmt.Sprintf("cd %s/some-project-dir && some-prestart-command && some-start-command && some-poststart-command", workingDir),
+					}))
+				})
+			})
+		})
+
+		context("to an invalid value", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_ARGS_POLICY", "sometimes")
+			})
+
+			it("returns an error", func() {
+				_, err := buildProcesses()
+				Expect(err).To(MatchError("failed to parse BP_NPM_START_ARGS_POLICY value sometimes: expected overridable or locked"))
+			})
+		})
+	})
+
+	context("when BP_NPM_START_FORWARD_PORT=true in the build environment", func() {
+		it.Before(func() {
+			os.Setenv("BP_NPM_START_FORWARD_PORT", "true")
+			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"scripts": {
+					"start": "npm run serve",
+					"serve": "vue-cli-service serve"
+				}
+			}`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_FORWARD_PORT")
+		})
+
+		it("forwards $PORT to the start command", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "web",
+					Command: "bash",
+					Args: []string{
+						"-c",
+						fmt.Sprintf(`cd %s && npm run serve -- --port "${PORT}"`, filepath.Join(workingDir, "some-project-dir")),
+					},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+		})
+	})
+
+	context("when BP_NPM_START_PROCESS_ENV is set", func() {
+		it.Before(func() {
+			os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+			os.Setenv("BP_NPM_START_PROCESS_ENV", `no-reload:NODE_OPTIONS=--max-old-space-size=4096;web:NODE_ENV=development;web:SOME_LIST=a\;b`)
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+			os.Unsetenv("BP_NPM_START_PROCESS_ENV")
+		})
+
+		it("sets the launch environment of each named process", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan:   reloadPlan("watchexec"),
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			Expect(processLaunchEnv(result.Layers[0])).To(Equal(map[string]packit.Environment{
+				"no-reload": {
+					"NODE_OPTIONS.override": "--max-old-space-size=4096",
+				},
+				"web": {
+					"NODE_ENV.override":  "development",
+					"SOME_LIST.override": "a;b",
+				},
+			}))
+		})
+	})
+
+	context("when BP_NPM_START_PROCESS_ENV sets NODE_OPTIONS more than once for a process", func() {
+		it.Before(func() {
+			os.Setenv("BP_NPM_START_PROCESS_ENV", "web:NODE_OPTIONS=--max-old-space-size=1024 -r ./tracing.js;web:NODE_OPTIONS=--max-old-space-size=4096 --enable-source-maps")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_PROCESS_ENV")
+		})
+
+		it("merges the entries flag by flag", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			Expect(launchEnv(result.Layers[0], "web")).To(Equal(packit.Environment{
+				"NODE_OPTIONS.override": "--max-old-space-size=4096 -r ./tracing.js --enable-source-maps",
+			}))
+		})
+
+		context("when one of the entries is not a valid NODE_OPTIONS value", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_PROCESS_ENV", "web:NODE_OPTIONS=--enable-source-maps;web:NODE_OPTIONS=server.js")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring(`failed to merge BP_NPM_START_PROCESS_ENV entries for the web process: failed to parse NODE_OPTIONS value server.js: "server.js" is not a flag`)))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+	})
+
+	context("when the start script passes flags to node", func() {
+		var scripts = []string{
+			"node --experimental-wasm-modules server.mjs",
+			"node --experimental-loader ./loader.mjs --no-warnings -r ./tracing.js server.mjs",
+			`node --title "some app" --max-old-space-size=4096 app.wasm --port 8080`,
+		}
+
+		var processArgs = func(start string, plan packit.BuildpackPlan) [][]string {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(fmt.Sprintf(`{"scripts": {"start": %q}}`, start)), 0600)).To(Succeed())
+
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				Plan:       plan,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var args [][]string
+			for _, process := range result.Launch.Processes {
+				args = append(args, process.Args)
+			}
+			return args
+		}
+
+		it("passes them through untouched", func() {
+			for _, start := range scripts {
+				args := processArgs(start, packit.BuildpackPlan{})
+				Expect(args).To(Equal([][]string{{"-c", fmt.Sprintf("cd %s && %s", filepath.Join(workingDir, "some-project-dir"), start)}}), start)
+			}
+		})
+
+		context("when launch arguments are forwarded", func() {
+			it.Before(func() {
+				os.Setenv("CNB_PLATFORM_API", "0.10")
+				os.Setenv("BP_NPM_START_ARGS_POLICY", "overridable")
+			})
+
+			it.After(func() {
+				os.Unsetenv("CNB_PLATFORM_API")
+				os.Unsetenv("BP_NPM_START_ARGS_POLICY")
+			})
+
+			it("passes them through untouched", func() {
+				for _, start := range scripts {
+					args := processArgs(start, packit.BuildpackPlan{})
+					Expect(args[0][1]).To(ContainSubstring(start+` "$@"`), start)
+				}
+			})
+		})
+
+		context("when live reload is enabled", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+			})
+
+			it("passes them through untouched in both processes", func() {
+				for _, provider := range []string{"watchexec", "entr"} {
+					for _, start := range scripts {
+						args := processArgs(start, reloadPlan(provider))
+						Expect(args).To(HaveLen(2))
+						for _, processArgs := range args {
+							Expect(strings.Join(processArgs, " ")).To(ContainSubstring(start), provider)
+						}
+					}
+				}
+			})
+		})
+	})
+
+	context("when BP_NPM_START_DEBUG_PROCESSES=true", func() {
+		var buildResult func() packit.BuildResult
+
+		it.Before(func() {
+			os.Setenv("BP_NPM_START_DEBUG_PROCESSES", "true")
+			os.Setenv("BP_NPM_START_PROCESS_ENV", "web:NODE_ENV=development;web:DEBUG=app:*;shell:DEBUG=*")
+
+			buildResult = func() packit.BuildResult {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				return result
+			}
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_DEBUG_PROCESSES")
+			os.Unsetenv("BP_NPM_START_PROCESS_ENV")
+		})
+
+		it("adds a non-default shell process in the project path with the environment of the web process", func() {
+			result := buildResult()
+
+			Expect(result.Launch.Processes).To(HaveLen(2))
+			Expect(result.Launch.Processes[1]).To(Equal(packit.Process{
+				Type:    "shell",
+				Command: "sh",
+				Args:    []string{"-c", fmt.Sprintf("cd %s && exec sh -i", filepath.Join(workingDir, "some-project-dir"))},
+				Direct:  true,
+			}))
+
+			Expect(launchEnv(result.Layers[0], "shell")).To(Equal(packit.Environment{
+				"NODE_ENV.override": "development",
+				"DEBUG.override":    "*",
+			}))
+		})
+
+		context("when BP_NPM_START_SHELL=bash", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_SHELL", "bash")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_SHELL")
+			})
+
+			it("opens bash", func() {
+				result := buildResult()
+
+				Expect(result.Launch.Processes[1].Command).To(Equal("bash"))
+				Expect(result.Launch.Processes[1].Args).To(Equal([]string{"-c", fmt.Sprintf("cd %s && exec bash -i", filepath.Join(workingDir, "some-project-dir"))}))
+			})
+		})
+
+		context("when the project path is the working directory", func() {
+			it.Before(func() {
+				pathParser.GetCall.Returns.ProjectPath = workingDir
+				Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{"scripts": {"start": "some-start-command"}}`), 0600)).To(Succeed())
+			})
+
+			it("opens the shell right away", func() {
+				result := buildResult()
+
+				Expect(result.Launch.Processes[1].Command).To(Equal("sh"))
+				Expect(result.Launch.Processes[1].Args).To(Equal([]string{"-i"}))
+			})
+		})
+
+		context("when the image is a worker", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_WORKLOAD_TYPE", "worker")
+				os.Setenv("BP_NPM_START_PROCESS_ENV", "worker:QUEUE=jobs")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_WORKLOAD_TYPE")
+			})
+
+			it("gives the shell the environment of the worker process", func() {
+				result := buildResult()
+
+				Expect(launchEnv(result.Layers[0], "shell")).To(Equal(packit.Environment{
+					"QUEUE.override": "jobs",
+				}))
+			})
+		})
+	})
+
+	context("when BP_NPM_START_MINIMAL_LAUNCH=true", func() {
+		it.Before(func() {
+			os.Setenv("BP_NPM_START_MINIMAL_LAUNCH", "true")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_MINIMAL_LAUNCH")
+		})
+
+		it("installs no launch helpers and keeps the process", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(1))
+			Expect(execD(result.Layers[0])).To(BeEmpty())
+			Expect(filepath.Join(layersDir, "start", "exec.d")).NotTo(BeADirectory())
+			Expect(filepath.Join(layersDir, "start", "profile.d")).NotTo(BeADirectory())
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "web",
+					Command: "bash",
+					Args:    []string{"-c", fmt.Sprintf("cd %s && some-prestart-command && some-start-command && some-poststart-command", filepath.Join(workingDir, "some-project-dir"))},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+
+			Expect(buffer.String()).To(ContainSubstring("Omitting the optional launch helpers (BP_NPM_START_MINIMAL_LAUNCH=true)"))
+			Expect(buffer.String()).To(ContainSubstring("wait-for-bindings: BPL_NPM_START_WAIT_FOR_BINDINGS has no effect at launch"))
+		})
+
+		it("removes the helpers layer of a previous build", func() {
+			Expect(os.MkdirAll(filepath.Join(layersDir, "helpers", "exec.d"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(layersDir, "helpers", "exec.d", "0-wait-for-bindings"), []byte("stale-contents"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(layersDir, "helpers.toml"), []byte("launch = true\n"), 0600)).To(Succeed())
+
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(1))
+			Expect(filepath.Join(layersDir, "helpers.toml")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(layersDir, "helpers", "exec.d")).NotTo(BeADirectory())
+		})
+	})
+
+	context("when two different apps are built with the same configuration", func() {
+		it("installs their launch helpers into identical layers", func() {
+			otherWorkingDir, err := os.MkdirTemp("", "other-working-dir")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(otherWorkingDir)
+
+			otherLayersDir, err := os.MkdirTemp("", "other-layers")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(otherLayersDir)
+
+			Expect(os.WriteFile(filepath.Join(otherWorkingDir, "package.json"), []byte(`{
+				"name": "other-app",
+				"scripts": {
+					"start": "node other-server.js"
+				}
+			}`), 0600)).To(Succeed())
+
+			var helpers []packit.Layer
+			for _, dirs := range [][3]string{
+				{workingDir, filepath.Join(workingDir, "some-project-dir"), layersDir},
+				{otherWorkingDir, otherWorkingDir, otherLayersDir},
+			} {
+				pathParser.GetCall.Returns.ProjectPath = dirs[1]
+
+				result, err := build(packit.BuildContext{
+					WorkingDir: dirs[0],
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: dirs[2]},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers).To(HaveLen(2))
+				Expect(result.Layers[0].Name).To(Equal("start"))
+				Expect(result.Layers[1].Name).To(Equal("helpers"))
+
+				layer := result.Layers[1]
+				Expect(execD(layer)).To(Equal([]string{"0-wait-for-bindings"}))
+				Expect(launchEnv(layer)).To(BeEmpty())
+				Expect(processLaunchEnv(layer)).To(BeEmpty())
+
+				layer.Path = ""
+				helpers = append(helpers, layer)
+			}
+
+			Expect(helpers[0].Metadata).To(BeEmpty())
+			Expect(helpers[1]).To(Equal(helpers[0]))
+
+			var contents []string
+			for _, dir := range []string{layersDir, otherLayersDir} {
+				entries, err := os.ReadDir(filepath.Join(dir, "helpers"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entries).To(HaveLen(1))
+
+				path := filepath.Join(dir, "helpers", "exec.d", "0-wait-for-bindings")
+				info, err := os.Stat(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.ModTime().UTC()).To(Equal(npmstart.ReproducibleTime))
+
+				content, err := os.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+				contents = append(contents, string(content))
+			}
+			Expect(contents).To(Equal([]string{"wait-for-bindings-contents", "wait-for-bindings-contents"}))
+		})
+	})
+
+	context("when BP_NPM_START_ENGINES_STRICT=true", func() {
+		it.Before(func() {
+			os.Setenv("BP_NPM_START_ENGINES_STRICT", "true")
+
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"engines": {
+					"node": ">=18 <21"
+				},
+				"scripts": {
+					"start": "some-start-command"
+				}
+			}`), 0600)).To(Succeed())
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_ENGINES_STRICT")
+		})
+
+		it("installs the check-engines helper with the engines.node range", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			Expect(execD(result.Layers[1])).To(Equal([]string{"0-wait-for-bindings", "1-check-engines"}))
+			Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("BPL_NPM_START_ENGINES_NODE.default", ">=18 <21"))
+			Expect(result.Layers[0].Metadata).To(Equal(map[string]interface{}{
+				"engines-node": ">=18 <21",
+			}))
+		})
+
+		context("when the package.json has no engines.node range", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+					"scripts": {
+						"start": "some-start-command"
+					}
+				}`), 0600)).To(Succeed())
+			})
+
+			it("installs no check-engines helper", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(execD(result.Layers[1])).To(Equal([]string{"0-wait-for-bindings"}))
+				Expect(launchEnv(result.Layers[0])).NotTo(HaveKey("BPL_NPM_START_ENGINES_NODE.default"))
+				Expect(buffer.String()).To(ContainSubstring("BP_NPM_START_ENGINES_STRICT is set, but package.json has no engines.node range."))
+			})
+		})
+
+		context("and BP_NPM_START_MINIMAL_LAUNCH=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_MINIMAL_LAUNCH", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_MINIMAL_LAUNCH")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("BP_NPM_START_ENGINES_STRICT and BP_NPM_START_MINIMAL_LAUNCH: a minimal launch leaves out the helper that checks the node version; unset BP_NPM_START_MINIMAL_LAUNCH")))
+			})
+		})
+	})
+
+	context("when the builder mandates node arguments", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(cnbDir, "config"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(cnbDir, "config", "mandatory-node-args"), []byte("# required by the platform security team\n--require /platform/agent.js\n\n--max-old-space-size=1024\n"), 0600)).To(Succeed())
+		})
+
+		it("appends them to NODE_OPTIONS and records them in a label", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("NODE_OPTIONS.append", "--require /platform/agent.js --max-old-space-size=1024"))
+			Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("NODE_OPTIONS.delim", " "))
+			Expect(result.Launch.Labels).To(HaveKeyWithValue("io.paketo.npm-start.mandatory-node-args", "--require /platform/agent.js --max-old-space-size=1024"))
+
+			Expect(buffer.String()).To(ContainSubstring("Appending the mandatory node arguments of the builder to NODE_OPTIONS"))
+
+			Expect(strings.Count(buffer.String(), "Configuring launch environment")).To(Equal(1))
+			Expect(strings.Index(buffer.String(), "Configuring launch environment")).To(BeNumerically(">", strings.Index(buffer.String(), "Appending the mandatory node arguments")))
+			Expect(buffer.String()).To(ContainSubstring(`NODE_OPTIONS -> "$NODE_OPTIONS --require /platform/agent.js --max-old-space-size=1024"`))
+		})
+
+		context("when BP_NPM_START_PROCESS_ENV overrides NODE_OPTIONS of a process", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_PROCESS_ENV", "web:NODE_OPTIONS=--max-old-space-size=4096 --no-require-agent --enable-source-maps")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_PROCESS_ENV")
+			})
+
+			it("merges them into the override, taking precedence over the flags of the app", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(launchEnv(result.Layers[0], "web")).To(HaveKeyWithValue("NODE_OPTIONS.override", "--max-old-space-size=1024 --no-require-agent --enable-source-maps --require /platform/agent.js"))
+				Expect(buffer.String()).To(ContainSubstring(`NODE_OPTIONS -> "--max-old-space-size=1024 --no-require-agent --enable-source-maps --require /platform/agent.js"`))
+			})
+		})
+
+		context("when BP_NPM_START_EXPLAIN=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_EXPLAIN", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_EXPLAIN")
+			})
+
+			it("explains the injection", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(ContainSubstring("Explaining the builder configuration"))
+				Expect(buffer.String()).To(ContainSubstring("NODE_OPTIONS: --require /platform/agent.js --max-old-space-size=1024 is appended for every process, as listed in config/mandatory-node-args of the buildpack"))
+			})
+		})
+
+		context("when the file does not hold node flags", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "config", "mandatory-node-args"), []byte("agent.js\n"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to parse config/mandatory-node-args")))
+			})
+		})
+	})
+
+	context("when the builder mandates no node arguments", func() {
+		it("leaves NODE_OPTIONS alone", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(launchEnv(result.Layers[0])).NotTo(HaveKey("NODE_OPTIONS.append"))
+			Expect(result.Launch.Labels).NotTo(HaveKey("io.paketo.npm-start.mandatory-node-args"))
+		})
+	})
+
+	context("when the start script runs a directory with node", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"main": "lib/server.js",
+				"scripts": {"start": "node ."}
+			}`), 0600)).To(Succeed())
+		})
+
+		context("when node resolves it", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, "some-project-dir", "lib"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "lib", "server.js"), nil, 0600)).To(Succeed())
+				os.Setenv("BP_NPM_START_EXPLAIN", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_EXPLAIN")
+			})
+
+			it("keeps the start script as written and reports the resolved file", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes[0].Args).To(Equal([]string{"-c", fmt.Sprintf("cd %s && node .", filepath.Join(workingDir, "some-project-dir"))}))
+				Expect(buffer.String()).To(ContainSubstring("runs the package.json start script, where node resolves . to lib/server.js"))
+				Expect(buffer.String()).NotTo(ContainSubstring("[missing-entrypoint]"))
+			})
+		})
+
+		context("when node cannot resolve it", func() {
+			it("warns", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(ContainSubstring(`WARNING: the start script runs "." with node, which cannot resolve it [missing-entrypoint]`))
+			})
+		})
+	})
+
+	context("when the entrypoint of the start script differs from a file only in case", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{"scripts": {"start": "node ./Server.js"}}`), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "server.js"), nil, 0600)).To(Succeed())
+		})
+
+		it("warns naming both spellings", func() {
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring(`WARNING: the start script runs "./Server.js" with node, but the file is named "./server.js" [missing-entrypoint]`))
+			Expect(buffer.String()).To(ContainSubstring("File names are case-sensitive at launch, so node cannot find the file. Rename the file or change the start script to match."))
+			Expect(buffer.String()).NotTo(ContainSubstring("which cannot resolve it"))
+		})
+
+		context("when BP_NPM_START_STRICT=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_STRICT", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_STRICT")
+			})
+
+			it("fails the build", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(`start script runs "./Server.js" with node, but the file is named "./server.js", and file names are case-sensitive at launch (BP_NPM_START_STRICT=true)`))
+			})
+		})
+
+		context("when several files differ from it only in case", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "SERVER.js"), nil, 0600)).To(Succeed())
+				os.Setenv("BP_NPM_START_STRICT", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_STRICT")
+			})
+
+			it("warns that node cannot resolve it and lists the variants", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(ContainSubstring(`WARNING: the start script runs "./Server.js" with node, which cannot resolve it [missing-entrypoint]`))
+				Expect(buffer.String()).To(ContainSubstring(`"./SERVER.js", "./server.js" differ from it only in case; file names are case-sensitive at launch.`))
+			})
+		})
+	})
+
+	context("when BP_NPM_START_SLIM=true", func() {
+		it.Before(func() {
+			os.Setenv("BP_NPM_START_ENGINES_STRICT", "true")
+			os.Setenv("BP_NPM_START_DEPRECATIONS_FILE", "deprecations.json")
+
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"version": "1.0.0",
+				"engines": {"node": ">=18"},
+				"scripts": {"start": "node server.js", "healthcheck": "node health.js"}
+			}`), 0600)).To(Succeed())
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_ENGINES_STRICT")
+			os.Unsetenv("BP_NPM_START_DEPRECATIONS_FILE")
+			os.Unsetenv("BP_NPM_START_SLIM")
+		})
+
+		it("computes the same processes and emits no optional metadata", func() {
+			full, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(full.Launch.Labels).NotTo(BeEmpty())
+			Expect(full.Layers[0].Metadata).NotTo(BeEmpty())
+			Expect(os.Remove(filepath.Join(workingDir, "deprecations.json"))).To(Succeed())
+
+			os.Setenv("BP_NPM_START_SLIM", "true")
+			buffer.Reset()
+
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(Equal(full.Launch.Processes))
+			Expect(result.Launch.Labels).To(BeEmpty())
+			Expect(result.Launch.BOM).To(BeEmpty())
+			Expect(result.Launch.SBOM).To(BeZero())
+			Expect(result.Build).To(BeZero())
+
+			Expect(result.Layers).To(HaveLen(2))
+			Expect(result.Layers[0].Metadata).To(BeEmpty())
+			Expect(result.Layers[0].SBOM).To(BeZero())
+
+			entries, err := os.ReadDir(filepath.Join(layersDir, "start"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Name()).To(Equal("env.launch"))
+			Expect(filepath.Join(workingDir, "deprecations.json")).NotTo(BeAnExistingFile())
+
+			Expect(buffer.String()).To(ContainSubstring("Slim build: emitting no image labels, layer metadata or report files (BP_NPM_START_SLIM=true)"))
+		})
+
+		context("and BP_NPM_START_WORKLOAD_TYPE=worker", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_SLIM", "true")
+				os.Setenv("BP_NPM_START_WORKLOAD_TYPE", "worker")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_WORKLOAD_TYPE")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("a slim build leaves out the label that tells the platform to skip HTTP health checks of a worker; unset BP_NPM_START_SLIM")))
+			})
+		})
+
+		context("and BP_NPM_START_LABELS is set", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_SLIM", "true")
+				os.Setenv("BP_NPM_START_LABELS", "some-key=some-value")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_LABELS")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("BP_NPM_START_LABELS and BP_NPM_START_SLIM: a slim build leaves out every image label, including those of BP_NPM_START_LABELS; unset BP_NPM_START_SLIM or BP_NPM_START_LABELS")))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("and BP_NPM_START_REVISION is set", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_SLIM", "true")
+				os.Setenv("BP_NPM_START_REVISION", "some-revision")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_REVISION")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("BP_NPM_START_REVISION and BP_NPM_START_SLIM: a slim build leaves out the io.paketo.npm-start.revision label, so only APP_REVISION would be set; unset BP_NPM_START_SLIM or BP_NPM_START_REVISION")))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("and BP_NPM_START_HEALTHCHECK_SCRIPT is set", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_SLIM", "true")
+				os.Setenv("BP_NPM_START_HEALTHCHECK_SCRIPT", "healthcheck")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_HEALTHCHECK_SCRIPT")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("BP_NPM_START_HEALTHCHECK_SCRIPT and BP_NPM_START_SLIM: a slim build leaves out the label that tells controllers which process runs the health check; unset BP_NPM_START_SLIM")))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+	})
+
+	context("when the package.json has a config field", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"config": {
+					"port": 8080,
+					"db": {"host": "localhost"}
+				},
+				"scripts": {
+					"start": "node server.js --port $npm_package_config_port"
+				}
+			}`), 0600)).To(Succeed())
+		})
+
+		it("exports the config to the processes as npm does", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("npm_package_config_port.default", "8080"))
+			Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("npm_package_config_db_host.default", "localhost"))
+		})
+	})
+
+	context("when the app relies on a behavior that is scheduled to change", func() {
+		var deprecations []npmstart.Deprecation
+
+		it.Before(func() {
+			deprecations = npmstart.Deprecations
+			npmstart.Deprecations = []npmstart.Deprecation{
+				{
+					ID:            "some-deprecation",
+					Description:   "some behavior",
+					Current:       "some behavior is the default",
+					Future:        "some other behavior is the default",
+					TargetVersion: "some-version",
+					ReliesOn: func(in npmstart.DeprecationInputs) bool {
+						return in.Package.Scripts.Start == "node server.js"
+					},
+				},
+				{
+					ID:            "other-deprecation",
+					Description:   "other behavior",
+					Current:       "other behavior is the default",
+					Future:        "another behavior is the default",
+					TargetVersion: "other-version",
+					ReliesOn: func(in npmstart.DeprecationInputs) bool {
+						return in.LiveReload
+					},
+				},
+			}
+
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"scripts": {
+					"start": "node server.js"
+				}
+			}`), 0600)).To(Succeed())
+		})
+
+		it.After(func() {
+			npmstart.Deprecations = deprecations
+		})
+
+		it("warns about the deprecation", func() {
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring("WARNING: some behavior, which changes in version some-version (some-deprecation) [deprecated]"))
+			Expect(buffer.String()).To(ContainSubstring("Currently some behavior is the default."))
+			Expect(buffer.String()).To(ContainSubstring("From version some-version, some other behavior is the default."))
+			Expect(buffer.String()).NotTo(ContainSubstring("(other-deprecation)"))
+		})
+
+		context("when BP_NPM_START_DISABLED_CHECKS=deprecated", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_DISABLED_CHECKS", "deprecated")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_DISABLED_CHECKS")
+			})
+
+			it("does not warn", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).NotTo(ContainSubstring("[deprecated]"))
+			})
+		})
+
+		context("when BP_NPM_START_DEPRECATIONS_FILE is set", func() {
+			var path string
+
+			it.Before(func() {
+				path = filepath.Join(workingDir, "deprecations.json")
+				os.Setenv("BP_NPM_START_DEPRECATIONS_FILE", path)
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_DEPRECATIONS_FILE")
+			})
+
+			it("writes every deprecation, marked with whether the app relies on it", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+
+				var report map[string][]map[string]interface{}
+				Expect(json.Unmarshal(content, &report)).To(Succeed())
+				Expect(report).To(HaveKey("deprecations"))
+				Expect(report["deprecations"]).To(HaveLen(2))
+
+				for i, deprecation := range report["deprecations"] {
+					Expect(deprecation).To(HaveLen(6))
+					Expect(deprecation).To(HaveKeyWithValue("id", npmstart.Deprecations[i].ID))
+					Expect(deprecation).To(HaveKeyWithValue("description", npmstart.Deprecations[i].Description))
+					Expect(deprecation).To(HaveKeyWithValue("current", npmstart.Deprecations[i].Current))
+					Expect(deprecation).To(HaveKeyWithValue("future", npmstart.Deprecations[i].Future))
+					Expect(deprecation).To(HaveKeyWithValue("target_version", npmstart.Deprecations[i].TargetVersion))
+					Expect(deprecation).To(HaveKeyWithValue("relied_on", npmstart.Deprecations[i].ID == "some-deprecation"))
+				}
+
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Writing the deprecation timeline to %s", path)))
+			})
+		})
+
+		context("when live reload is enabled", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+			})
+
+			it("warns about each deprecation the app relies on", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan:   reloadPlan("watchexec"),
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(ContainSubstring("(some-deprecation) [deprecated]"))
+				Expect(buffer.String()).To(ContainSubstring("(other-deprecation) [deprecated]"))
+			})
+		})
+	})
+
+	context("when no change of default behavior is scheduled", func() {
+		var path string
+
+		it.Before(func() {
+			path = filepath.Join(workingDir, "deprecations.json")
+			os.Setenv("BP_NPM_START_DEPRECATIONS_FILE", path)
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_DEPRECATIONS_FILE")
+		})
+
+		it("warns about nothing and writes an empty timeline", func() {
+			Expect(npmstart.Deprecations).To(BeEmpty())
+
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(MatchJSON(`{"deprecations": []}`))
+			Expect(buffer.String()).NotTo(ContainSubstring("[deprecated]"))
+		})
+	})
+
+	context("when BP_NPM_START_DESCRIPTOR is set", func() {
+		it.Before(func() {
+			os.Setenv("BP_NPM_START_DESCRIPTOR", "manifest.yml")
+
+			Expect(os.WriteFile(filepath.Join(workingDir, "manifest.yml"), []byte(`applications:
+- name: some-app
+  command: node server.js
+- name: other-app
+  command: node worker.js
+`), 0600)).To(Succeed())
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_DESCRIPTOR")
+			os.Unsetenv("BP_NPM_START_DESCRIPTOR_APP")
+		})
+
+		it("starts the app with the command of the selected application instead of the start script", func() {
+			os.Setenv("BP_NPM_START_DESCRIPTOR_APP", "other-app")
+
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "web",
+					Command: "bash",
+					Args:    []string{"-c", fmt.Sprintf("cd %s && some-prestart-command && node worker.js && some-poststart-command", filepath.Join(workingDir, "some-project-dir"))},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+
+			Expect(buffer.String()).To(ContainSubstring(`Starting the app with the command of application "other-app" in manifest.yml (BP_NPM_START_DESCRIPTOR)`))
+			Expect(buffer.String()).To(ContainSubstring(`It takes precedence over the start script "some-start-command" of package.json.`))
+		})
+	})
+
+	context("when the start command is too long to be passed inline", func() {
+		var start string
+
+		it.Before(func() {
+			start = "some-start-command"
+			for i := 0; len(start) <= npmstart.MaxInlineCommandSize; i++ {
+				start = fmt.Sprintf("VAR_%d=value %s", i, start)
+			}
+
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(fmt.Sprintf(`{
+				"scripts": {
+					"start": %q
+				}
+			}`, start)), 0600)).To(Succeed())
+		})
+
+		it("runs it from a script file in the start layer", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			path := filepath.Join(layersDir, "start", "start-command.sh")
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "web",
+					Command: "bash",
+					Args:    []string{path},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal(fmt.Sprintf("#!/usr/bin/env bash\ncd %s && %s\n", filepath.Join(workingDir, "some-project-dir"), start)))
+
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+
+			Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Writing the start command to %s", path)))
+			Expect(buffer.String()).To(ContainSubstring("more than the 102400 bytes that are passed to the shell inline."))
+		})
+
+		context("when live reload is enabled and BP_NPM_START_TRACE_WRAPPERS=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+				os.Setenv("BP_NPM_START_TRACE_WRAPPERS", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+				os.Unsetenv("BP_NPM_START_TRACE_WRAPPERS")
+			})
+
+			it("reloads the script file and logs the command after each wrapper in order", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan:   reloadPlan("watchexec"),
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				path := filepath.Join(layersDir, "start", "start-command.sh")
+				Expect(result.Launch.Processes).To(HaveLen(2))
+				Expect(result.Launch.Processes[0].Command).To(Equal("watchexec"))
+				Expect(result.Launch.Processes[0].Args[len(result.Launch.Processes[0].Args)-2:]).To(Equal([]string{"bash", path}))
+				Expect(result.Launch.Processes[1]).To(Equal(packit.Process{
+					Type:    "no-reload",
+					Command: "bash",
+					Args:    []string{path},
+					Direct:  true,
+				}))
+
+				Expect(buffer.String()).To(ContainSubstring("Wrapping the start command (BP_NPM_START_TRACE_WRAPPERS=true)"))
+				Expect(buffer.String()).To(MatchRegexp(`(?s)start: bash -c .*script-file: bash %s\n.*command-hook: not enabled\n.*live-reload: watchexec --restart .* -- bash %s\n`, regexp.QuoteMeta(path), regexp.QuoteMeta(path)))
+			})
+		})
+	})
+
+	context("when the app has a revision or a version", func() {
+		const commit = "0123456789abcdef0123456789abcdef01234567"
+
+		var buildContext packit.BuildContext
+
+		it.Before(func() {
+			buildContext = packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			}
+		})
+
+		// writeGit writes the given files, relative to the .git directory,
+		// into the working directory.
+		writeGit := func(files map[string]string) {
+			for name, content := range files {
+				path := filepath.Join(workingDir, ".git", filepath.FromSlash(name))
+				Expect(os.MkdirAll(filepath.Dir(path), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+			}
+		}
+
+		context("when BP_NPM_START_REVISION is set", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_REVISION", "v1.2.3-rc.1")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_REVISION")
+			})
+
+			it("takes precedence over the git HEAD", func() {
+				writeGit(map[string]string{"HEAD": commit + "\n"})
+
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("APP_REVISION.default", "v1.2.3-rc.1"))
+				Expect(result.Launch.Labels).To(Equal(map[string]string{
+					npmstart.RevisionLabel: "v1.2.3-rc.1",
+				}))
+				Expect(buffer.String()).To(ContainSubstring("Setting the revision and version of the app"))
+				Expect(buffer.String()).To(ContainSubstring("APP_REVISION: v1.2.3-rc.1, from BP_NPM_START_REVISION"))
+			})
+		})
+
+		context("when the working directory is a git repository", func() {
+			it("sets the short commit of the branch that HEAD refers to", func() {
+				writeGit(map[string]string{
+					"HEAD":            "ref: refs/heads/main\n",
+					"refs/heads/main": commit + "\n",
+				})
+
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("APP_REVISION.default", "0123456"))
+				Expect(result.Launch.Labels).To(HaveKeyWithValue(npmstart.RevisionLabel, "0123456"))
+				Expect(buffer.String()).To(ContainSubstring("APP_REVISION: 0123456, from the git HEAD of the app"))
+			})
+
+			it("reads branches that git gc has packed", func() {
+				writeGit(map[string]string{
+					"HEAD": "ref: refs/heads/main\n",
+					"packed-refs": strings.Join([]string{
+						"# pack-refs with: peeled fully-peeled sorted",
+						"fedcba9876543210fedcba9876543210fedcba98 refs/heads/feature",
+						commit + " refs/heads/main",
+						"^fedcba9876543210fedcba9876543210fedcba98",
+					}, "\n"),
+				})
+
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("APP_REVISION.default", "0123456"))
+			})
+
+			it("reads a detached HEAD", func() {
+				writeGit(map[string]string{"HEAD": commit + "\n"})
+
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("APP_REVISION.default", "0123456"))
+			})
+
+			it("follows a .git file to the repository of a linked worktree", func() {
+				repository, err := os.MkdirTemp("", "repository")
+				Expect(err).NotTo(HaveOccurred())
+				defer os.RemoveAll(repository)
+
+				worktree := filepath.Join(repository, ".git", "worktrees", "some-worktree")
+				Expect(os.MkdirAll(worktree, os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(worktree, "HEAD"), []byte("ref: refs/heads/main\n"), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(worktree, "commondir"), []byte("../..\n"), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(repository, ".git", "packed-refs"), []byte(commit+" refs/heads/main\n"), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, ".git"), []byte("gitdir: "+worktree+"\n"), 0600)).To(Succeed())
+
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("APP_REVISION.default", "0123456"))
+			})
+
+			context("when HEAD refers to a branch without commits", func() {
+				it("warns and builds without a revision", func() {
+					writeGit(map[string]string{"HEAD": "ref: refs/heads/main\n"})
+
+					result, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(launchEnv(result.Layers[0])).NotTo(HaveKey("APP_REVISION.default"))
+					Expect(result.Launch.Labels).To(BeEmpty())
+					Expect(buffer.String()).To(ContainSubstring("WARNING: failed to read the git revision of " + workingDir))
+					Expect(buffer.String()).To(ContainSubstring("HEAD refers to refs/heads/main, which has no commits"))
+				})
+			})
+		})
+
+		context("when package.json has a version", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{"version": "2.0.1", "scripts": {"start": "some-start-command"}}`), 0600)).To(Succeed())
+			})
+
+			it("sets the version without a revision", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("APP_VERSION.default", "2.0.1"))
+				Expect(launchEnv(result.Layers[0])).NotTo(HaveKey("APP_REVISION.default"))
+				Expect(result.Launch.Labels).To(Equal(map[string]string{
+					npmstart.VersionLabel: "2.0.1",
+				}))
+				Expect(buffer.String()).To(ContainSubstring("APP_VERSION: 2.0.1, from the version field of package.json"))
+			})
+		})
+
+		context("when there is neither a revision nor a version", func() {
+			it("sets neither and does not log about them", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(launchEnv(result.Layers[0])).NotTo(HaveKey("APP_REVISION.default"))
+				Expect(launchEnv(result.Layers[0])).NotTo(HaveKey("APP_VERSION.default"))
+				Expect(result.Launch.Labels).To(BeEmpty())
+				Expect(buffer.String()).NotTo(ContainSubstring("revision"))
+			})
+		})
+	})
+
+	context("when BP_NPM_START_HEALTHCHECK_SCRIPT is set", func() {
+		var writeScripts func(scripts string)
+
+		it.Before(func() {
+			writeScripts = func(scripts string) {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{"scripts": {"start": "some-start-command", `+scripts+`}}`), 0600)).To(Succeed())
+			}
+
+			os.Setenv("BP_NPM_START_HEALTHCHECK_SCRIPT", "healthcheck")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_HEALTHCHECK_SCRIPT")
+		})
+
+		it("adds a non-default health process that runs the script without npm", func() {
+			writeScripts(`"prehealthcheck": "some-prehealthcheck-command", "healthcheck": "node healthcheck.js"`)
+
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(HaveLen(2))
+			Expect(result.Launch.Processes[0].Type).To(Equal("web"))
+			Expect(result.Launch.Processes[1]).To(Equal(packit.Process{
+				Type:    "health",
+				Command: "bash",
+				Args: []string{
+					"-c",
+					fmt.Sprintf("cd %s && some-prehealthcheck-command && node healthcheck.js", filepath.Join(workingDir, "some-project-dir")),
+				},
+				Direct: true,
+			}))
+			Expect(result.Launch.Labels).To(Equal(map[string]string{
+				npmstart.HealthcheckLabel: "health",
+			}))
+			Expect(buffer.String()).NotTo(ContainSubstring("[healthcheck-npm]"))
+		})
+
+		context("when the script reads variables that only npm sets", func() {
+			it("runs the script with npm and warns about the latency of probes", func() {
+				writeScripts(`"healthcheck": "curl -f localhost:$npm_package_config_port/health", "posthealthcheck": "some-posthealthcheck-command"`)
+
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes[1]).To(Equal(packit.Process{
+					Type:    "health",
+					Command: "npm",
+					Args:    []string{"--prefix", filepath.Join(workingDir, "some-project-dir"), "run", "healthcheck"},
+					Direct:  true,
+				}))
+				Expect(result.Launch.Labels).To(HaveKeyWithValue(npmstart.HealthcheckLabel, "health"))
+
+				Expect(buffer.String()).To(ContainSubstring("WARNING: the health process runs the healthcheck script with npm, as the healthcheck script reads variables that only npm sets [healthcheck-npm]"))
+				Expect(buffer.String()).To(ContainSubstring("Starting npm adds several hundred milliseconds to every probe that runs the process."))
+			})
+		})
+
+		context("when the script reads config variables that the build exports", func() {
+			it("runs the script without npm", func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+					"config": {"port": 8080},
+					"scripts": {
+						"start": "some-start-command",
+						"healthcheck": "curl -f localhost:${npm_package_config_port}/health"
+					}
+				}`), 0600)).To(Succeed())
+
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(launchEnv(result.Layers[0])).To(HaveKeyWithValue("npm_package_config_port.default", "8080"))
+				Expect(result.Launch.Processes[1]).To(Equal(packit.Process{
+					Type:    "health",
+					Command: "bash",
+					Args: []string{
+						"-c",
+						fmt.Sprintf("cd %s && curl -f localhost:${npm_package_config_port}/health", filepath.Join(workingDir, "some-project-dir")),
+					},
+					Direct: true,
+				}))
+				Expect(buffer.String()).NotTo(ContainSubstring("[healthcheck-npm]"))
+			})
+
+			it("still runs the script with npm when it also reads other npm variables", func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+					"config": {"port": 8080},
+					"scripts": {
+						"start": "some-start-command",
+						"healthcheck": "curl -f localhost:$npm_package_config_port/$npm_package_config_path"
+					}
+				}`), 0600)).To(Succeed())
+
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes[1].Command).To(Equal("npm"))
+				Expect(buffer.String()).To(ContainSubstring("[healthcheck-npm]"))
+			})
+		})
+
+		context("when the script cannot be joined into a single command", func() {
+			it("runs the script with npm", func() {
+				writeScripts(`"healthcheck": "some-healthcheck-command &&"`)
+
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes[1].Command).To(Equal("npm"))
+				Expect(buffer.String()).To(ContainSubstring(`as the healthcheck script "some-healthcheck-command &&" has a dangling "&&" [healthcheck-npm]`))
+			})
+		})
+
+		context("when BP_NPM_START_DISABLED_CHECKS lists healthcheck-npm", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_DISABLED_CHECKS", "healthcheck-npm")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_DISABLED_CHECKS")
+			})
+
+			it("does not warn", func() {
+				writeScripts(`"healthcheck": "echo $npm_package_name"`)
+
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).NotTo(ContainSubstring("[healthcheck-npm]"))
+			})
+		})
+	})
+
+	context("when BP_NPM_START_JOBS is set", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"scripts": {
+					"prestart": "some-prestart-command",
+					"start": "some-start-command",
+					"migrate": "some-migrate-command",
+					"seed": "some-seed-command"
+				}
+			}`), 0600)).To(Succeed())
+
+			os.Setenv("BP_NPM_START_JOBS", "migrate, seed,migrate")
+			os.Setenv("BP_NPM_START_PROCESS_ENV", "seed:SEED_SIZE=10")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_JOBS")
+			os.Unsetenv("BP_NPM_START_PROCESS_ENV")
+		})
+
+		it("adds a non-default process running each script once", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			projectPath := filepath.Join(workingDir, "some-project-dir")
+			Expect(result.Launch.Processes).To(HaveLen(3))
+			Expect(result.Launch.Processes[0].Type).To(Equal("web"))
+			Expect(result.Launch.Processes[1:]).To(Equal([]packit.Process{
+				{
+					Type:    "migrate",
+					Command: "npm",
+					Args:    []string{"--prefix", projectPath, "run", "migrate"},
+					Direct:  true,
+				},
+				{
+					Type:    "seed",
+					Command: "npm",
+					Args:    []string{"--prefix", projectPath, "run", "seed"},
+					Direct:  true,
+				},
+			}))
+
+			Expect(processLaunchEnv(result.Layers[0])).To(Equal(map[string]packit.Environment{
+				"migrate": {
+					"BPL_NPM_START_JOB.override": "true",
+				},
+				"seed": {
+					"BPL_NPM_START_JOB.override": "true",
+					"SEED_SIZE.override":         "10",
+				},
+			}))
+		})
+
+		context("when the project path is the working directory", func() {
+			it.Before(func() {
+				pathParser.GetCall.Returns.ProjectPath = workingDir
+				Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{
+					"scripts": {
+						"start": "some-start-command",
+						"migrate": "some-migrate-command",
+						"seed": "some-seed-command"
+					}
+				}`), 0600)).To(Succeed())
+			})
+
+			it("runs the script without --prefix", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes[1].Args).To(Equal([]string{"run", "migrate"}))
+			})
+		})
+
+		context("when BP_NPM_START_SILENT=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_SILENT", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_SILENT")
+			})
+
+			it("runs the scripts without the npm banner", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				projectPath := filepath.Join(workingDir, "some-project-dir")
+				Expect(result.Launch.Processes[1].Args).To(Equal([]string{"--prefix", projectPath, "run", "migrate", "--silent"}))
+				Expect(result.Launch.Processes[2].Args).To(Equal([]string{"--prefix", projectPath, "run", "seed", "--silent"}))
+				Expect(result.Launch.Processes[0].Args).To(Equal([]string{"-c", fmt.Sprintf("cd %s && some-prestart-command && some-start-command", projectPath)}))
+			})
+		})
+	})
+
+	context("when node_modules is a symlink into the layers of another buildpack", func() {
+		var (
+			layersRoot string
+			target     string
+			buildApp   func() error
+		)
+
+		it.Before(func() {
+			layersRoot = layersDir
+			layersDir = filepath.Join(layersRoot, "paketo-buildpacks_npm-start")
+			Expect(os.Mkdir(layersDir, os.ModePerm)).To(Succeed())
+
+			target = filepath.Join(layersRoot, "paketo-buildpacks_npm-install", "launch-modules", "node_modules")
+			Expect(os.Symlink(target, filepath.Join(workingDir, "some-project-dir", "node_modules"))).To(Succeed())
+
+			buildApp = func() error {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				return err
+			}
+		})
+
+		it.After(func() {
+			layersDir = layersRoot
+		})
+
+		context("when the target exists", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(target, os.ModePerm)).To(Succeed())
+			})
+
+			it("leaves the symlink in place", func() {
+				Expect(buildApp()).To(Succeed())
+
+				link, err := os.Readlink(filepath.Join(workingDir, "some-project-dir", "node_modules"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(target))
+				Expect(buffer.String()).NotTo(ContainSubstring("symlink"))
+			})
+		})
+
+		context("when the target no longer exists", func() {
+			it("removes the symlink and warns", func() {
+				Expect(buildApp()).To(Succeed())
+
+				_, err := os.Lstat(filepath.Join(workingDir, "some-project-dir", "node_modules"))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("WARNING: removed node_modules, a symlink to %s, which does not exist in this build", target)))
+				Expect(buffer.String()).To(ContainSubstring("The paketo-buildpacks/npm-install buildpack created it in a previous build but is not part of this build."))
+			})
+
+			context("when BP_NPM_START_STRICT is true", func() {
+				it.Before(func() {
+					os.Setenv("BP_NPM_START_STRICT", "true")
+				})
+
+				it.After(func() {
+					os.Unsetenv("BP_NPM_START_STRICT")
+				})
+
+				it("fails the build and leaves the symlink in place", func() {
+					err := buildApp()
+					Expect(err).To(MatchError(fmt.Sprintf("node_modules in %s is a symlink to %s, which the paketo-buildpacks/npm-install buildpack created in a previous build but is not part of this build (BP_NPM_START_STRICT=true)", filepath.Join(workingDir, "some-project-dir"), target)))
+
+					_, err = os.Lstat(filepath.Join(workingDir, "some-project-dir", "node_modules"))
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
+
+		context("when the symlink points outside the layers", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(workingDir, "some-project-dir", "node_modules"))).To(Succeed())
+				Expect(os.Symlink("../missing-modules", filepath.Join(workingDir, "some-project-dir", "node_modules"))).To(Succeed())
+			})
+
+			it("leaves the symlink in place", func() {
+				Expect(buildApp()).To(Succeed())
+
+				_, err := os.Lstat(filepath.Join(workingDir, "some-project-dir", "node_modules"))
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	context("when the start script runs a command that is not installed", func() {
+		var buildLog func(start string) string
+
+		it.Before(func() {
+			buildLog = func(start string) string {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(fmt.Sprintf(`{
+					"scripts": {
+						"start": %q
+					}
+				}`, start)), 0600)).To(Succeed())
+
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				return buffer.String()
+			}
+		})
+
+		context("and it is a well-known global npm package", func() {
+			it("warns that it looks like a global npm package", func() {
+				output := buildLog("serve -s build")
+				Expect(output).To(ContainSubstring(`WARNING: the start script runs "serve", which looks like a global npm package; add it to dependencies or use npx`))
+				Expect(output).To(ContainSubstring(`Run "npm install --save serve" to add it to the dependencies in package.json.`))
+			})
+		})
+
+		context("and it is some other command", func() {
+			it("warns that it was not found", func() {
+				output := buildLog("NODE_ENV=production some-unknown-command --flag")
+				Expect(output).To(ContainSubstring(`WARNING: the start script runs "some-unknown-command", which is not installed in node_modules/.bin`))
+				Expect(output).NotTo(ContainSubstring("global npm package"))
+			})
+		})
+
+		context("and it is installed in node_modules/.bin", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin", "serve"), nil, 0755)).To(Succeed())
+			})
+
+			it("does not warn", func() {
+				Expect(buildLog("serve -s build && cd build")).NotTo(ContainSubstring("WARNING"))
+			})
+		})
+
+		context("and it is only on the PATH of the build", func() {
+			var path string
+
+			it.Before(func() {
+				dir := t.TempDir()
+				Expect(os.WriteFile(filepath.Join(dir, "some-build-command"), nil, 0755)).To(Succeed())
+
+				path = os.Getenv("PATH")
+				os.Setenv("PATH", dir)
+			})
+
+			it.After(func() {
+				os.Setenv("PATH", path)
+			})
+
+			it("warns that it is not installed", func() {
+				output := buildLog("some-build-command")
+				Expect(output).To(ContainSubstring(`WARNING: the start script runs "some-build-command", which is not installed in node_modules/.bin [missing-command]`))
+			})
+		})
+
+		context("and it is provided by the runtime while node is not on the PATH of the build", func() {
+			var path string
+
+			it.Before(func() {
+				path = os.Getenv("PATH")
+				os.Setenv("PATH", t.TempDir())
+			})
+
+			it.After(func() {
+				os.Setenv("PATH", path)
+			})
+
+			it("does not warn", func() {
+				output := buildLog("npm run migrate && NODE_ENV=production node server.js && sh -c true && bash -c true")
+				Expect(output).NotTo(ContainSubstring("WARNING"))
+			})
+		})
+
+		context("and it is run with npx", func() {
+			it("does not warn that it is missing", func() {
+				Expect(buildLog("npx serve -s build")).NotTo(ContainSubstring("not installed in node_modules/.bin"))
+			})
+		})
+
+		context("and BP_NPM_START_SUPPRESS_WARNINGS=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_SUPPRESS_WARNINGS", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_SUPPRESS_WARNINGS")
+			})
+
+			it("does not warn", func() {
+				Expect(buildLog("serve -s build")).NotTo(ContainSubstring("WARNING"))
+			})
+		})
+
+		context("and BP_NPM_START_DISABLED_CHECKS lists one of the checks", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_DISABLED_CHECKS", "global-bin")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_DISABLED_CHECKS")
+			})
+
+			it("names each check in its warning and skips the disabled one", func() {
+				output := buildLog("serve -s build && some-unknown-command")
+				Expect(output).NotTo(ContainSubstring("global npm package"))
+				Expect(output).To(ContainSubstring(`WARNING: the start script runs "some-unknown-command", which is not installed in node_modules/.bin [missing-command]`))
+			})
+		})
+
+		context("and BP_NPM_START_DISABLED_CHECKS lists an unknown check", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_DISABLED_CHECKS", "missing-command, global-bins")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_DISABLED_CHECKS")
+			})
+
+			it("warns about the unknown check and applies the others", func() {
+				output := buildLog("serve -s build && some-unknown-command")
+				Expect(output).To(ContainSubstring("WARNING: BP_NPM_START_DISABLED_CHECKS names unknown checks: global-bins"))
+				Expect(output).To(ContainSubstring("The known checks are build-step, deprecated, dev-deps, dev-server, global-bin, healthcheck-npm, missing-command, missing-entrypoint, missing-node-modules, npx-network."))
+				Expect(output).To(ContainSubstring(`WARNING: the start script runs "serve", which looks like a global npm package; add it to dependencies or use npx [global-bin]`))
+				Expect(output).NotTo(ContainSubstring("not installed in node_modules/.bin"))
+			})
+		})
+	})
+
+	context("when the start script only runs a package with npx", func() {
+		var buildWith func(start string) (packit.BuildResult, error)
+
+		it.Before(func() {
+			buildWith = func(start string) (packit.BuildResult, error) {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(fmt.Sprintf(`{
+					"scripts": {
+						"start": %q
+					}
+				}`, start)), 0600)).To(Succeed())
+
+				return build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+			}
+		})
+
+		context("and the package is installed in node_modules", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin", "serve"), nil, 0755)).To(Succeed())
+			})
+
+			it("runs the installed executable instead of npx", func() {
+				result, err := buildWith("npx --yes serve -s build")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes[0].Args).To(Equal([]string{
+					"-c",
+					fmt.Sprintf("cd %s/some-project-dir && node_modules/.bin/serve -s build", workingDir),
+				}))
+				Expect(buffer.String()).To(ContainSubstring("Running serve from node_modules instead of through npx"))
+				Expect(buffer.String()).To(ContainSubstring(`Rewrote the start script "npx --yes serve -s build" to "node_modules/.bin/serve -s build"`))
+				Expect(buffer.String()).NotTo(ContainSubstring("WARNING"))
+			})
+
+			context("when the version is pinned", func() {
+				it("leaves the start script to npx", func() {
+					result, err := buildWith("npx serve@14 -s build")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(result.Launch.Processes[0].Args).To(Equal([]string{
+						"-c",
+						fmt.Sprintf("cd %s/some-project-dir && npx serve@14 -s build", workingDir),
+					}))
+					Expect(buffer.String()).To(ContainSubstring(`Leaving the start script "npx serve@14 -s build" to npx`))
+					Expect(buffer.String()).To(ContainSubstring("It pins serve@14; npx runs the installed node_modules/.bin/serve only if it satisfies that version."))
+					Expect(buffer.String()).NotTo(ContainSubstring("WARNING"))
+				})
+			})
+		})
+
+		context("and the package is not installed", func() {
+			it("warns that it is installed from the network at launch", func() {
+				result, err := buildWith("npx serve -s build")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes[0].Args).To(Equal([]string{
+					"-c",
+					fmt.Sprintf("cd %s/some-project-dir && npx serve -s build", workingDir),
+				}))
+				Expect(buffer.String()).To(ContainSubstring(`WARNING: the start script "npx serve -s build" installs serve from the network at launch, as it is not in node_modules`))
+			})
+
+			context("when BP_NPM_START_STRICT=true", func() {
+				it.Before(func() {
+					os.Setenv("BP_NPM_START_STRICT", "true")
+				})
+
+				it.After(func() {
+					os.Unsetenv("BP_NPM_START_STRICT")
+				})
+
+				it("returns an error", func() {
+					_, err := buildWith("npx serve@14 -s build")
+					Expect(err).To(MatchError(`start script "npx serve@14 -s build" installs serve from the network at launch, as it is not in node_modules (BP_NPM_START_STRICT=true)`))
+				})
+			})
+		})
+	})
+
+	context("when the package.json contains Latin-1 bytes", func() {
+		it.Before(func() {
+			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte("{\"scripts\": {\"start\": \"echo caf\xe9 cr\xe8me\"}}"), 0600)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("transcodes them and warns with their offsets", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes[0].Args).To(ContainElement(ContainSubstring("echo café crème")))
+			Expect(buffer.String()).To(ContainSubstring("WARNING: package.json is not valid UTF-8"))
+			Expect(buffer.String()).To(ContainSubstring("The bytes at offsets 31, 35 were read as Latin-1 characters."))
+		})
+	})
+
+	context("when the start script only runs a build tool", func() {
+		it.Before(func() {
+			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"scripts": {
+					"start": "webpack --mode production"
+				}
+			}`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("warns that the start script appears to be a build step", func() {
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring(`WARNING: the start script "webpack --mode production" appears to be a build step rather than a server`))
+			Expect(buffer.String()).To(ContainSubstring("node-run-script buildpack"))
+		})
+
+		context("when BP_NPM_START_STRICT=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_STRICT", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_STRICT")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(`start script "webpack --mode production" appears to be a build step rather than a server (BP_NPM_START_STRICT=true)`))
+			})
+		})
+	})
+
+	context("when the start script at the workspace root runs a monorepo task runner", func() {
+		var buildProcesses func() []packit.Process
+
+		it.Before(func() {
+			buildProcesses = func() []packit.Process {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(launchEnv(result.Layers[0])["PATH.append"]).To(Equal(filepath.Join(workingDir, "node_modules", ".bin")))
+
+				return result.Launch.Processes
+			}
+		})
+
+		context("and the runner is turbo", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{
+					"scripts": {
+						"start": "turbo run start"
+					}
+				}`), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "turbo.json"), []byte(`{}`), 0600)).To(Succeed())
+			})
+
+			it("runs the start script at the workspace root without resolving the project path", func() {
+				Expect(buildProcesses()[0].Args).To(Equal([]string{"-c", "turbo run start"}))
+				Expect(pathParser.GetCall.CallCount).To(Equal(0))
+				Expect(buffer.String()).To(ContainSubstring("Starting the workspace with turbo"))
+			})
+
+			context("and BP_NPM_START_FILTER is set", func() {
+				it.Before(func() {
+					os.Setenv("BP_NPM_START_FILTER", "./apps/*")
+				})
+
+				it.After(func() {
+					os.Unsetenv("BP_NPM_START_FILTER")
+				})
+
+				it("passes the filter to turbo", func() {
+					Expect(buildProcesses()[0].Args).To(Equal([]string{"-c", "turbo run start --filter='./apps/*'"}))
+				})
+
+				context("and the start script already has a filter", func() {
+					it.Before(func() {
+						Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{
+							"scripts": {
+								"start": "turbo run start --filter=web"
+							}
+						}`), 0600)).To(Succeed())
+					})
+
+					it("keeps the filter of the start script", func() {
+						Expect(buildProcesses()[0].Args).To(Equal([]string{"-c", "turbo run start --filter=web"}))
+					})
+				})
+			})
+		})
+
+		context("and the runner is nx", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{
+					"scripts": {
+						"start": "nx run-many --target=start"
+					}
+				}`), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "nx.json"), []byte(`{}`), 0600)).To(Succeed())
+			})
+
+			it("runs the start script at the workspace root without resolving the project path", func() {
+				Expect(buildProcesses()[0].Args).To(Equal([]string{"-c", "nx run-many --target=start"}))
+				Expect(pathParser.GetCall.CallCount).To(Equal(0))
+			})
+
+			context("and BP_NPM_START_FILTER is set", func() {
+				it.Before(func() {
+					os.Setenv("BP_NPM_START_FILTER", "web")
+				})
+
+				it.After(func() {
+					os.Unsetenv("BP_NPM_START_FILTER")
+				})
+
+				it("passes the filter to nx", func() {
+					Expect(buildProcesses()[0].Args).To(Equal([]string{"-c", "nx run-many --target=start --projects=web"}))
+				})
+			})
+		})
+
+		context("and the runner config file is missing", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{
+					"scripts": {
+						"start": "turbo run start"
+					}
+				}`), 0600)).To(Succeed())
+			})
+
+			it("resolves the project path", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pathParser.GetCall.CallCount).To(Equal(1))
+				Expect(result.Launch.Processes[0].Args).To(Equal([]string{
+					"-c",
+					fmt.Sprintf("cd %s/some-project-dir && some-prestart-command && some-start-command && some-poststart-command", workingDir),
+				}))
+			})
+		})
+	})
+
+	context("when BP_NPM_START_COMMAND_HOOK is set", func() {
+		var input npmstart.HookCommand
+
+		it.Before(func() {
+			os.Setenv("BP_NPM_START_COMMAND_HOOK", "hooks/command.js")
+
+			node.ExecuteCall.Stub = func(execution pexec.Execution) error {
+				content, err := os.ReadFile(execution.Args[1])
+				if err != nil {
+					return err
+				}
+
+				err = json.Unmarshal(content, &input)
+				if err != nil {
+					return err
+				}
+
+				_, err = fmt.Fprint(execution.Stdout, `{"command": "some-launcher", "args": ["--tenant", "some-tenant", "bash", "-c", "some-start-command"]}`)
+				return err
+			}
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_COMMAND_HOOK")
+		})
+
+		it("runs the hook and uses the command it returns", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(node.ExecuteCall.Receives.Execution.Args[0]).To(Equal(filepath.Join(workingDir, "hooks", "command.js")))
+			Expect(node.ExecuteCall.Receives.Execution.Dir).To(Equal(filepath.Join(workingDir, "some-project-dir")))
+			Expect(input).To(Equal(npmstart.HookCommand{
+				Command: "bash",
+				Args: []string{
+					"-c",
+					fmt.Sprintf("cd %s && some-prestart-command && some-start-command && some-poststart-command", filepath.Join(workingDir, "some-project-dir")),
+				},
+			}))
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "web",
+					Command: "some-launcher",
+					Args:    []string{"--tenant", "some-tenant", "bash", "-c", "some-start-command"},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+
+			Expect(buffer.String()).To(ContainSubstring("Applying command hook command.js"))
+			Expect(buffer.String()).To(ContainSubstring("- bash -c cd "))
+			Expect(buffer.String()).To(ContainSubstring("+ some-launcher --tenant some-tenant bash -c some-start-command"))
+		})
+	})
+
+	context("when BP_NPM_START_NO_DEFAULT_PROCESS=true in the build environment", func() {
+		it.Before(func() {
+			os.Setenv("BP_NPM_START_NO_DEFAULT_PROCESS", "true")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_NO_DEFAULT_PROCESS")
+		})
+
+		it("emits the start command as a non-default app process", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "app",
+					Command: "bash",
+					Args: []string{
+						"-c",
+						fmt.Sprintf("cd %s/some-project-dir && some-prestart-command && some-start-command && some-poststart-command", workingDir),
+					},
+					Direct: true,
+				},
+			}))
+		})
+
+		context("when BP_LIVE_RELOAD_ENABLED=true in the build environment", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+				os.Setenv("BP_NPM_START_PROCESS_ENV", "app:NODE_ENV=development")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+				os.Unsetenv("BP_NPM_START_PROCESS_ENV")
+			})
+
+			it("emits no default process", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan:   reloadPlan("watchexec"),
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes).To(HaveLen(2))
+				Expect(result.Launch.Processes[0].Type).To(Equal("app"))
+				Expect(result.Launch.Processes[0].Command).To(Equal("watchexec"))
+				Expect(result.Launch.Processes[1].Type).To(Equal("no-reload"))
+				for _, process := range result.Launch.Processes {
+					Expect(process.Default).To(BeFalse())
+				}
+
+				Expect(processLaunchEnv(result.Layers[0])).To(HaveKey("app"))
+			})
+		})
+	})
+
+	context("when BP_NPM_START_LABELS is set", func() {
+		it.Before(func() {
+			os.Setenv("BP_NPM_START_LABELS", `autoscaling.knative.dev/target=10,some-list=a\,b`)
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_LABELS")
+			os.Unsetenv("BP_NPM_START_WORKLOAD_TYPE")
+		})
+
+		it("passes the labels through to the image", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Labels).To(Equal(map[string]string{
+				"autoscaling.knative.dev/target": "10",
+				"some-list":                      "a,b",
+			}))
+		})
+
+		context("when the image is a worker", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_WORKLOAD_TYPE", "worker")
+			})
+
+			it("adds them to the workload label", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Labels).To(Equal(map[string]string{
+					npmstart.WorkloadLabel:           "worker",
+					"autoscaling.knative.dev/target": "10",
+					"some-list":                      "a,b",
+				}))
+			})
+		})
+	})
+
+	context("when BP_NPM_START_WORKLOAD_TYPE=worker in the build environment", func() {
+		it.Before(func() {
+			os.Setenv("BP_NPM_START_WORKLOAD_TYPE", "worker")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_WORKLOAD_TYPE")
+		})
+
+		it("names the default process worker and labels the image", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch).To(Equal(packit.LaunchMetadata{
+				Processes: []packit.Process{
+					{
+						Type:    "worker",
+						Command: "bash",
+						Args: []string{
+							"-c",
+							fmt.Sprintf("cd %s/some-project-dir && some-prestart-command && some-start-command && some-poststart-command", workingDir),
+						},
+						Default: true,
+						Direct:  true,
+					},
+				},
+				Labels: map[string]string{
+					"io.paketo.npm-start.workload": "worker",
+				},
+			}))
+		})
+
+		context("and a secret in the build environment appears in a label", func() {
+			it.Before(func() {
+				os.Setenv("SOME_TOKEN", "worker")
+			})
+
+			it.After(func() {
+				os.Unsetenv("SOME_TOKEN")
+			})
+
+			it("redacts it", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Labels).To(Equal(map[string]string{
+					"io.paketo.npm-start.workload": "[redacted]",
+				}))
+			})
+		})
+
+		context("and BP_LIVE_RELOAD_ENABLED=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+			})
+
+			it("names the reloadable process worker", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan:   reloadPlan("watchexec"),
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes).To(HaveLen(2))
+				Expect(result.Launch.Processes[0].Type).To(Equal("worker"))
+				Expect(result.Launch.Processes[0].Command).To(Equal("watchexec"))
+				Expect(result.Launch.Processes[0].Default).To(BeTrue())
+				Expect(result.Launch.Processes[1].Type).To(Equal("no-reload"))
+			})
+		})
+
+		context("and BP_NPM_START_FORWARD_PORT=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_FORWARD_PORT", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_FORWARD_PORT")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("BP_NPM_START_WORKLOAD_TYPE=worker and BP_NPM_START_FORWARD_PORT: a worker does not listen on $PORT; unset BP_NPM_START_FORWARD_PORT")))
+			})
+		})
+	})
+
+	context("when BP_NPM_START_EXPLAIN=true in the build environment", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "maintenance-server"), []byte("maintenance-server-contents"), 0755)).To(Succeed())
+
+			os.Setenv("BP_NPM_START_EXPLAIN", "true")
+			os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+			os.Setenv("BP_NPM_START_MAINTENANCE", "true")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_EXPLAIN")
+			os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+			os.Unsetenv("BP_NPM_START_MAINTENANCE")
+		})
+
+		it("explains every process and combination of options", func() {
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan:   reloadPlan("watchexec"),
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(HaveSuffix(`  Explaining the launch processes
+    web: runs the package.json start script, restarted by watchexec when files change (BP_LIVE_RELOAD_ENABLED=true)
+    no-reload: runs the package.json start script without live reload
+    maintenance: serves a 503 page on $PORT (BP_NPM_START_MAINTENANCE=true)
+
+  Explaining the combined build options
+    BP_LIVE_RELOAD_ENABLED with BP_NPM_START_MAINTENANCE: the maintenance process is not wrapped by the reloader
+
+`))
+		})
+	})
+
+	context("when BP_NPM_START_MAINTENANCE=true in the build environment", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "maintenance-server"), []byte("maintenance-server-contents"), 0755)).To(Succeed())
+
+			os.Setenv("BP_NPM_START_MAINTENANCE", "true")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_MAINTENANCE")
+		})
+
+		it("adds a non-default maintenance process backed by the maintenance server", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "web",
+					Command: "bash",
+					Args: []string{
+						"-c",
+						fmt.Sprintf("cd %s/some-project-dir && some-prestart-command && some-start-command && some-poststart-command", workingDir),
+					},
+					Default: true,
+					Direct:  true,
+				},
+				{
+					Type:    "maintenance",
+					Command: filepath.Join(layersDir, "maintenance", "bin", "maintenance-server"),
+					Direct:  true,
+				},
+			}))
+
+			Expect(result.Layers).To(HaveLen(3))
+			layer := result.Layers[1]
+			Expect(layer.Name).To(Equal("maintenance"))
+			Expect(layer.Path).To(Equal(filepath.Join(layersDir, "maintenance")))
+			Expect(layer.Launch).To(BeTrue())
+			Expect(layer.Build).To(BeFalse())
+			Expect(layer.Cache).To(BeFalse())
+
+			content, err := os.ReadFile(filepath.Join(layersDir, "maintenance", "bin", "maintenance-server"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("maintenance-server-contents"))
+
+			info, err := os.Stat(filepath.Join(layersDir, "maintenance", "bin", "maintenance-server"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0755)))
+		})
+
+		context("when BP_NPM_START_MAINTENANCE_PAGE is set", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "maintenance.html"), []byte("<p>down</p>"), 0600)).To(Succeed())
+				os.Setenv("BP_NPM_START_MAINTENANCE_PAGE", "maintenance.html")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_MAINTENANCE_PAGE")
+			})
+
+			it("serves the given page", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes).To(ContainElement(packit.Process{
+					Type:    "maintenance",
+					Command: filepath.Join(layersDir, "maintenance", "bin", "maintenance-server"),
+					Args:    []string{"--page", filepath.Join(workingDir, "maintenance.html")},
+					Direct:  true,
+				}))
+			})
+		})
+	})
+
+	context("when the same app is built twice", func() {
+		type entry struct {
+			Mode    os.FileMode
+			ModTime time.Time
+			Content string
+		}
+
+		var (
+			otherLayersDir string
+			snapshot       func(root string) map[string]entry
+		)
+
+		it.Before(func() {
+			var err error
+			otherLayersDir, err = os.MkdirTemp("", "layers")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "maintenance-server"), []byte("maintenance-server-contents"), 0755)).To(Succeed())
+
+			os.Setenv("BP_NPM_START_MAINTENANCE", "true")
+
+			// A start command this long is written to a script in the start
+			// layer.
+			start := "some-start-command"
+			for i := 0; len(start) <= npmstart.MaxInlineCommandSize; i++ {
+				start = fmt.Sprintf("VAR_%d=value %s", i, start)
+			}
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(fmt.Sprintf(`{
+				"scripts": {
+					"start": %q
+				}
+			}`, start)), 0600)).To(Succeed())
+
+			snapshot = func(root string) map[string]entry {
+				entries := map[string]entry{}
+				Expect(filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+					if err != nil {
+						return err
+					}
+
+					rel, err := filepath.Rel(root, path)
+					if err != nil {
+						return err
+					}
+
+					if rel == "." {
+						return nil
+					}
+
+					e := entry{Mode: info.Mode(), ModTime: info.ModTime().UTC()}
+					if info.Mode().IsRegular() {
+						content, err := os.ReadFile(path)
+						if err != nil {
+							return err
+						}
+						e.Content = string(content)
+					}
+					entries[rel] = e
+
+					return nil
+				})).To(Succeed())
+
+				return entries
+			}
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_MAINTENANCE")
+			Expect(os.RemoveAll(otherLayersDir)).To(Succeed())
+		})
+
+		it("writes every file of its layers, including the environment and exec.d, identically with normalized timestamps", func() {
+			for _, dir := range []string{layersDir, otherLayersDir} {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: dir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			first := snapshot(layersDir)
+			Expect(first).To(HaveKey(filepath.Join("start", npmstart.StartCommandScript)))
+			Expect(first).To(HaveKey(filepath.Join("maintenance", "bin", "maintenance-server")))
+			Expect(first).To(HaveKey(filepath.Join("start", "env.launch", "PATH.append")))
+			Expect(first).To(HaveKey(filepath.Join("helpers", "exec.d", "0-wait-for-bindings")))
+			Expect(snapshot(otherLayersDir)).To(Equal(first))
+
+			for path, e := range first {
+				Expect(e.ModTime).To(Equal(npmstart.ReproducibleTime), path)
+			}
+		})
+	})
+
+	context("when the layers directory holds layers from a previous build", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "maintenance-server"), []byte("maintenance-server-contents"), 0755)).To(Succeed())
+
+			for _, name := range []string{"start", "maintenance"} {
+				Expect(os.MkdirAll(filepath.Join(layersDir, name, "env.launch", "stale-process"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(layersDir, name, "start.sh"), []byte("stale-contents"), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(layersDir, name, "env.launch", "stale-process", "NODE_ENV.override"), []byte("stale"), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(layersDir, name+".toml"), []byte(`
+launch = true
+cache = true
+
+[metadata]
+  schema = "0"
+  command = "sh start.sh"
+`), 0600)).To(Succeed())
+			}
+
+			os.Setenv("BP_NPM_START_MAINTENANCE", "true")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_MAINTENANCE")
+		})
+
+		it("rebuilds the layers from scratch", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(3))
+			for _, layer := range result.Layers {
+				Expect(layer.Metadata).To(BeNil())
+				Expect(layer.Cache).To(BeFalse())
+				Expect(launchEnv(layer)).NotTo(HaveKey("NODE_ENV.override"))
+				Expect(processLaunchEnv(layer)).NotTo(HaveKey("stale-process"))
+			}
+
+			entries, err := os.ReadDir(filepath.Join(layersDir, "start"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Name()).To(Equal("env.launch"))
+
+			var files []string
+			err = filepath.Walk(filepath.Join(layersDir, "maintenance"), func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if !info.IsDir() {
+					rel, err := filepath.Rel(filepath.Join(layersDir, "maintenance"), path)
+					if err != nil {
+						return err
+					}
+					files = append(files, rel)
+				}
+
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(Equal([]string{filepath.Join("bin", "maintenance-server")}))
+		})
+	})
+
+	context("when BP_NPM_START_MAINTENANCE is not set", func() {
+		it("does not add a maintenance process", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(HaveLen(1))
+			Expect(result.Layers).To(HaveLen(2))
+			Expect(result.Layers[0].Name).To(Equal("start"))
+		})
+	})
+
+	context("when BP_NPM_START_RUN_PREPARE=true in the build environment", func() {
+		it.Before(func() {
+			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"scripts": {
+					"prepare": "some-prepare-command",
+					"prestart": "some-prestart-command",
+					"start": "some-start-command",
+					"poststart": "some-poststart-command"
+				}
+			}`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+
+			os.Setenv("BP_NPM_START_RUN_PREPARE", "true")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_RUN_PREPARE")
+		})
+
+		it("runs the prepare script at the head of the start command", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "web",
+					Command: "bash",
+					Args: []string{
+						"-c",
+						fmt.Sprintf("cd %s/some-project-dir && some-prepare-command && some-prestart-command && some-start-command && some-poststart-command", workingDir),
+					},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+		})
+	})
+
+	context("when there is a prepare script and BP_NPM_START_RUN_PREPARE is not set", func() {
+		it.Before(func() {
+			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"scripts": {
+					"prepare": "some-prepare-command",
+					"start": "some-start-command"
+				}
+			}`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("leaves the prepare script out of the start command", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes[0].Args).To(Equal([]string{
+				"-c",
+				fmt.Sprintf("cd %s/some-project-dir && some-start-command", workingDir),
+			}))
+		})
+	})
+
+	context("when there is no prestart script", func() {
+		it.Before(func() {
+			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"scripts": {
+					"start": "some-start-command",
+					"poststart": "some-poststart-command"
+				}
+			}`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("specifies a valid start command", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result).To(Equal(packit.BuildResult{
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: []packit.Layer{
+					{
+						Path:             filepath.Join(layersDir, "start"),
+						Name:             "start",
+						Launch:           true,
+						SharedEnv:        packit.Environment{},
+						BuildEnv:         packit.Environment{},
+						LaunchEnv:        packit.Environment{},
+						ProcessLaunchEnv: map[string]packit.Environment{},
+					},
+					{
+						Path:             filepath.Join(layersDir, "helpers"),
+						Name:             "helpers",
+						Launch:           true,
+						SharedEnv:        packit.Environment{},
+						BuildEnv:         packit.Environment{},
+						LaunchEnv:        packit.Environment{},
+						ProcessLaunchEnv: map[string]packit.Environment{},
+					},
+				},
+				Launch: packit.LaunchMetadata{
+					Processes: []packit.Process{
+						{
+							Type:    "web",
+							Command: "bash",
+							Args: []string{
+								"-c",
+								fmt.Sprintf("cd %s/some-project-dir && some-start-command && some-poststart-command", workingDir),
+							},
+							Direct:  true,
+							Default: true,
+						},
+					},
+				},
+			}))
+
+			Expect(launchEnv(result.Layers[0])).To(Equal(packit.Environment{
+				"PATH.append": filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin"),
+				"PATH.delim":  ":",
+			}))
+			Expect(execD(result.Layers[1])).To(Equal([]string{"0-wait-for-bindings"}))
+		})
+	})
+
+	context("when there is no poststart script", func() {
+		it.Before(func() {
+			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"scripts": {
+					"prestart": "some-prestart-command",
+					"start": "some-start-command"
+				}
+			}`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("specifies a valid start command", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result).To(Equal(packit.BuildResult{
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: []packit.Layer{
+					{
+						Path:             filepath.Join(layersDir, "start"),
+						Name:             "start",
+						Launch:           true,
+						SharedEnv:        packit.Environment{},
+						BuildEnv:         packit.Environment{},
+						LaunchEnv:        packit.Environment{},
+						ProcessLaunchEnv: map[string]packit.Environment{},
+					},
+					{
+						Path:             filepath.Join(layersDir, "helpers"),
+						Name:             "helpers",
+						Launch:           true,
+						SharedEnv:        packit.Environment{},
+						BuildEnv:         packit.Environment{},
+						LaunchEnv:        packit.Environment{},
+						ProcessLaunchEnv: map[string]packit.Environment{},
+					},
+				},
+				Launch: packit.LaunchMetadata{
+					Processes: []packit.Process{
+						{
+							Type:    "web",
+							Command: "bash",
+							Args: []string{
+								"-c",
+								fmt.Sprintf("cd %s/some-project-dir && some-prestart-command && some-start-command", workingDir),
+							},
+							Direct:  true,
+							Default: true,
+						},
+					},
+				},
+			}))
+
+			Expect(launchEnv(result.Layers[0])).To(Equal(packit.Environment{
+				"PATH.append": filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin"),
+				"PATH.delim":  ":",
+			}))
+			Expect(execD(result.Layers[1])).To(Equal([]string{"0-wait-for-bindings"}))
+		})
+	})
+
+	context("when there is no start script", func() {
+		it.Before(func() {
+			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"scripts": {
+					"prestart": "some-prestart-command",
+					"poststart": "some-poststart-command"
+				}
+			}`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("specifies a valid start command", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result).To(Equal(packit.BuildResult{
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: []packit.Layer{
+					{
+						Path:             filepath.Join(layersDir, "start"),
+						Name:             "start",
+						Launch:           true,
+						SharedEnv:        packit.Environment{},
+						BuildEnv:         packit.Environment{},
+						LaunchEnv:        packit.Environment{},
+						ProcessLaunchEnv: map[string]packit.Environment{},
+					},
+					{
+						Path:             filepath.Join(layersDir, "helpers"),
+						Name:             "helpers",
+						Launch:           true,
+						SharedEnv:        packit.Environment{},
+						BuildEnv:         packit.Environment{},
+						LaunchEnv:        packit.Environment{},
+						ProcessLaunchEnv: map[string]packit.Environment{},
+					},
+				},
+				Launch: packit.LaunchMetadata{
+					Processes: []packit.Process{
+						{
+							Type:    "web",
+							Command: "bash",
+							Args: []string{
+								"-c",
+								fmt.Sprintf("cd %[1]s/some-project-dir && some-prestart-command && node %[1]s/server.js && some-poststart-command", workingDir),
+							},
+							Direct:  true,
+							Default: true,
+						},
+					},
+				},
+			}))
+
+			Expect(launchEnv(result.Layers[0])).To(Equal(packit.Environment{
+				"PATH.append": filepath.Join(workingDir, "some-project-dir", "node_modules", ".bin"),
+				"PATH.delim":  ":",
+			}))
+			Expect(execD(result.Layers[1])).To(Equal([]string{"0-wait-for-bindings"}))
+		})
+	})
+
+	context("when there is no start script but a sole bin entry", func() {
+		it.Before(func() {
+			err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+				"name": "some-service",
+				"bin": {
+					"some-service": "./dist/cli.js"
+				}
+			}`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.MkdirAll(filepath.Join(workingDir, "some-project-dir", "dist"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "dist", "cli.js"), nil, 0600)).To(Succeed())
+		})
+
+		it("runs the bin entry with node", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "web",
+					Command: "bash",
+					Args: []string{
+						"-c",
+						fmt.Sprintf("cd %[1]s/some-project-dir && node %[1]s/some-project-dir/dist/cli.js", workingDir),
+					},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+		})
+
+		context("when the bin entry does not exist", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(workingDir, "some-project-dir", "dist", "cli.js"))).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to find the bin entrypoint:")))
+				Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+			})
+		})
+	})
+
+	context("when the project-path env var is not set", func() {
+		it.Before(func() {
+			pathParser.GetCall.Returns.ProjectPath = workingDir
+
+			err := os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{
+				"scripts": {
+					"prestart": "some-prestart-command",
+					"start": "some-start-command",
+					"poststart": "some-poststart-command"
+				}
+			}`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(os.Remove(filepath.Join(workingDir, "package.json"))).To(Succeed())
+		})
+
+		it("returns a result with a valid start command", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result).To(Equal(packit.BuildResult{
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: []packit.Layer{
+					{
+						Path:             filepath.Join(layersDir, "start"),
+						Name:             "start",
+						Launch:           true,
+						SharedEnv:        packit.Environment{},
+						BuildEnv:         packit.Environment{},
+						LaunchEnv:        packit.Environment{},
+						ProcessLaunchEnv: map[string]packit.Environment{},
+					},
+					{
+						Path:             filepath.Join(layersDir, "helpers"),
+						Name:             "helpers",
+						Launch:           true,
+						SharedEnv:        packit.Environment{},
+						BuildEnv:         packit.Environment{},
+						LaunchEnv:        packit.Environment{},
+						ProcessLaunchEnv: map[string]packit.Environment{},
+					},
+				},
+				Launch: packit.LaunchMetadata{
+					Processes: []packit.Process{
+						{
+							Type:    "web",
+							Command: "bash",
+							Args: []string{
+								"-c",
+								"some-prestart-command && some-start-command && some-poststart-command",
+							},
+							Direct:  true,
+							Default: true,
+						},
+					},
+				},
+			}))
+
+			Expect(launchEnv(result.Layers[0])).To(Equal(packit.Environment{
+				"PATH.append": filepath.Join(workingDir, "node_modules", ".bin"),
+				"PATH.delim":  ":",
+			}))
+			Expect(execD(result.Layers[1])).To(Equal([]string{"0-wait-for-bindings"}))
+		})
+	})
+
+	context("when the scripts embed a secret", func() {
+		const sentinel = "sentinel-secret-0123456789"
+
+		var buffer *bytes.Buffer
+
+		it.Before(func() {
+			buffer = bytes.NewBuffer(nil)
+			build = npmstart.BuildWithNode(gocontext.Background(), pathParser, node, scribe.NewEmitter(buffer).WithLevel("DEBUG"))
+
+			os.Setenv("CNB_PLATFORM_API", "0.10")
+			os.Setenv("BP_NPM_START_EXPLAIN", "true")
+			os.Setenv("BP_NPM_START_TRACE_WRAPPERS", "true")
+		})
+
+		it.After(func() {
+			os.Unsetenv("CNB_PLATFORM_API")
+			os.Unsetenv("BP_NPM_START_EXPLAIN")
+			os.Unsetenv("BP_NPM_START_TRACE_WRAPPERS")
+		})
+
+		it("never shows the secret in the log or in errors", func() {
+			scripts := []string{
+				`{"scripts": {"start": "API_TOKEN=` + sentinel + ` node server.js --password ` + sentinel + ` -- --flag"}}`,
+				`{"scripts": {"start": "webpack --token=` + sentinel + `"}}`,
+				`{"scripts": {"start": "npx some-cli --db https://user:` + sentinel + `@db:5432"}}`,
+				`{"scripts": {"start": "SECRET_KEY=` + sentinel + ` some-missing-cli \u001b[31m\n&&"}}`,
+			}
+
+			for _, env := range []map[string]string{
+				{},
+				{"BP_NPM_START_STRICT": "true"},
+				{"BP_NPM_START_FORWARD_PORT": "true"},
+			} {
+				for name, value := range env {
+					os.Setenv(name, value)
+				}
+
+				for _, script := range scripts {
+					Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(script), 0600)).To(Succeed())
+
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Stack:      "some-stack",
+						BuildpackInfo: packit.BuildpackInfo{
+							Name:    "Some Buildpack",
+							Version: "some-version",
+						},
+						Plan: packit.BuildpackPlan{
+							Entries: []packit.BuildpackPlanEntry{},
+						},
+						Layers: packit.Layers{Path: layersDir},
+					})
+					if err != nil {
+						Expect(err.Error()).NotTo(ContainSubstring(sentinel), script)
+					}
+				}
+
+				for name := range env {
+					os.Unsetenv(name)
+				}
+			}
+
+			Expect(buffer.String()).To(ContainSubstring("[redacted]"))
+			Expect(buffer.String()).NotTo(ContainSubstring(sentinel))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the package.json file does not exist", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(workingDir, "some-project-dir", "package.json"))).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+			})
+		})
+
+		context("when the context is canceled while the project path is being resolved", func() {
+			var release chan struct{}
+
+			it.Before(func() {
+				release = make(chan struct{})
+				pathParser.GetCall.Stub = func(string) (string, error) {
+					<-release
+					return filepath.Join(workingDir, "some-project-dir"), nil
+				}
+
+				ctx, cancel := gocontext.WithCancel(gocontext.Background())
+				time.AfterFunc(10*time.Millisecond, cancel)
+
+				build = npmstart.BuildWithNode(ctx, pathParser, node, scribe.NewEmitter(buffer))
+			})
+
+			it.After(func() {
+				close(release)
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(gocontext.Canceled))
+				Expect(err).To(MatchError(ContainSubstring("canceled while resolving the project path")))
+			})
+		})
+
+		context("when the context is canceled before the build starts", func() {
+			it.Before(func() {
+				ctx, cancel := gocontext.WithCancel(gocontext.Background())
+				cancel()
+
+				build = npmstart.BuildWithNode(ctx, pathParser, node, scribe.NewEmitter(buffer))
+			})
+
+			it("returns an error without resolving the project path", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(gocontext.Canceled))
+				Expect(pathParser.GetCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("when the package.json is malformed", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte("%%%"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("invalid character '%'")))
+			})
+		})
+
+		context("when CNB_PLATFORM_API is set to an invalid value", func() {
+			it.Before(func() {
+				os.Setenv("CNB_PLATFORM_API", "not-a-version")
+			})
+
+			it.After(func() {
+				os.Unsetenv("CNB_PLATFORM_API")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to parse CNB_PLATFORM_API value not-a-version")))
+			})
+		})
+
+		context("when the live reload provider is unknown and there is no command template", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+				os.Setenv("BP_LIVE_RELOAD_PROVIDER", "some-reloader")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+				os.Unsetenv("BP_LIVE_RELOAD_PROVIDER")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan:   reloadPlan("some-reloader"),
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(`unknown live reload provider "some-reloader": set BP_LIVE_RELOAD_COMMAND_TEMPLATE to configure its command`))
+			})
+		})
+
+		context("when BP_LIVE_RELOAD_SIGNAL is set to an invalid value", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+				os.Setenv("BP_LIVE_RELOAD_SIGNAL", "not a signal")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+				os.Unsetenv("BP_LIVE_RELOAD_SIGNAL")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan:   reloadPlan("watchexec"),
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError("failed to parse BP_LIVE_RELOAD_SIGNAL value not a signal: not a signal name"))
+			})
+		})
+
+		context("when BP_LIVE_RELOAD_SIGNAL is set for the entr provider", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+				os.Setenv("BP_LIVE_RELOAD_PROVIDER", "entr")
+				os.Setenv("BP_LIVE_RELOAD_SIGNAL", "SIGUSR2")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+				os.Unsetenv("BP_LIVE_RELOAD_PROVIDER")
+				os.Unsetenv("BP_LIVE_RELOAD_SIGNAL")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan:   reloadPlan("entr"),
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("the entr live reload provider always restarts with SIGTERM")))
+			})
+		})
+
+		context("when the live reload command template cannot be rendered", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+				os.Setenv("BP_LIVE_RELOAD_COMMAND_TEMPLATE", "some-reloader {{unknown}}")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+				os.Unsetenv("BP_LIVE_RELOAD_COMMAND_TEMPLATE")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan:   reloadPlan("watchexec"),
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to parse BP_LIVE_RELOAD_COMMAND_TEMPLATE")))
+			})
+		})
+
+		context("when BP_NPM_START_SUPPRESS_WARNINGS is set to an invalid value", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_SUPPRESS_WARNINGS", "not-a-bool")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_SUPPRESS_WARNINGS")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to parse BP_NPM_START_SUPPRESS_WARNINGS value not-a-bool")))
+			})
+		})
+
+		context("when BP_NPM_START_FORWARD_PORT=true and the start command runs a node file", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_FORWARD_PORT", "true")
+				err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+					"scripts": {
+						"start": "node server.js"
+					}
+				}`), 0600)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_FORWARD_PORT")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("BP_NPM_START_FORWARD_PORT is set but the start command \"node server.js\" runs a node file")))
+			})
+		})
+
+		context("when BP_NPM_START_PROCESS_ENV refers to an unknown process", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_PROCESS_ENV", "worker:NODE_OPTIONS=--max-old-space-size=4096")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_PROCESS_ENV")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(`BP_NPM_START_PROCESS_ENV refers to unknown process type "worker"`))
+			})
+		})
+
+		context("when BP_NPM_START_PROCESS_ENV is malformed", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_PROCESS_ENV", "web:NODE_ENV")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_PROCESS_ENV")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(`failed to parse BP_NPM_START_PROCESS_ENV entry "web:NODE_ENV": expected <process>:<name>=<value>`))
+			})
+		})
+
+		context("when the command hook fails", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_COMMAND_HOOK", "hooks/command.js")
+				node.ExecuteCall.Stub = func(execution pexec.Execution) error {
+					fmt.Fprint(execution.Stderr, "some-hook-stderr")
+					return errors.New("exit status 1")
+				}
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_COMMAND_HOOK")
+			})
+
+			it("returns an error including the hook's stderr", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(fmt.Sprintf("command hook %s failed: exit status 1\nsome-hook-stderr", filepath.Join(workingDir, "hooks", "command.js"))))
+			})
+		})
+
+		context("when the command hook prints an invalid command", func() {
+			var output string
+
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_COMMAND_HOOK", "hooks/command.js")
+				node.ExecuteCall.Stub = func(execution pexec.Execution) error {
+					fmt.Fprint(execution.Stdout, output)
+					return nil
+				}
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_COMMAND_HOOK")
+			})
+
+			for description, test := range map[string]struct{ output, message string }{
+				"invalid JSON":          {output: "not-json", message: "printed invalid JSON"},
+				"an empty command":      {output: `{"command": "", "args": ["some-arg"]}`, message: "printed an invalid command: command is empty"},
+				"a control character":   {output: `{"command": "bash", "args": ["-c", "some\u0007command"]}`, message: "contains a control character"},
+				"a newline in the args": {output: `{"command": "bash", "args": ["-c", "some\ncommand"]}`, message: "contains a control character"},
+			} {
+				test := test
+
+				context(fmt.Sprintf("with %s", description), func() {
+					it.Before(func() {
+						output = test.output
+					})
+
+					it("returns an error", func() {
+						_, err := build(packit.BuildContext{
+							WorkingDir: workingDir,
+							CNBPath:    cnbDir,
+							Stack:      "some-stack",
+							BuildpackInfo: packit.BuildpackInfo{
+								Name:    "Some Buildpack",
+								Version: "some-version",
+							},
+							Plan: packit.BuildpackPlan{
+								Entries: []packit.BuildpackPlanEntry{},
+							},
+							Layers: packit.Layers{Path: layersDir},
+						})
+						Expect(err).To(MatchError(ContainSubstring(test.message)))
+					})
+				})
+			}
+		})
+
+		context("when a script leaves a dangling operator", func() {
+			it.Before(func() {
+				err := os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+					"scripts": {
+						"prestart": "some-prestart-command &&",
+						"start": "some-start-command"
+					}
+				}`), 0600)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(`the prestart script "some-prestart-command &&" has a dangling "&&"`))
+			})
+		})
+
+		context("when BP_NPM_START_WORKLOAD_TYPE is set to an invalid value", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_WORKLOAD_TYPE", "daemon")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_WORKLOAD_TYPE")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError("failed to parse BP_NPM_START_WORKLOAD_TYPE value daemon: expected web or worker"))
+			})
+		})
+
+		context("when BP_NPM_START_LABELS sets a reserved label", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_LABELS", "io.buildpacks.lifecycle.metadata=some-value")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_LABELS")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("keys starting with io.buildpacks. are reserved for the lifecycle")))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when BP_NPM_START_LABELS sets the workload label", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_LABELS", "io.paketo.npm-start.workload=worker")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_LABELS")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError("BP_NPM_START_LABELS cannot set the io.paketo.npm-start.workload label, use BP_NPM_START_WORKLOAD_TYPE instead"))
+			})
+		})
+
+		context("when BP_NPM_START_LABELS sets another label of the buildpack", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_REVISION", "some-revision")
+				os.Setenv("BP_NPM_START_LABELS", "autoscaling.knative.dev/target=10,io.paketo.npm-start.revision=other-revision")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_REVISION")
+				os.Unsetenv("BP_NPM_START_LABELS")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError("BP_NPM_START_LABELS cannot set the io.paketo.npm-start.revision label, labels starting with io.paketo.npm-start. are set by the buildpack"))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when BP_NPM_START_HEALTHCHECK_SCRIPT names a script that package.json does not define", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_HEALTHCHECK_SCRIPT", "healthcheck")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_HEALTHCHECK_SCRIPT")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError("BP_NPM_START_HEALTHCHECK_SCRIPT names the script \"healthcheck\", which package.json does not define"))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when BP_NPM_START_JOBS names a script that package.json does not define", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+					"scripts": {
+						"start": "some-start-command",
+						"db:migrate": "some-migrate-command",
+						"web": "some-web-command"
+					}
+				}`), 0600)).To(Succeed())
+
+				os.Setenv("BP_NPM_START_JOBS", "migrate")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_JOBS")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError("BP_NPM_START_JOBS names the script \"migrate\", which package.json does not define"))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when BP_NPM_START_JOBS names a script that is not a valid process type", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+					"scripts": {
+						"start": "some-start-command",
+						"db:migrate": "some-migrate-command",
+						"web": "some-web-command"
+					}
+				}`), 0600)).To(Succeed())
+
+				os.Setenv("BP_NPM_START_JOBS", "db:migrate")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_JOBS")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError("BP_NPM_START_JOBS names the script \"db:migrate\", which is not a valid process type: use letters, digits, \".\", \"-\" and \"_\" only"))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when BP_NPM_START_JOBS names a script that clashes with a process of the buildpack", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-project-dir", "package.json"), []byte(`{
+					"scripts": {
+						"start": "some-start-command",
+						"db:migrate": "some-migrate-command",
+						"web": "some-web-command"
+					}
+				}`), 0600)).To(Succeed())
+
+				os.Setenv("BP_NPM_START_JOBS", "web")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_JOBS")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError("BP_NPM_START_JOBS names the script \"web\", which clashes with the web process of the buildpack"))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when several build options are invalid", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_MAINTENANCE", "sometimes")
+				os.Setenv("BP_NPM_START_WORKLOAD_TYPE", "cron")
+				os.Setenv("BP_NPM_START_FORWARD_PORT", "true")
+				os.Setenv("BP_NPM_START_NO_DEFAULT_PROCESS", "yes please")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_MAINTENANCE")
+				os.Unsetenv("BP_NPM_START_WORKLOAD_TYPE")
+				os.Unsetenv("BP_NPM_START_FORWARD_PORT")
+				os.Unsetenv("BP_NPM_START_NO_DEFAULT_PROCESS")
+			})
+
+			it("reports them together", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("found 3 problems with the build configuration:\n")))
+				Expect(err.Error()).To(ContainSubstring("\n  1. failed to parse BP_NPM_START_MAINTENANCE value sometimes"))
+				Expect(err.Error()).To(ContainSubstring("\n  2. failed to parse BP_NPM_START_NO_DEFAULT_PROCESS value yes please"))
+				Expect(err.Error()).To(ContainSubstring("\n  3. failed to parse BP_NPM_START_WORKLOAD_TYPE value cron"))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when BP_NPM_START_SHELL is set to an invalid value", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_SHELL", "zsh")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_SHELL")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError("failed to parse BP_NPM_START_SHELL value zsh: expected sh or bash"))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when the descriptor does not exist", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_DESCRIPTOR", "manifest.yml")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_DESCRIPTOR")
+				os.Unsetenv("BP_NPM_START_DESCRIPTOR_APP")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to read application descriptor")))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when the descriptor names several applications and BP_NPM_START_DESCRIPTOR_APP is not set", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_DESCRIPTOR", "manifest.yml")
+				Expect(os.WriteFile(filepath.Join(workingDir, "manifest.yml"), []byte("applications:\n- name: some-app\n  command: node server.js\n- name: other-app\n  command: node worker.js\n"), 0600)).To(Succeed())
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_DESCRIPTOR")
+				os.Unsetenv("BP_NPM_START_DESCRIPTOR_APP")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to select an application of BP_NPM_START_DESCRIPTOR manifest.yml: the descriptor names 2 applications, set BP_NPM_START_DESCRIPTOR_APP to one of some-app, other-app")))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when the selected application has no command", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_DESCRIPTOR", "manifest.yml")
+				Expect(os.WriteFile(filepath.Join(workingDir, "manifest.yml"), []byte("applications:\n- name: some-app\n  memory: 256M\n"), 0600)).To(Succeed())
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_DESCRIPTOR")
+				os.Unsetenv("BP_NPM_START_DESCRIPTOR_APP")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring(`application "some-app" of BP_NPM_START_DESCRIPTOR manifest.yml has no command`)))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when BP_NPM_START_DESCRIPTOR_APP is set without BP_NPM_START_DESCRIPTOR", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_DESCRIPTOR_APP", "some-app")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_DESCRIPTOR")
+				os.Unsetenv("BP_NPM_START_DESCRIPTOR_APP")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("BP_NPM_START_DESCRIPTOR_APP is set, but BP_NPM_START_DESCRIPTOR is not")))
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			})
+		})
+
+		context("when BP_NPM_START_MAINTENANCE is set to an invalid value", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_MAINTENANCE", "not-a-bool")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_MAINTENANCE")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to parse BP_NPM_START_MAINTENANCE value not-a-bool")))
+			})
+		})
+
+		context("when the maintenance page does not exist", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_MAINTENANCE", "true")
+				os.Setenv("BP_NPM_START_MAINTENANCE_PAGE", "no-such-page.html")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_MAINTENANCE")
+				os.Unsetenv("BP_NPM_START_MAINTENANCE_PAGE")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to find maintenance page")))
+			})
+		})
+
+		context("when the maintenance server is missing from the buildpack", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_MAINTENANCE", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_MAINTENANCE")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to install maintenance-server")))
+			})
+
+			it("leaves no layer metadata or partial files behind", func() {
+				Expect(os.WriteFile(filepath.Join(layersDir, "start.toml"), []byte("[types]\n  launch = true\n  cache = true\n"), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(layersDir, "maintenance.toml"), []byte("[types]\n  launch = true\n  cache = true\n"), 0600)).To(Succeed())
+
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(HaveOccurred())
+
+				Expect(filepath.Join(layersDir, "start.toml")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(layersDir, "maintenance.toml")).NotTo(BeAnExistingFile())
+
+				entries, err := os.ReadDir(filepath.Join(layersDir, "maintenance", "bin"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entries).To(BeEmpty())
 			})
 		})
 
@@ -0,0 +1,105 @@
+package npmstart
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/chronos"
+)
+
+type cachingPathParserKey struct {
+	path        string
+	projectPath string
+}
+
+type cachingPathParserEntry struct {
+	projectPath string
+	err         error
+	expires     time.Time
+}
+
+// CachingPathParser wraps a PathParser and memoizes its results, including
+// failed resolutions, for a fixed TTL. Results are keyed by the path given to
+// Get and the value of $BP_NODE_PROJECT_PATH at the time of the call. It is
+// safe for concurrent use and intended for long-lived processes that run
+// detection repeatedly; Detect and Build do not use it unless it is passed to
+// them.
+type CachingPathParser struct {
+	inner PathParser
+	ttl   time.Duration
+	clock chronos.Clock
+
+	mutex   sync.Mutex
+	entries map[cachingPathParserKey]cachingPathParserEntry
+}
+
+// NewCachingPathParser creates a CachingPathParser that caches the results of
+// the given PathParser for the given TTL.
+func NewCachingPathParser(inner PathParser, ttl time.Duration) *CachingPathParser {
+	return &CachingPathParser{
+		inner:   inner,
+		ttl:     ttl,
+		clock:   chronos.DefaultClock,
+		entries: map[cachingPathParserKey]cachingPathParserEntry{},
+	}
+}
+
+// WithClock sets the clock used to expire cached results.
+func (p *CachingPathParser) WithClock(clock chronos.Clock) *CachingPathParser {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.clock = clock
+	return p
+}
+
+// Get returns the cached result for the given path if one exists and has not
+// expired, and otherwise resolves it with the wrapped PathParser.
+func (p *CachingPathParser) Get(path string) (string, error) {
+	key := cachingPathParserKey{
+		path:        path,
+		projectPath: os.Getenv("BP_NODE_PROJECT_PATH"),
+	}
+
+	p.mutex.Lock()
+	entry, ok := p.entries[key]
+	now := p.clock.Now()
+	p.mutex.Unlock()
+
+	if ok && now.Before(entry.expires) {
+		return entry.projectPath, entry.err
+	}
+
+	projectPath, err := p.inner.Get(path)
+
+	p.mutex.Lock()
+	p.entries[key] = cachingPathParserEntry{
+		projectPath: projectPath,
+		err:         err,
+		expires:     p.clock.Now().Add(p.ttl),
+	}
+	p.mutex.Unlock()
+
+	return projectPath, err
+}
+
+// Invalidate removes every cached result for the given path.
+func (p *CachingPathParser) Invalidate(path string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for key := range p.entries {
+		if key.path == path {
+			delete(p.entries, key)
+		}
+	}
+}
+
+// InvalidateAll removes every cached result.
+func (p *CachingPathParser) InvalidateAll() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.entries = map[cachingPathParserKey]cachingPathParserEntry{}
+}
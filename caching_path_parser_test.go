@@ -0,0 +1,135 @@
+package npmstart_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/paketo-buildpacks/npm-start/fakes"
+	"github.com/paketo-buildpacks/packit/v2/chronos"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCachingPathParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		now        time.Time
+		pathParser *fakes.PathParser
+		parser     *npmstart.CachingPathParser
+	)
+
+	it.Before(func() {
+		now = time.Now()
+
+		pathParser = &fakes.PathParser{}
+		pathParser.GetCall.Stub = func(path string) (string, error) {
+			return path + "/" + os.Getenv("BP_NODE_PROJECT_PATH"), nil
+		}
+
+		parser = npmstart.NewCachingPathParser(pathParser, time.Minute).
+			WithClock(chronos.NewClock(func() time.Time { return now }))
+	})
+
+	it.After(func() {
+		os.Unsetenv("BP_NODE_PROJECT_PATH")
+	})
+
+	it("resolves each path once within the TTL", func() {
+		projectPath, err := parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(projectPath).To(Equal("/workspace/"))
+
+		projectPath, err = parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(projectPath).To(Equal("/workspace/"))
+
+		Expect(pathParser.GetCall.CallCount).To(Equal(1))
+	})
+
+	it("keys results by the value of BP_NODE_PROJECT_PATH", func() {
+		_, err := parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+
+		os.Setenv("BP_NODE_PROJECT_PATH", "some-project-dir")
+
+		projectPath, err := parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(projectPath).To(Equal("/workspace/some-project-dir"))
+
+		Expect(pathParser.GetCall.CallCount).To(Equal(2))
+	})
+
+	it("resolves the path again once the TTL has expired", func() {
+		_, err := parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+
+		now = now.Add(59 * time.Second)
+		_, err = parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pathParser.GetCall.CallCount).To(Equal(1))
+
+		now = now.Add(time.Second)
+		_, err = parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pathParser.GetCall.CallCount).To(Equal(2))
+	})
+
+	it("resolves the path again once it has been invalidated", func() {
+		_, err := parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = parser.Get("/other-workspace")
+		Expect(err).NotTo(HaveOccurred())
+
+		parser.Invalidate("/workspace")
+
+		_, err = parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = parser.Get("/other-workspace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pathParser.GetCall.CallCount).To(Equal(3))
+
+		parser.InvalidateAll()
+
+		_, err = parser.Get("/other-workspace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pathParser.GetCall.CallCount).To(Equal(4))
+	})
+
+	it("is safe for concurrent use", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = parser.Get("/workspace")
+				parser.Invalidate("/other-workspace")
+			}()
+		}
+		wg.Wait()
+
+		Expect(pathParser.GetCall.CallCount).To(BeNumerically(">=", 1))
+	})
+
+	context("when the wrapped parser fails", func() {
+		it.Before(func() {
+			pathParser.GetCall.Stub = nil
+			pathParser.GetCall.Returns.Err = errors.New("failed to resolve")
+		})
+
+		it("caches the failure", func() {
+			_, err := parser.Get("/workspace")
+			Expect(err).To(MatchError("failed to resolve"))
+
+			_, err = parser.Get("/workspace")
+			Expect(err).To(MatchError("failed to resolve"))
+
+			Expect(pathParser.GetCall.CallCount).To(Equal(1))
+		})
+	})
+}
@@ -0,0 +1,33 @@
+package npmstart
+
+import (
+	"context"
+	"fmt"
+)
+
+// cancellable runs f and returns its error, or returns early with a wrapped
+// ctx.Err() if ctx is done first. f keeps running in the background after a
+// cancellation, so it must not write to state the caller reads afterwards.
+//
+// When ctx carries a Tracer, the action is recorded as a step of the traced
+// phase.
+func cancellable(ctx context.Context, action string, f func() error) (err error) {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("canceled before %s: %w", action, err)
+	}
+
+	end := traceStep(ctx, action)
+	defer func() { end(err) }()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("canceled while %s: %w", action, ctx.Err())
+	}
+}
@@ -0,0 +1,33 @@
+package npmstart
+
+// ChainPathParser resolves the project path with each of a list of
+// PathParsers in turn. The first project path other than the working
+// directory wins, so a parser defers to the next one by returning the working
+// directory unchanged, as the ProjectPathParser does when
+// $BP_NODE_PROJECT_PATH is unset. An error from any parser ends the chain.
+type ChainPathParser struct {
+	parsers []PathParser
+}
+
+// NewChainPathParser creates a ChainPathParser that tries the given
+// PathParsers in order.
+func NewChainPathParser(parsers ...PathParser) ChainPathParser {
+	return ChainPathParser{parsers: parsers}
+}
+
+// Get returns the first project path resolved by the parsers of the chain
+// that differs from the given path, or the path itself when none differs.
+func (p ChainPathParser) Get(path string) (string, error) {
+	for _, parser := range p.parsers {
+		projectPath, err := parser.Get(path)
+		if err != nil {
+			return "", err
+		}
+
+		if projectPath != path {
+			return projectPath, nil
+		}
+	}
+
+	return path, nil
+}
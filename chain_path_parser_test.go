@@ -0,0 +1,145 @@
+package npmstart_test
+
+import (
+	"bufio"
+	"bytes"
+	gocontext "context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/paketo-buildpacks/npm-start/fakes"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+// manifestPathParser is a custom PathParser that reads the project path from
+// the "root:" line of a .platform/app.yaml manifest.
+type manifestPathParser struct{}
+
+func (manifestPathParser) Get(path string) (string, error) {
+	file, err := os.Open(filepath.Join(path, ".platform", "app.yaml"))
+	if errors.Is(err, os.ErrNotExist) {
+		return path, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if root := strings.TrimPrefix(scanner.Text(), "root:"); root != scanner.Text() {
+			return filepath.Join(path, strings.TrimSpace(root)), nil
+		}
+	}
+
+	return path, scanner.Err()
+}
+
+func testChainPathParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		first  *fakes.PathParser
+		second *fakes.PathParser
+		parser npmstart.ChainPathParser
+	)
+
+	it.Before(func() {
+		first = &fakes.PathParser{}
+		first.GetCall.Returns.ProjectPath = "/workspace"
+
+		second = &fakes.PathParser{}
+		second.GetCall.Returns.ProjectPath = "/workspace/some-project-dir"
+
+		parser = npmstart.NewChainPathParser(first, second)
+	})
+
+	it("returns the first project path other than the working directory", func() {
+		projectPath, err := parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(projectPath).To(Equal("/workspace/some-project-dir"))
+
+		Expect(first.GetCall.Receives.Path).To(Equal("/workspace"))
+		Expect(second.GetCall.Receives.Path).To(Equal("/workspace"))
+	})
+
+	it("does not consult the parsers after the one that resolved the path", func() {
+		first.GetCall.Returns.ProjectPath = "/workspace/other-project-dir"
+
+		projectPath, err := parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(projectPath).To(Equal("/workspace/other-project-dir"))
+
+		Expect(second.GetCall.CallCount).To(Equal(0))
+	})
+
+	it("returns the working directory when no parser resolves another path", func() {
+		second.GetCall.Returns.ProjectPath = "/workspace"
+
+		projectPath, err := parser.Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(projectPath).To(Equal("/workspace"))
+
+		projectPath, err = npmstart.NewChainPathParser().Get("/workspace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(projectPath).To(Equal("/workspace"))
+	})
+
+	it("stops at the first error", func() {
+		first.GetCall.Returns.Err = errors.New("some-error")
+
+		_, err := parser.Get("/workspace")
+		Expect(err).To(MatchError("some-error"))
+
+		Expect(second.GetCall.CallCount).To(Equal(0))
+	})
+
+	context("when a custom resolver is chained ahead of the ProjectPathParser", func() {
+		var (
+			workingDir string
+			detect     packit.DetectFunc
+		)
+
+		it.Before(func() {
+			var err error
+			workingDir, err = os.MkdirTemp("", "working-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.MkdirAll(filepath.Join(workingDir, "from-manifest"), os.ModePerm)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(workingDir, "from-env"), os.ModePerm)).To(Succeed())
+
+			os.Setenv("BP_NODE_PROJECT_PATH", "from-env")
+
+			detect = npmstart.DetectWithContext(gocontext.Background(), npmstart.NewChainPathParser(manifestPathParser{}, npmstart.NewProjectPathParser()), scribe.NewEmitter(bytes.NewBuffer(nil)))
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NODE_PROJECT_PATH")
+			Expect(os.RemoveAll(workingDir)).To(Succeed())
+		})
+
+		it("detects the project named by the manifest", func() {
+			Expect(os.MkdirAll(filepath.Join(workingDir, ".platform"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, ".platform", "app.yaml"), []byte("name: some-app\nroot: from-manifest\n"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "from-manifest", "package.json"), []byte(`{"scripts": {"start": "node server.js"}}`), 0600)).To(Succeed())
+
+			_, err := detect(packit.DetectContext{WorkingDir: workingDir})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("falls back to BP_NODE_PROJECT_PATH without a manifest", func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "from-env", "package.json"), []byte(`{"scripts": {"start": "node server.js"}}`), 0600)).To(Succeed())
+
+			_, err := detect(packit.DetectContext{WorkingDir: workingDir})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+}
@@ -0,0 +1,65 @@
+package npmstart
+
+import (
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// The identifiers of the advisory checks, which name the checks in their
+// warnings and in $BP_NPM_START_DISABLED_CHECKS.
+const (
+	CheckDevServer          = "dev-server"
+	CheckMissingNodeModules = "missing-node-modules"
+	CheckDevDeps            = "dev-deps"
+	CheckNpxNetwork         = "npx-network"
+	CheckBuildStep          = "build-step"
+	CheckGlobalBin          = "global-bin"
+	CheckMissingCommand     = "missing-command"
+	CheckDeprecated         = "deprecated"
+	CheckMissingEntrypoint  = "missing-entrypoint"
+	CheckHealthcheckNpm     = "healthcheck-npm"
+)
+
+// AdvisoryChecks describes every advisory check by its identifier. A check
+// only warns; disabling it never changes the image that is built.
+var AdvisoryChecks = map[string]string{
+	CheckDevServer:          "the start script runs the development server of a static site",
+	CheckMissingNodeModules: "package.json declares dependencies that no node_modules directory contains",
+	CheckDevDeps:            "devDependencies are installed in node_modules",
+	CheckNpxNetwork:         "the start script installs a package with npx at launch",
+	CheckBuildStep:          "the start script is a build step rather than a server",
+	CheckGlobalBin:          "the start script runs a global npm package that is not installed",
+	CheckMissingCommand:     "the start script runs a command that is not installed",
+	CheckDeprecated:         "the app relies on a default behavior that a future version changes",
+	CheckMissingEntrypoint:  "the start script runs a file with node that node cannot resolve",
+	CheckHealthcheckNpm:     "the healthcheck script runs through npm, which slows down every probe",
+}
+
+// checkSet tells which of the AdvisoryChecks are enabled.
+type checkSet struct {
+	suppressed bool
+	disabled   map[string]bool
+}
+
+// newCheckSet resolves the enabled checks from
+// $BP_NPM_START_SUPPRESS_WARNINGS, which disables all of them, and
+// $BP_NPM_START_DISABLED_CHECKS, a comma-separated list of the identifiers
+// of the checks to disable.
+func newCheckSet(config Configuration) checkSet {
+	checks := checkSet{suppressed: config.SuppressWarnings, disabled: map[string]bool{}}
+	for _, id := range config.DisabledChecks {
+		checks.disabled[id] = true
+	}
+
+	return checks
+}
+
+// enabled reports whether the check of the given identifier runs.
+func (c checkSet) enabled(id string) bool {
+	return !c.suppressed && !c.disabled[id]
+}
+
+// warn logs the first line of a warning of the given check, naming the check
+// so that it can be disabled.
+func warn(logger scribe.Emitter, check, format string, v ...interface{}) {
+	logger.Process("WARNING: "+format+" ["+check+"]", v...)
+}
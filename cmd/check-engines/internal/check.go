@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ExitEnginesMismatch is the exit code with which the helper refuses to start
+// a process whose node version is outside the engines range of the app.
+const ExitEnginesMismatch = 64
+
+// Runtime reports the version of the node that the process will run with.
+type Runtime interface {
+	Version() (string, error)
+}
+
+// NodeRuntime runs "node --version" with the PATH of the process.
+type NodeRuntime struct{}
+
+func (NodeRuntime) Version() (string, error) {
+	output, err := exec.Command("node", "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run node --version: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// MismatchError is returned by Check when the node version is outside the
+// engines range.
+type MismatchError struct {
+	Range   string
+	Version string
+}
+
+func (e MismatchError) Error() string {
+	return fmt.Sprintf("node %s does not satisfy the engines.node range %q of package.json: the run image provides a different node than the app was built with, rebuild the app to pick up a matching node", e.Version, e.Range)
+}
+
+// Check compares the version of the runtime against the engines range. It
+// returns a MismatchError when the range excludes the version, and a warning
+// when the check cannot be made, in which case the process is started.
+func Check(engines string, runtime Runtime) (string, error) {
+	r, err := ParseRange(engines)
+	if err != nil {
+		return fmt.Sprintf("WARNING: not checking the node version: %s", err), nil
+	}
+
+	output, err := runtime.Version()
+	if err != nil {
+		return fmt.Sprintf("WARNING: not checking the node version: %s", err), nil
+	}
+
+	version, err := ParseVersion(output)
+	if err != nil {
+		return fmt.Sprintf("WARNING: not checking the node version: %s", err), nil
+	}
+
+	if !r.Contains(version) {
+		return "", MismatchError{Range: engines, Version: version.String()}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,56 @@
+package internal_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/paketo-buildpacks/npm-start/cmd/check-engines/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+type runtime struct {
+	version string
+	err     error
+}
+
+func (r runtime) Version() (string, error) {
+	return r.version, r.err
+}
+
+func testCheck(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	it("passes when the node version is in the range", func() {
+		warning, err := internal.Check(">=18 <21", runtime{version: "v20.11.0"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warning).To(BeEmpty())
+	})
+
+	it("fails when the range excludes the node version", func() {
+		_, err := internal.Check("^18.12", runtime{version: "v20.11.0"})
+		Expect(err).To(MatchError(`node 20.11.0 does not satisfy the engines.node range "^18.12" of package.json: the run image provides a different node than the app was built with, rebuild the app to pick up a matching node`))
+
+		var mismatch internal.MismatchError
+		Expect(errors.As(err, &mismatch)).To(BeTrue())
+	})
+
+	context("when the check cannot be made", func() {
+		it("warns and passes", func() {
+			for _, test := range []struct {
+				engines string
+				runtime runtime
+				warning string
+			}{
+				{"latest", runtime{version: "v20.11.0"}, `WARNING: not checking the node version: failed to parse range "latest"`},
+				{">=18", runtime{err: errors.New("node: not found")}, "WARNING: not checking the node version: node: not found"},
+				{">=18", runtime{version: "something odd"}, `WARNING: not checking the node version: failed to parse version "something odd"`},
+			} {
+				warning, err := internal.Check(test.engines, test.runtime)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(warning).To(HavePrefix(test.warning))
+			}
+		})
+	})
+}
@@ -0,0 +1,15 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitCheckEngines(t *testing.T) {
+	suite := spec.New("check-engines", spec.Report(report.Terminal{}))
+	suite("Semver", testSemver)
+	suite("Check", testCheck)
+	suite.Run(t)
+}
@@ -0,0 +1,253 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version. Pre-release and build suffixes are ignored,
+// as node releases carry none.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+func (v Version) compare(o Version) int {
+	switch {
+	case v.Major != o.Major:
+		return sign(v.Major - o.Major)
+	case v.Minor != o.Minor:
+		return sign(v.Minor - o.Minor)
+	default:
+		return sign(v.Patch - o.Patch)
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ParseVersion parses a complete version such as the output of
+// "node --version", eg. "v18.17.1".
+func ParseVersion(value string) (Version, error) {
+	version, parts, err := parsePartial(strings.TrimSpace(value))
+	if err != nil {
+		return Version{}, err
+	}
+
+	if parts != 3 {
+		return Version{}, fmt.Errorf("failed to parse version %q: expected <major>.<minor>.<patch>", value)
+	}
+
+	return version, nil
+}
+
+// parsePartial parses a version that may leave out its minor and patch
+// numbers, or give them as "x", "X" or "*". It returns the version with the
+// missing numbers set to 0, and the number of numbers that were given.
+func parsePartial(value string) (Version, int, error) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(value, "="), "v")
+	if i := strings.IndexAny(trimmed, "-+"); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+
+	fields := strings.Split(trimmed, ".")
+	if len(fields) > 3 {
+		return Version{}, 0, fmt.Errorf("failed to parse version %q: too many numbers", value)
+	}
+
+	var numbers [3]int
+	parts := 0
+	for _, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			break
+		}
+
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return Version{}, 0, fmt.Errorf("failed to parse version %q: %q is not a number", value, field)
+		}
+
+		numbers[parts] = n
+		parts++
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, parts, nil
+}
+
+// next returns the lowest version above every version that matches the first
+// parts numbers of v, eg. 1.3.0 for 1.2 and 2.0.0 for 1.
+func next(v Version, parts int) Version {
+	switch parts {
+	case 0:
+		return Version{Major: int(^uint(0) >> 1)}
+	case 1:
+		return Version{Major: v.Major + 1}
+	case 2:
+		return Version{Major: v.Major, Minor: v.Minor + 1}
+	default:
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+}
+
+type comparator struct {
+	operator string
+	version  Version
+}
+
+func (c comparator) matches(v Version) bool {
+	n := v.compare(c.version)
+	switch c.operator {
+	case ">=":
+		return n >= 0
+	case ">":
+		return n > 0
+	case "<=":
+		return n <= 0
+	case "<":
+		return n < 0
+	default:
+		return n == 0
+	}
+}
+
+// Range is a version range in the syntax of the engines field of
+// package.json, eg. ">=16 <20 || ^21.2".
+type Range struct {
+	value string
+
+	// sets are alternatives, each of which matches the versions that match
+	// all of its comparators.
+	sets [][]comparator
+}
+
+func (r Range) String() string {
+	return r.value
+}
+
+var operatorSpace = regexp.MustCompile(`([<>=~^]+)\s+`)
+
+// ParseRange parses a version range. A blank range, "*" and "x" match every
+// version.
+func ParseRange(value string) (Range, error) {
+	r := Range{value: value}
+	for _, alternative := range strings.Split(value, "||") {
+		alternative = strings.TrimSpace(alternative)
+
+		var set []comparator
+		if parts := strings.Split(alternative, " - "); len(parts) == 2 {
+			lower, _, err := parsePartial(strings.TrimSpace(parts[0]))
+			if err != nil {
+				return Range{}, fmt.Errorf("failed to parse range %q: %w", value, err)
+			}
+
+			upper, n, err := parsePartial(strings.TrimSpace(parts[1]))
+			if err != nil {
+				return Range{}, fmt.Errorf("failed to parse range %q: %w", value, err)
+			}
+
+			set = append(set, comparator{">=", lower})
+			if n == 3 {
+				set = append(set, comparator{"<=", upper})
+			} else {
+				set = append(set, comparator{"<", next(upper, n)})
+			}
+		} else {
+			for _, field := range strings.Fields(operatorSpace.ReplaceAllString(alternative, "$1")) {
+				comparators, err := desugar(field)
+				if err != nil {
+					return Range{}, fmt.Errorf("failed to parse range %q: %w", value, err)
+				}
+				set = append(set, comparators...)
+			}
+		}
+
+		r.sets = append(r.sets, set)
+	}
+
+	return r, nil
+}
+
+// desugar turns a single comparator of the range syntax into the plain
+// comparators that it stands for.
+func desugar(field string) ([]comparator, error) {
+	rest := strings.TrimLeft(field, "<>=~^")
+	operator := field[:len(field)-len(rest)]
+	if operator == "=" {
+		operator = ""
+	}
+
+	version, parts, err := parsePartial(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operator {
+	case ">=":
+		return []comparator{{">=", version}}, nil
+	case ">":
+		if parts < 3 {
+			return []comparator{{">=", next(version, parts)}}, nil
+		}
+		return []comparator{{">", version}}, nil
+	case "<":
+		return []comparator{{"<", version}}, nil
+	case "<=":
+		if parts < 3 {
+			return []comparator{{"<", next(version, parts)}}, nil
+		}
+		return []comparator{{"<=", version}}, nil
+	case "~":
+		if parts < 2 {
+			return []comparator{{">=", version}, {"<", next(version, 1)}}, nil
+		}
+		return []comparator{{">=", version}, {"<", next(version, 2)}}, nil
+	case "^":
+		switch {
+		case version.Major > 0 || parts == 1:
+			return []comparator{{">=", version}, {"<", next(version, 1)}}, nil
+		case version.Minor > 0 || parts == 2:
+			return []comparator{{">=", version}, {"<", next(version, 2)}}, nil
+		default:
+			return []comparator{{">=", version}, {"<", next(version, 3)}}, nil
+		}
+	case "":
+		if parts == 3 {
+			return []comparator{{"=", version}}, nil
+		}
+		return []comparator{{">=", version}, {"<", next(version, parts)}}, nil
+	default:
+		return nil, fmt.Errorf("unknown operator %q", operator)
+	}
+}
+
+// Contains reports whether the version matches the range.
+func (r Range) Contains(v Version) bool {
+	for _, set := range r.sets {
+		matches := true
+		for _, c := range set {
+			if !c.matches(v) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
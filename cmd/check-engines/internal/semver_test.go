@@ -0,0 +1,99 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/npm-start/cmd/check-engines/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSemver(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseVersion", func() {
+		it("parses the output of node --version", func() {
+			version, err := internal.ParseVersion("v18.17.1\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal(internal.Version{Major: 18, Minor: 17, Patch: 1}))
+		})
+
+		it("ignores pre-release suffixes", func() {
+			version, err := internal.ParseVersion("v21.0.0-nightly20230801")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version.String()).To(Equal("21.0.0"))
+		})
+
+		it("rejects incomplete versions", func() {
+			_, err := internal.ParseVersion("v18")
+			Expect(err).To(MatchError(`failed to parse version "v18": expected <major>.<minor>.<patch>`))
+		})
+	})
+
+	context("Range", func() {
+		it("matches the versions in the range", func() {
+			for _, test := range []struct {
+				r        string
+				version  string
+				contains bool
+			}{
+				{"", "4.0.0", true},
+				{"*", "22.1.0", true},
+				{"18.x", "18.19.0", true},
+				{"18.x", "19.0.0", false},
+				{"18", "18.0.0", true},
+				{"18", "17.9.9", false},
+				{"18.17.1", "18.17.1", true},
+				{"=18.17.1", "18.17.2", false},
+				{"v18.17", "18.17.9", true},
+				{">=18", "18.0.0", true},
+				{">=18", "17.9.9", false},
+				{">= 16.14.0", "16.14.0", true},
+				{">16", "16.9.0", false},
+				{">16", "17.0.0", true},
+				{">16.0.0", "16.0.1", true},
+				{"<20", "19.9.9", true},
+				{"<20", "20.0.0", false},
+				{"<=20", "20.9.0", true},
+				{"<=20", "21.0.0", false},
+				{"<=20.1.0", "20.1.1", false},
+				{">=16 <20", "18.0.0", true},
+				{">=16 <20", "20.1.0", false},
+				{"^18.2", "18.19.0", true},
+				{"^18.2", "18.1.0", false},
+				{"^18.2", "19.0.0", false},
+				{"^0.2.3", "0.2.9", true},
+				{"^0.2.3", "0.3.0", false},
+				{"^0.0.3", "0.0.4", false},
+				{"^0", "0.9.0", true},
+				{"~18.17", "18.17.5", true},
+				{"~18.17", "18.18.0", false},
+				{"~18", "18.20.0", true},
+				{"16 - 18", "18.20.0", true},
+				{"16 - 18", "19.0.0", false},
+				{"16.1 - 18.2.0", "16.0.9", false},
+				{"16.1 - 18.2.0", "18.2.0", true},
+				{"16.1 - 18.2.0", "18.2.1", false},
+				{"^16.14 || ^18.12 || >=20", "17.0.0", false},
+				{"^16.14 || ^18.12 || >=20", "18.12.0", true},
+				{"^16.14 || ^18.12 || >=20", "22.0.0", true},
+			} {
+				r, err := internal.ParseRange(test.r)
+				Expect(err).NotTo(HaveOccurred(), test.r)
+
+				version, err := internal.ParseVersion(test.version)
+				Expect(err).NotTo(HaveOccurred(), test.version)
+
+				Expect(r.Contains(version)).To(Equal(test.contains), "%s in %q", test.version, test.r)
+			}
+		})
+
+		it("rejects malformed ranges", func() {
+			for _, r := range []string{"latest", ">=18.a", "1.2.3.4", "~>1.2"} {
+				_, err := internal.ParseRange(r)
+				Expect(err).To(HaveOccurred(), r)
+			}
+		})
+	})
+}
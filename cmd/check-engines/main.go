@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/paketo-buildpacks/npm-start/cmd/check-engines/internal"
+)
+
+// main is run by the lifecycle as an exec.d helper ahead of every process.
+// It refuses to start the process when the node of the run image is outside
+// the engines.node range that the build recorded in
+// BPL_NPM_START_ENGINES_NODE.
+func main() {
+	engines := os.Getenv("BPL_NPM_START_ENGINES_NODE")
+	if engines == "" {
+		return
+	}
+
+	warning, err := internal.Check(engines, internal.NodeRuntime{})
+	if warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		var mismatch internal.MismatchError
+		if errors.As(err, &mismatch) {
+			os.Exit(internal.ExitEnginesMismatch)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,14 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitMaintenanceServer(t *testing.T) {
+	suite := spec.New("maintenance-server", spec.Report(report.Terminal{}))
+	suite("Server", testServer)
+	suite.Run(t)
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryAfter is the number of seconds clients are asked to wait before
+// retrying a request while the maintenance page is being served.
+const RetryAfter = "120"
+
+// DefaultPage is served when no custom maintenance page has been configured.
+const DefaultPage = `<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>Service Unavailable</title>
+  </head>
+  <body>
+    <h1>Service Unavailable</h1>
+    <p>This application is undergoing maintenance. Please try again later.</p>
+  </body>
+</html>
+`
+
+// NewHandler returns an http.Handler that responds to every request with a
+// 503 Service Unavailable status and the given page as its body.
+func NewHandler(page []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Retry-After", RetryAfter)
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusServiceUnavailable)
+
+		if r.Method != http.MethodHead {
+			_, _ = w.Write(page)
+		}
+	})
+}
+
+// Serve accepts connections on the given listener until the context is
+// cancelled, at which point in-flight requests are given the shutdown timeout
+// to complete before the server is closed.
+func Serve(ctx context.Context, listener net.Listener, handler http.Handler, shutdownTimeout time.Duration) error {
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+
+	err = <-errs
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,89 @@
+package internal_test
+
+import (
+	gocontext "context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/npm-start/cmd/maintenance-server/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testServer(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect     = NewWithT(t).Expect
+		Eventually = NewWithT(t).Eventually
+	)
+
+	context("NewHandler", func() {
+		it("responds with a 503 and a Retry-After header", func() {
+			recorder := httptest.NewRecorder()
+			internal.NewHandler([]byte("down for maintenance")).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/some/path", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Header().Get("Retry-After")).To(Equal(internal.RetryAfter))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/html; charset=utf-8"))
+			Expect(recorder.Body.String()).To(Equal("down for maintenance"))
+		})
+
+		it("omits the body for HEAD requests", func() {
+			recorder := httptest.NewRecorder()
+			internal.NewHandler([]byte("down for maintenance")).ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, "/", nil))
+
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Body.String()).To(BeEmpty())
+		})
+	})
+
+	context("Serve", func() {
+		var (
+			listener net.Listener
+			ctx      gocontext.Context
+			cancel   gocontext.CancelFunc
+			errs     chan error
+		)
+
+		it.Before(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel = gocontext.WithCancel(gocontext.Background())
+
+			errs = make(chan error, 1)
+			go func() {
+				errs <- internal.Serve(ctx, listener, internal.NewHandler([]byte(internal.DefaultPage)), time.Second)
+			}()
+		})
+
+		it.After(func() {
+			cancel()
+		})
+
+		it("serves the page until the context is cancelled", func() {
+			response, err := http.Get("http://" + listener.Addr().String())
+			Expect(err).NotTo(HaveOccurred())
+			defer response.Body.Close()
+
+			Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(response.Header.Get("Retry-After")).To(Equal(internal.RetryAfter))
+
+			content, err := io.ReadAll(response.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("Service Unavailable"))
+
+			cancel()
+
+			Eventually(errs).Should(Receive(BeNil()))
+
+			_, err = http.Get("http://" + listener.Addr().String())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/paketo-buildpacks/npm-start/cmd/maintenance-server/internal"
+)
+
+func main() {
+	var pagePath string
+	flag.StringVar(&pagePath, "page", "", "path to an HTML page to serve instead of the default")
+	flag.Parse()
+
+	err := run(pagePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(pagePath string) error {
+	page := []byte(internal.DefaultPage)
+	if pagePath != "" {
+		var err error
+		page, err = os.ReadFile(pagePath)
+		if err != nil {
+			return fmt.Errorf("failed to read maintenance page: %w", err)
+		}
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("", port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", port, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	fmt.Printf("Serving maintenance page on port %s\n", port)
+
+	return internal.Serve(ctx, listener, internal.NewHandler(page), 10*time.Second)
+}
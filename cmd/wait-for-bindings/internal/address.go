@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+)
+
+// DefaultPorts maps URI schemes to the port a service listens on when the
+// binding URI does not include one.
+var DefaultPorts = map[string]string{
+	"amqp":       "5672",
+	"amqps":      "5671",
+	"http":       "80",
+	"https":      "443",
+	"mongodb":    "27017",
+	"mysql":      "3306",
+	"postgres":   "5432",
+	"postgresql": "5432",
+	"redis":      "6379",
+	"rediss":     "6379",
+}
+
+// Address returns the host:port address of the service described by the
+// binding. It reads the host and port entries, falling back to the uri entry
+// for whichever of them is missing.
+func Address(binding servicebindings.Binding) (string, error) {
+	host, err := readEntry(binding, "host")
+	if err != nil {
+		return "", err
+	}
+
+	port, err := readEntry(binding, "port")
+	if err != nil {
+		return "", err
+	}
+
+	if host == "" || port == "" {
+		uri, err := readEntry(binding, "uri")
+		if err != nil {
+			return "", err
+		}
+
+		if uri != "" {
+			u, err := url.Parse(uri)
+			if err != nil {
+				return "", fmt.Errorf("failed to parse uri of binding %q: %w", binding.Name, err)
+			}
+
+			if host == "" {
+				host = u.Hostname()
+			}
+
+			if port == "" {
+				port = u.Port()
+			}
+
+			if port == "" {
+				port = DefaultPorts[strings.ToLower(u.Scheme)]
+			}
+		}
+	}
+
+	if host == "" {
+		return "", fmt.Errorf("binding %q has no host: expected a host or uri entry", binding.Name)
+	}
+
+	if port == "" {
+		return "", fmt.Errorf("binding %q has no port: expected a port entry or a uri with a port", binding.Name)
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+func readEntry(binding servicebindings.Binding, name string) (string, error) {
+	entry, ok := binding.Entries[name]
+	if !ok {
+		return "", nil
+	}
+
+	value, err := entry.ReadString()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s of binding %q: %w", name, binding.Name, err)
+	}
+
+	return strings.TrimSpace(value), nil
+}
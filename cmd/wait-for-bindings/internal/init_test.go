@@ -0,0 +1,15 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitWaitForBindings(t *testing.T) {
+	suite := spec.New("wait-for-bindings", spec.Report(report.Terminal{}))
+	suite("Targets", testTargets)
+	suite("Wait", testWait)
+	suite.Run(t)
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+)
+
+// Target is a bound service to wait for.
+type Target struct {
+	Type    string
+	Name    string
+	Address string
+}
+
+// BindingResolver looks up service bindings by type. It is satisfied by
+// servicebindings.Resolver.
+type BindingResolver interface {
+	Resolve(typ, provider, platformDir string) ([]servicebindings.Binding, error)
+}
+
+// ParseTypes splits a comma-separated list of binding types, dropping blank
+// entries.
+func ParseTypes(value string) []string {
+	var types []string
+	for _, typ := range strings.Split(value, ",") {
+		typ = strings.TrimSpace(typ)
+		if typ != "" {
+			types = append(types, typ)
+		}
+	}
+
+	return types
+}
+
+// Targets resolves the bindings of each of the given types into the
+// addresses to wait for. Every type must have at least one binding; all of
+// them are checked before any service is polled so that a missing binding
+// fails right away.
+func Targets(resolver BindingResolver, types []string) ([]Target, error) {
+	var targets []Target
+	for _, typ := range types {
+		bindings, err := resolver.Resolve(typ, "", "")
+		if err != nil {
+			return nil, err
+		}
+
+		if len(bindings) == 0 {
+			return nil, fmt.Errorf("no binding of type %q found: BPL_NPM_START_WAIT_FOR_BINDINGS lists it, but SERVICE_BINDING_ROOT holds no such binding", typ)
+		}
+
+		for _, binding := range bindings {
+			address, err := Address(binding)
+			if err != nil {
+				return nil, err
+			}
+
+			targets = append(targets, Target{
+				Type:    typ,
+				Name:    binding.Name,
+				Address: address,
+			})
+		}
+	}
+
+	return targets, nil
+}
@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Wait polls the address until it accepts a TCP connection, retrying every
+// interval. It gives up with an error once timeout has elapsed.
+func Wait(ctx context.Context, address string, timeout, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	for {
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err == nil {
+			return conn.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s was not reachable after %s: %w", address, timeout, err)
+		case <-time.After(interval):
+		}
+	}
+}
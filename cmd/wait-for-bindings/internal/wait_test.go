@@ -0,0 +1,86 @@
+package internal_test
+
+import (
+	gocontext "context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/npm-start/cmd/wait-for-bindings/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testWait(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("when the service accepts connections", func() {
+		var listener net.Listener
+
+		it.Before(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(listener.Close()).To(Succeed())
+		})
+
+		it("returns", func() {
+			Expect(internal.Wait(gocontext.Background(), listener.Addr().String(), time.Second, 10*time.Millisecond)).To(Succeed())
+		})
+	})
+
+	context("when the service starts listening while it is polled", func() {
+		var (
+			address  string
+			listener chan net.Listener
+		)
+
+		it.Before(func() {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			address = l.Addr().String()
+			Expect(l.Close()).To(Succeed())
+
+			listener = make(chan net.Listener, 1)
+			time.AfterFunc(50*time.Millisecond, func() {
+				l, err := net.Listen("tcp", address)
+				if err == nil {
+					listener <- l
+				}
+				close(listener)
+			})
+		})
+
+		it.After(func() {
+			for l := range listener {
+				Expect(l.Close()).To(Succeed())
+			}
+		})
+
+		it("returns once it is reachable", func() {
+			Expect(internal.Wait(gocontext.Background(), address, 5*time.Second, 10*time.Millisecond)).To(Succeed())
+		})
+	})
+
+	context("when the service never becomes reachable", func() {
+		var address string
+
+		it.Before(func() {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			address = l.Addr().String()
+			Expect(l.Close()).To(Succeed())
+		})
+
+		it("returns an error after the timeout", func() {
+			start := time.Now()
+			err := internal.Wait(gocontext.Background(), address, 100*time.Millisecond, 10*time.Millisecond)
+			Expect(err).To(MatchError(ContainSubstring(address + " was not reachable after 100ms")))
+			Expect(time.Since(start)).To(BeNumerically(">=", 100*time.Millisecond))
+		})
+	})
+}
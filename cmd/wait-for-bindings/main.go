@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/paketo-buildpacks/npm-start/cmd/wait-for-bindings/internal"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+)
+
+// DefaultTimeout is how long each bound service is polled for when
+// BPL_NPM_START_WAIT_FOR_BINDINGS_TIMEOUT is not set.
+const DefaultTimeout = 60 * time.Second
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	types := internal.ParseTypes(os.Getenv("BPL_NPM_START_WAIT_FOR_BINDINGS"))
+	if len(types) == 0 {
+		return nil
+	}
+
+	timeout := DefaultTimeout
+	if value, ok := os.LookupEnv("BPL_NPM_START_WAIT_FOR_BINDINGS_TIMEOUT"); ok {
+		var err error
+		timeout, err = time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse BPL_NPM_START_WAIT_FOR_BINDINGS_TIMEOUT value %s: %w", value, err)
+		}
+	}
+
+	targets, err := internal.Targets(servicebindings.NewResolver(), types)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		fmt.Printf("Waiting for %s binding %q at %s\n", target.Type, target.Name, target.Address)
+
+		err = internal.Wait(context.Background(), target.Address, timeout, time.Second)
+		if err != nil {
+			return fmt.Errorf("%s binding %q: %w", target.Type, target.Name, err)
+		}
+	}
+
+	return nil
+}
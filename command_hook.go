@@ -0,0 +1,99 @@
+package npmstart
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+//go:generate faux --interface Executable --output fakes/executable.go
+type Executable interface {
+	Execute(execution pexec.Execution) error
+}
+
+// HookCommand is the JSON document exchanged with a command hook.
+type HookCommand struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+}
+
+// applyCommandHook runs the Node.js script at hook with the path of a JSON
+// file holding the computed command as its only argument, and returns the
+// command that the script prints to stdout. pexec does not expose the
+// standard input of the process, which is why the command is passed as a
+// file.
+func applyCommandHook(node Executable, logger scribe.Emitter, excerpts excerpter, hook, projectPath, command string, args []string) (string, []string, error) {
+	before := HookCommand{Command: command, Args: args}
+
+	input, err := json.Marshal(before)
+	if err != nil {
+		return "", nil, err
+	}
+
+	file, err := os.CreateTemp("", "command-hook-*.json")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create command hook input: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(input)
+	if err == nil {
+		err = file.Close()
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to write command hook input: %w", err)
+	}
+
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	err = node.Execute(pexec.Execution{
+		Args:   []string{hook, file.Name()},
+		Dir:    projectPath,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("command hook %s failed: %w\n%s", hook, err, stderr)
+	}
+
+	var after HookCommand
+	err = json.Unmarshal(stdout.Bytes(), &after)
+	if err != nil {
+		return "", nil, fmt.Errorf("command hook %s printed invalid JSON: %w\n%s", hook, err, stderr)
+	}
+
+	err = validateHookCommand(after)
+	if err != nil {
+		return "", nil, fmt.Errorf("command hook %s printed an invalid command: %w\n%s", hook, err, stderr)
+	}
+
+	logger.Process("Applying command hook %s", filepath.Base(hook))
+	logger.Subprocess("- %s", excerpts.excerpt(strings.Join(append([]string{before.Command}, before.Args...), " ")))
+	logger.Subprocess("+ %s", excerpts.excerpt(strings.Join(append([]string{after.Command}, after.Args...), " ")))
+	logger.Break()
+
+	return after.Command, after.Args, nil
+}
+
+func validateHookCommand(command HookCommand) error {
+	if command.Command == "" {
+		return fmt.Errorf("command is empty")
+	}
+
+	for _, value := range append([]string{command.Command}, command.Args...) {
+		for _, r := range value {
+			if unicode.IsControl(r) {
+				return fmt.Errorf("%q contains a control character", value)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package npmstart
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// Configuration is the build configuration of the buildpack: the BP_*
+// variables that Detect and Build read. The env tag of each field names its
+// variable and the default tag holds the value that an unset variable takes.
+// The enum tag lists the values that a string field accepts.
+//
+// The variables whose values have a syntax of their own, such as
+// BP_NPM_START_JOBS, are bound as strings and parsed where the package.json
+// that they refer to is known. BP_NODE_PROJECT_PATH belongs to the
+// PathParser and the CNB_* variables to the platform, so neither is bound
+// here.
+type Configuration struct {
+	ArgsPolicy       string `env:"BP_NPM_START_ARGS_POLICY" default:"" enum:"overridable,locked"`
+	RunPrepare       bool   `env:"BP_NPM_START_RUN_PREPARE" default:"false"`
+	ForwardPort      bool   `env:"BP_NPM_START_FORWARD_PORT" default:"false"`
+	LiveReload       bool   `env:"BP_LIVE_RELOAD_ENABLED" default:"false"`
+	Maintenance      bool   `env:"BP_NPM_START_MAINTENANCE" default:"false"`
+	NoDefaultProcess bool   `env:"BP_NPM_START_NO_DEFAULT_PROCESS" default:"false"`
+	WorkloadType     string `env:"BP_NPM_START_WORKLOAD_TYPE" default:"web" enum:"web,worker"`
+	Explain          bool   `env:"BP_NPM_START_EXPLAIN" default:"false"`
+	Silent           bool   `env:"BP_NPM_START_SILENT" default:"false"`
+	MinimalLaunch    bool   `env:"BP_NPM_START_MINIMAL_LAUNCH" default:"false"`
+	DebugProcesses   bool   `env:"BP_NPM_START_DEBUG_PROCESSES" default:"false"`
+	Shell            string `env:"BP_NPM_START_SHELL" default:"sh" enum:"sh,bash"`
+	EnginesStrict    bool   `env:"BP_NPM_START_ENGINES_STRICT" default:"false"`
+	TraceWrappers    bool   `env:"BP_NPM_START_TRACE_WRAPPERS" default:"false"`
+	Slim             bool   `env:"BP_NPM_START_SLIM" default:"false"`
+	ExcerptLength    int    `env:"BP_NPM_START_EXCERPT_LENGTH" default:"200"`
+	Strict           bool   `env:"BP_NPM_START_STRICT" default:"false"`
+	RejectDevServers bool   `env:"BP_NPM_START_REJECT_DEV_SERVERS" default:"false"`
+	Force            bool   `env:"BP_NPM_START_FORCE" default:"false"`
+
+	SuppressWarnings bool     `env:"BP_NPM_START_SUPPRESS_WARNINGS" default:"false"`
+	DisabledChecks   []string `env:"BP_NPM_START_DISABLED_CHECKS" default:""`
+
+	LiveReloadProvider        string `env:"BP_LIVE_RELOAD_PROVIDER" default:"watchexec"`
+	LiveReloadSignal          string `env:"BP_LIVE_RELOAD_SIGNAL" default:""`
+	LiveReloadCommandTemplate string `env:"BP_LIVE_RELOAD_COMMAND_TEMPLATE" default:""`
+
+	CommandHook       string `env:"BP_NPM_START_COMMAND_HOOK" default:""`
+	Filter            string `env:"BP_NPM_START_FILTER" default:""`
+	Descriptor        string `env:"BP_NPM_START_DESCRIPTOR" default:""`
+	DescriptorApp     string `env:"BP_NPM_START_DESCRIPTOR_APP" default:""`
+	MaintenancePage   string `env:"BP_NPM_START_MAINTENANCE_PAGE" default:""`
+	HealthcheckScript string `env:"BP_NPM_START_HEALTHCHECK_SCRIPT" default:""`
+	Labels            string `env:"BP_NPM_START_LABELS" default:""`
+	Revision          string `env:"BP_NPM_START_REVISION" default:""`
+	Jobs              string `env:"BP_NPM_START_JOBS" default:""`
+	ProcessEnv        string `env:"BP_NPM_START_PROCESS_ENV" default:""`
+	DeprecationsFile  string `env:"BP_NPM_START_DEPRECATIONS_FILE" default:""`
+}
+
+// Warning is a problem with the build configuration that does not fail the
+// build, such as a value that is valid but most likely misspelt.
+type Warning struct {
+	// Message is the first line of the warning.
+	Message string
+
+	// Detail explains the warning on the line that follows.
+	Detail string
+}
+
+// ParseConfiguration binds the fields of a Configuration to the variables of
+// env. Every variable is parsed, so that the error reports all of the invalid
+// ones at once; their fields keep their defaults. Booleans are parsed like
+// strconv.ParseBool; the other variables take their default when they are set
+// to an empty value too.
+func ParseConfiguration(env Environment) (Configuration, []Warning, error) {
+	var config Configuration
+
+	value := reflect.ValueOf(&config).Elem()
+
+	var problems []error
+	for i := 0; i < value.NumField(); i++ {
+		problems = append(problems, bindField(env, value.Type().Field(i), value.Field(i)))
+	}
+
+	return config, config.warnings(), JoinErrors("the build configuration", problems...)
+}
+
+// bindField sets value to the default of field, then to the value of its
+// variable when that is set.
+func bindField(env Environment, field reflect.StructField, value reflect.Value) error {
+	name := field.Tag.Get("env")
+
+	parsed, err := parseField(field, field.Tag.Get("default"))
+	if err != nil {
+		panic(fmt.Sprintf("the default of the %s field is invalid: %s", field.Name, err))
+	}
+	value.Set(parsed)
+
+	raw, ok := env(name)
+	if !ok || (raw == "" && field.Type.Kind() != reflect.Bool) {
+		return nil
+	}
+
+	parsed, err = parseField(field, raw)
+	if err != nil {
+		return classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse %s value %s: %w", name, raw, err))
+	}
+	value.Set(parsed)
+
+	return nil
+}
+
+// parseField parses raw as a value of the type of field.
+func parseField(field reflect.StructField, raw string) (reflect.Value, error) {
+	switch field.Type.Kind() {
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(parsed), nil
+
+	case reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			return reflect.Value{}, fmt.Errorf("expected a positive integer")
+		}
+		return reflect.ValueOf(parsed), nil
+
+	case reflect.String:
+		enum, ok := field.Tag.Lookup("enum")
+		if !ok || raw == "" {
+			return reflect.ValueOf(raw), nil
+		}
+
+		values := strings.Split(enum, ",")
+		for _, value := range values {
+			if raw == value {
+				return reflect.ValueOf(raw), nil
+			}
+		}
+		return reflect.Value{}, fmt.Errorf("expected %s", formatAlternatives(values))
+
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return reflect.ValueOf(items), nil
+
+	default:
+		panic(fmt.Sprintf("the %s field has the unsupported type %s", field.Name, field.Type))
+	}
+}
+
+// formatAlternatives lists values as in "a, b or c".
+func formatAlternatives(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+
+	return strings.Join(values[:len(values)-1], ", ") + " or " + values[len(values)-1]
+}
+
+// warnings returns the warnings about the values of the configuration.
+func (c Configuration) warnings() []Warning {
+	var warnings []Warning
+
+	var unknown []string
+	seen := map[string]bool{}
+	for _, id := range c.DisabledChecks {
+		if _, ok := AdvisoryChecks[id]; !ok && !seen[id] {
+			unknown = append(unknown, id)
+		}
+		seen[id] = true
+	}
+
+	if len(unknown) > 0 {
+		var ids []string
+		for id := range AdvisoryChecks {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		warnings = append(warnings, Warning{
+			Message: fmt.Sprintf("BP_NPM_START_DISABLED_CHECKS names unknown checks: %s", strings.Join(unknown, ", ")),
+			Detail:  fmt.Sprintf("The known checks are %s.", strings.Join(ids, ", ")),
+		})
+	}
+
+	return warnings
+}
+
+// logWarnings logs the warnings about the build configuration.
+func logWarnings(logger scribe.Emitter, warnings []Warning) {
+	for _, warning := range warnings {
+		logger.Process("WARNING: %s", warning.Message)
+		logger.Subprocess(warning.Detail)
+		logger.Break()
+	}
+}
@@ -0,0 +1,196 @@
+package npmstart_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testConfiguration(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		environment func(variables map[string]string) npmstart.Environment
+	)
+
+	it.Before(func() {
+		environment = func(variables map[string]string) npmstart.Environment {
+			return func(name string) (string, bool) {
+				value, ok := variables[name]
+				return value, ok
+			}
+		}
+	})
+
+	it("binds every field to a variable and a default", func() {
+		names := map[string]string{}
+
+		configType := reflect.TypeOf(npmstart.Configuration{})
+		for i := 0; i < configType.NumField(); i++ {
+			field := configType.Field(i)
+
+			name, ok := field.Tag.Lookup("env")
+			Expect(ok).To(BeTrue(), field.Name)
+			Expect(name).To(HavePrefix("BP_"), field.Name)
+			Expect(names).NotTo(HaveKey(name), field.Name)
+			names[name] = field.Name
+
+			_, ok = field.Tag.Lookup("default")
+			Expect(ok).To(BeTrue(), field.Name)
+		}
+	})
+
+	it("holds the defaults when no variable is set", func() {
+		config, warnings, err := npmstart.ParseConfiguration(environment(nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+
+		Expect(config.LiveReload).To(BeFalse())
+		Expect(config.WorkloadType).To(Equal(npmstart.WorkloadWeb))
+		Expect(config.Shell).To(Equal("sh"))
+		Expect(config.ArgsPolicy).To(BeEmpty())
+		Expect(config.LiveReloadProvider).To(Equal(npmstart.Watchexec))
+		Expect(config.ExcerptLength).To(Equal(npmstart.DefaultExcerptLength))
+		Expect(config.DisabledChecks).To(BeEmpty())
+	})
+
+	context("booleans", func() {
+		it("parses them like strconv.ParseBool", func() {
+			config, _, err := npmstart.ParseConfiguration(environment(map[string]string{
+				"BP_NPM_START_MAINTENANCE": "true",
+				"BP_NPM_START_SILENT":      "1",
+				"BP_NPM_START_SLIM":        "false",
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Maintenance).To(BeTrue())
+			Expect(config.Silent).To(BeTrue())
+			Expect(config.Slim).To(BeFalse())
+		})
+
+		it("rejects other values, including an empty one", func() {
+			_, _, err := npmstart.ParseConfiguration(environment(map[string]string{
+				"BP_NPM_START_MAINTENANCE": "sometimes",
+			}))
+			Expect(err).To(MatchError(`failed to parse BP_NPM_START_MAINTENANCE value sometimes: strconv.ParseBool: parsing "sometimes": invalid syntax`))
+			Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+
+			_, _, err = npmstart.ParseConfiguration(environment(map[string]string{
+				"BP_NPM_START_SLIM": "",
+			}))
+			Expect(err).To(MatchError(ContainSubstring("failed to parse BP_NPM_START_SLIM value : ")))
+		})
+	})
+
+	context("integers", func() {
+		it("parses positive integers", func() {
+			config, _, err := npmstart.ParseConfiguration(environment(map[string]string{
+				"BP_NPM_START_EXCERPT_LENGTH": "80",
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.ExcerptLength).To(Equal(80))
+		})
+
+		it("takes the default for an empty value", func() {
+			config, _, err := npmstart.ParseConfiguration(environment(map[string]string{
+				"BP_NPM_START_EXCERPT_LENGTH": "",
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.ExcerptLength).To(Equal(npmstart.DefaultExcerptLength))
+		})
+
+		it("rejects other values and keeps the default", func() {
+			for _, value := range []string{"0", "-3", "many"} {
+				config, _, err := npmstart.ParseConfiguration(environment(map[string]string{
+					"BP_NPM_START_EXCERPT_LENGTH": value,
+				}))
+				Expect(err).To(MatchError("failed to parse BP_NPM_START_EXCERPT_LENGTH value "+value+": expected a positive integer"), value)
+				Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+				Expect(config.ExcerptLength).To(Equal(npmstart.DefaultExcerptLength))
+			}
+		})
+	})
+
+	context("enums", func() {
+		it("accepts the listed values", func() {
+			config, _, err := npmstart.ParseConfiguration(environment(map[string]string{
+				"BP_NPM_START_WORKLOAD_TYPE": "worker",
+				"BP_NPM_START_SHELL":         "bash",
+				"BP_NPM_START_ARGS_POLICY":   "locked",
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.WorkloadType).To(Equal(npmstart.WorkloadWorker))
+			Expect(config.Shell).To(Equal("bash"))
+			Expect(config.ArgsPolicy).To(Equal(npmstart.ArgsPolicyLocked))
+		})
+
+		it("takes the default for an empty value", func() {
+			config, _, err := npmstart.ParseConfiguration(environment(map[string]string{
+				"BP_NPM_START_WORKLOAD_TYPE": "",
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.WorkloadType).To(Equal(npmstart.WorkloadWeb))
+		})
+
+		it("rejects other values, listing the accepted ones", func() {
+			_, _, err := npmstart.ParseConfiguration(environment(map[string]string{
+				"BP_NPM_START_WORKLOAD_TYPE": "cron",
+			}))
+			Expect(err).To(MatchError("failed to parse BP_NPM_START_WORKLOAD_TYPE value cron: expected web or worker"))
+			Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+		})
+	})
+
+	context("lists", func() {
+		it("splits them on commas, leaving out blank items", func() {
+			config, _, err := npmstart.ParseConfiguration(environment(map[string]string{
+				"BP_NPM_START_DISABLED_CHECKS": " dev-deps,, npx-network ,",
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.DisabledChecks).To(Equal([]string{npmstart.CheckDevDeps, npmstart.CheckNpxNetwork}))
+		})
+	})
+
+	context("strings", func() {
+		it("takes them as they are", func() {
+			config, _, err := npmstart.ParseConfiguration(environment(map[string]string{
+				"BP_NPM_START_JOBS":       "migrate:db:migrate",
+				"BP_LIVE_RELOAD_PROVIDER": "nodemon",
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Jobs).To(Equal("migrate:db:migrate"))
+			Expect(config.LiveReloadProvider).To(Equal("nodemon"))
+		})
+	})
+
+	it("reports every invalid variable at once and parses the others", func() {
+		config, _, err := npmstart.ParseConfiguration(environment(map[string]string{
+			"BP_NPM_START_MAINTENANCE":    "sometimes",
+			"BP_NPM_START_SHELL":          "zsh",
+			"BP_NPM_START_EXCERPT_LENGTH": "0",
+			"BP_NPM_START_SILENT":         "true",
+		}))
+		Expect(err).To(MatchError(HavePrefix("found 3 problems with the build configuration:\n")))
+		Expect(err.Error()).To(ContainSubstring("\n  1. failed to parse BP_NPM_START_MAINTENANCE value sometimes"))
+		Expect(err.Error()).To(ContainSubstring("\n  2. failed to parse BP_NPM_START_SHELL value zsh: expected sh or bash"))
+		Expect(err.Error()).To(ContainSubstring("\n  3. failed to parse BP_NPM_START_EXCERPT_LENGTH value 0"))
+		Expect(config.Silent).To(BeTrue())
+		Expect(config.Shell).To(Equal("sh"))
+	})
+
+	it("warns once about each disabled check that is unknown", func() {
+		_, warnings, err := npmstart.ParseConfiguration(environment(map[string]string{
+			"BP_NPM_START_DISABLED_CHECKS": "dev-deps,dev-dep,dev-dep,npx",
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0].Message).To(Equal("BP_NPM_START_DISABLED_CHECKS names unknown checks: dev-dep, npx"))
+		Expect(warnings[0].Detail).To(HavePrefix("The known checks are "))
+		Expect(strings.Count(warnings[0].Detail, ", ")).To(Equal(len(npmstart.AdvisoryChecks) - 1))
+	})
+}
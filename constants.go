@@ -5,3 +5,21 @@ const (
 	NodeModules = "node_modules"
 	Npm         = "npm"
 )
+
+// The workload types that can be set with BP_NPM_START_WORKLOAD_TYPE. The
+// type of a worker image is recorded in the WorkloadLabel image label, which
+// platforms read to skip HTTP health checks.
+const (
+	WorkloadWeb    = "web"
+	WorkloadWorker = "worker"
+	WorkloadLabel  = "io.paketo.npm-start.workload"
+)
+
+// The policies that can be set with BP_NPM_START_ARGS_POLICY. Overridable
+// forwards arguments appended at launch to the start command, which needs
+// platform API 0.10 or newer; locked folds the whole start command into the
+// shell script of the process so that nothing can be appended to it.
+const (
+	ArgsPolicyOverridable = "overridable"
+	ArgsPolicyLocked      = "locked"
+)
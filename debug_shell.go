@@ -0,0 +1,31 @@
+package npmstart
+
+import (
+	"fmt"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// DebugShellProcess is the type of the process that BP_NPM_START_DEBUG_PROCESSES
+// adds for debugging the app in the built image.
+const DebugShellProcess = "shell"
+
+// debugShellProcess returns the non-default process that opens an
+// interactive shell in the project path.
+func debugShellProcess(shell, workingDir, projectPath string) packit.Process {
+	if projectPath == workingDir {
+		return packit.Process{
+			Type:    DebugShellProcess,
+			Command: shell,
+			Args:    []string{"-i"},
+			Direct:  true,
+		}
+	}
+
+	return packit.Process{
+		Type:    DebugShellProcess,
+		Command: shell,
+		Args:    []string{"-c", fmt.Sprintf("cd %s && exec %s -i", shellQuote(projectPath), shell)},
+		Direct:  true,
+	}
+}
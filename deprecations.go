@@ -0,0 +1,96 @@
+package npmstart
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// Deprecation is a default behavior of the buildpack that is scheduled to
+// change.
+type Deprecation struct {
+	ID            string `json:"id"`
+	Description   string `json:"description"`
+	Current       string `json:"current"`
+	Future        string `json:"future"`
+	TargetVersion string `json:"target_version"`
+
+	// ReliesOn reports whether an app relies on the current behavior.
+	ReliesOn func(DeprecationInputs) bool `json:"-"`
+}
+
+// DeprecationInputs is what the ReliesOn functions of the Deprecations
+// decide on.
+type DeprecationInputs struct {
+	Package     PackageJson
+	ProjectPath string
+	LiveReload  bool
+}
+
+// Deprecations is the registry of the scheduled changes of default behavior,
+// ordered by target version. A new entry is all it takes for builds to warn
+// the apps that rely on the current behavior and for the change to appear in
+// the BP_NPM_START_DEPRECATIONS_FILE. No change of default behavior is
+// scheduled yet, so it is empty.
+var Deprecations = []Deprecation{}
+
+// DeprecationStatus is a Deprecation as reported in the
+// BP_NPM_START_DEPRECATIONS_FILE.
+type DeprecationStatus struct {
+	Deprecation
+
+	// ReliedOn indicates that the app relies on the current behavior.
+	ReliedOn bool `json:"relied_on"`
+}
+
+// DeprecationReport is the content of the BP_NPM_START_DEPRECATIONS_FILE.
+type DeprecationReport struct {
+	Deprecations []DeprecationStatus `json:"deprecations"`
+}
+
+// reliedOnDeprecations returns the identifiers of the Deprecations whose
+// current behavior the app relies on.
+func reliedOnDeprecations(in DeprecationInputs) map[string]bool {
+	relied := map[string]bool{}
+	for _, deprecation := range Deprecations {
+		if deprecation.ReliesOn(in) {
+			relied[deprecation.ID] = true
+		}
+	}
+
+	return relied
+}
+
+// logDeprecations warns about each of the Deprecations that the app relies
+// on.
+func logDeprecations(logger scribe.Emitter, relied map[string]bool) {
+	for _, deprecation := range Deprecations {
+		if !relied[deprecation.ID] {
+			continue
+		}
+
+		warn(logger, CheckDeprecated, "%s, which changes in version %s (%s)", deprecation.Description, deprecation.TargetVersion, deprecation.ID)
+		logger.Subprocess("Currently %s.", deprecation.Current)
+		logger.Subprocess("From version %s, %s.", deprecation.TargetVersion, deprecation.Future)
+		logger.Break()
+	}
+}
+
+// writeDeprecations writes the DeprecationReport of every one of the
+// Deprecations, marked with whether the app relies on it, to path.
+func writeDeprecations(path string, relied map[string]bool) error {
+	report := DeprecationReport{Deprecations: []DeprecationStatus{}}
+	for _, deprecation := range Deprecations {
+		report.Deprecations = append(report.Deprecations, DeprecationStatus{
+			Deprecation: deprecation,
+			ReliedOn:    relied[deprecation.ID],
+		})
+	}
+
+	return WriteFileAtomic(path, 0644, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	})
+}
@@ -0,0 +1,119 @@
+package npmstart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Descriptor is an application descriptor in the format of a Cloud Foundry
+// manifest.yaml, as named by BP_NPM_START_DESCRIPTOR. Only the fields that
+// npm-start honors are decoded.
+type Descriptor struct {
+	Applications []DescriptorApplication `yaml:"applications"`
+}
+
+// DescriptorApplication is an entry of the applications of a Descriptor.
+type DescriptorApplication struct {
+	Name    string `yaml:"name"`
+	Command string `yaml:"command"`
+}
+
+// ParseDescriptor reads and decodes the application descriptor at path.
+func ParseDescriptor(path string) (Descriptor, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Descriptor{}, fmt.Errorf("failed to read application descriptor: %w", err)
+	}
+
+	var descriptor Descriptor
+	err = yaml.Unmarshal(content, &descriptor)
+	if err != nil {
+		return Descriptor{}, fmt.Errorf("failed to parse application descriptor %s: %w", filepath.Base(path), err)
+	}
+
+	return descriptor, nil
+}
+
+// Select returns the application with the given name. An empty name selects
+// the only application of a descriptor that has just one.
+func (d Descriptor) Select(name string) (DescriptorApplication, error) {
+	var names []string
+	for _, application := range d.Applications {
+		names = append(names, application.Name)
+	}
+
+	if len(d.Applications) == 0 {
+		return DescriptorApplication{}, fmt.Errorf("the descriptor names no applications")
+	}
+
+	if name == "" {
+		if len(d.Applications) > 1 {
+			return DescriptorApplication{}, fmt.Errorf("the descriptor names %d applications, set BP_NPM_START_DESCRIPTOR_APP to one of %s", len(d.Applications), strings.Join(names, ", "))
+		}
+
+		return d.Applications[0], nil
+	}
+
+	for _, application := range d.Applications {
+		if application.Name == name {
+			return application, nil
+		}
+	}
+
+	return DescriptorApplication{}, fmt.Errorf("the descriptor has no application %q, only %s", name, strings.Join(names, ", "))
+}
+
+// ApplyDescriptor replaces the start script of the package.json with the
+// command of the application descriptor named by BP_NPM_START_DESCRIPTOR,
+// relative to the working directory.
+func ApplyDescriptor(pkg *PackageJson, workingDir string) error {
+	config, _, err := ParseConfiguration(processEnvironment)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = applyDescriptor(config, pkg, workingDir)
+	return err
+}
+
+// applyDescriptor is ApplyDescriptor for the given configuration. It returns
+// the path of the descriptor and the application whose command replaced the
+// start script, or an empty path when BP_NPM_START_DESCRIPTOR is not set.
+func applyDescriptor(config Configuration, pkg *PackageJson, workingDir string) (string, DescriptorApplication, error) {
+	path := config.Descriptor
+	name := config.DescriptorApp
+
+	if path == "" {
+		if name != "" {
+			return "", DescriptorApplication{}, classify(ErrInvalidConfiguration, fmt.Errorf("BP_NPM_START_DESCRIPTOR_APP is set, but BP_NPM_START_DESCRIPTOR is not"))
+		}
+
+		return "", DescriptorApplication{}, nil
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, path)
+	}
+
+	descriptor, err := ParseDescriptor(path)
+	if err != nil {
+		return "", DescriptorApplication{}, classify(ErrInvalidConfiguration, err)
+	}
+
+	application, err := descriptor.Select(name)
+	if err != nil {
+		return "", DescriptorApplication{}, classify(ErrInvalidConfiguration, fmt.Errorf("failed to select an application of BP_NPM_START_DESCRIPTOR %s: %w", filepath.Base(path), err))
+	}
+
+	if strings.TrimSpace(application.Command) == "" {
+		return "", DescriptorApplication{}, classify(ErrInvalidConfiguration, fmt.Errorf("application %q of BP_NPM_START_DESCRIPTOR %s has no command", application.Name, filepath.Base(path)))
+	}
+
+	pkg.Scripts.Start = application.Command
+
+	return path, application, nil
+}
@@ -0,0 +1,139 @@
+package npmstart_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDescriptor(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		path       string
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(workingDir, "manifest.yml")
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("ParseDescriptor", func() {
+		it("decodes the applications and ignores the other fields", func() {
+			Expect(os.WriteFile(path, []byte(`---
+applications:
+- name: some-app
+  memory: 256M
+  command: node server.js
+  env:
+    NODE_ENV: production
+- name: other-app
+  command: >-
+    node worker.js
+    --queue jobs
+services:
+- some-service
+`), 0600)).To(Succeed())
+
+			descriptor, err := npmstart.ParseDescriptor(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(descriptor).To(Equal(npmstart.Descriptor{
+				Applications: []npmstart.DescriptorApplication{
+					{Name: "some-app", Command: "node server.js"},
+					{Name: "other-app", Command: "node worker.js --queue jobs"},
+				},
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the file does not exist", func() {
+				it("returns an error", func() {
+					_, err := npmstart.ParseDescriptor(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to read application descriptor")))
+					Expect(err).To(MatchError(os.ErrNotExist))
+				})
+			})
+
+			context("when the file is not valid YAML", func() {
+				it("returns an error", func() {
+					Expect(os.WriteFile(path, []byte("applications: [\n"), 0600)).To(Succeed())
+
+					_, err := npmstart.ParseDescriptor(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse application descriptor manifest.yml")))
+				})
+			})
+
+			context("when applications is not a list", func() {
+				it("returns an error", func() {
+					Expect(os.WriteFile(path, []byte("applications:\n  name: some-app\n"), 0600)).To(Succeed())
+
+					_, err := npmstart.ParseDescriptor(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse application descriptor manifest.yml")))
+				})
+			})
+		})
+	})
+
+	context("Select", func() {
+		var descriptor npmstart.Descriptor
+
+		it.Before(func() {
+			descriptor = npmstart.Descriptor{
+				Applications: []npmstart.DescriptorApplication{
+					{Name: "some-app", Command: "node server.js"},
+					{Name: "other-app", Command: "node worker.js"},
+				},
+			}
+		})
+
+		it("returns the named application", func() {
+			application, err := descriptor.Select("other-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(application).To(Equal(npmstart.DescriptorApplication{Name: "other-app", Command: "node worker.js"}))
+		})
+
+		it("returns the only application when no name is given", func() {
+			descriptor.Applications = descriptor.Applications[:1]
+
+			application, err := descriptor.Select("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(application.Name).To(Equal("some-app"))
+		})
+
+		context("failure cases", func() {
+			context("when no name is given for several applications", func() {
+				it("returns an error", func() {
+					_, err := descriptor.Select("")
+					Expect(err).To(MatchError("the descriptor names 2 applications, set BP_NPM_START_DESCRIPTOR_APP to one of some-app, other-app"))
+				})
+			})
+
+			context("when no application has the name", func() {
+				it("returns an error", func() {
+					_, err := descriptor.Select("missing-app")
+					Expect(err).To(MatchError(`the descriptor has no application "missing-app", only some-app, other-app`))
+				})
+			})
+
+			context("when the descriptor has no applications", func() {
+				it("returns an error", func() {
+					_, err := npmstart.Descriptor{}.Select("")
+					Expect(err).To(MatchError("the descriptor names no applications"))
+				})
+			})
+		})
+	})
+}
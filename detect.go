@@ -1,14 +1,22 @@
 package npmstart
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"os"
-	"path/filepath"
-	"strconv"
 
 	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
 )
 
+// PathParser resolves the project path, the directory holding the
+// package.json of the app, from the working directory given to Detect and
+// Build. It is the extension point for platforms that record the project path
+// elsewhere than in $BP_NODE_PROJECT_PATH: implement Get, and pass the
+// implementation to Detect and Build, alone or combined with the
+// ProjectPathParser in a ChainPathParser. Get returns the working directory
+// itself when the app is at its root.
+//
 //go:generate faux --interface PathParser --output fakes/path_parser.go
 type PathParser interface {
 	Get(path string) (projectPath string, err error)
@@ -17,79 +25,55 @@ type PathParser interface {
 const NoStartScriptError = "no start script in package.json"
 
 func Detect(projectPathParser PathParser) packit.DetectFunc {
+	return DetectWithContext(context.Background(), projectPathParser, scribe.NewEmitter(os.Stdout).WithLevel(os.Getenv("BP_LOG_LEVEL")))
+}
+
+// DetectWithContext is Detect with a context that can cancel the filesystem
+// calls made while resolving and reading the project's package.json.
+func DetectWithContext(ctx context.Context, projectPathParser PathParser, logger scribe.Emitter) packit.DetectFunc {
 	return func(context packit.DetectContext) (packit.DetectResult, error) {
-		projectPath, err := projectPathParser.Get(context.WorkingDir)
+		config, _, err := ParseConfiguration(processEnvironment)
 		if err != nil {
 			return packit.DetectResult{}, err
 		}
 
-		_, err = os.Stat(filepath.Join(projectPath, "package.json"))
+		location, err := LocatePackageJson(ctx, projectPathParser, context.WorkingDir)
 		if err != nil {
-			if os.IsNotExist(err) {
+			if errors.Is(err, ErrMissingPackageJson) {
 				return packit.DetectResult{}, packit.Fail
 			}
-			return packit.DetectResult{}, fmt.Errorf("failed to stat package.json: %w", err)
+			return packit.DetectResult{}, err
 		}
 
-		var pkg *PackageJson
-		if pkg, err = NewPackageJsonFromPath(filepath.Join(projectPath, "package.json")); err != nil {
+		pkg, err := LoadPackageJson(ctx, location)
+		if err != nil {
 			return packit.DetectResult{}, err
 		}
 
-		if !pkg.hasStartCommand() {
-			return packit.DetectResult{}, packit.Fail.WithMessage(NoStartScriptError)
+		traceScripts(ctx, pkg.Scripts)
+
+		_, _, err = applyDescriptor(config, pkg, context.WorkingDir)
+		if err != nil {
+			return packit.DetectResult{}, err
 		}
 
-		requirements := []packit.BuildPlanRequirement{
-			{
-				Name: Node,
-				Metadata: map[string]interface{}{
-					"launch": true,
-				},
-			},
-			{
-				Name: Npm,
-				Metadata: map[string]interface{}{
-					"launch": true,
-				},
-			},
-			{
-				Name: NodeModules,
-				Metadata: map[string]interface{}{
-					"launch": true,
-				},
-			},
+		err = ValidatePackageJson(*pkg)
+		if err != nil {
+			return packit.DetectResult{}, err
 		}
 
-		shouldReload, err := checkLiveReloadEnabled()
+		err = checkCompetingRuntime(config, *pkg, location)
 		if err != nil {
 			return packit.DetectResult{}, err
 		}
 
-		if shouldReload {
-			requirements = append(requirements, packit.BuildPlanRequirement{
-				Name: "watchexec",
-				Metadata: map[string]interface{}{
-					"launch": true,
-				},
-			})
+		err = checkStaticSite(config, newExcerpter(config, os.Environ()), *pkg, location, logger)
+		if err != nil {
+			return packit.DetectResult{}, err
 		}
 
 		return packit.DetectResult{
-			Plan: packit.BuildPlan{
-				Requires: requirements,
-			},
+			Plan: PlanRequirements(config, *pkg, logger),
 		}, nil
 	}
 }
-
-func checkLiveReloadEnabled() (bool, error) {
-	if reload, ok := os.LookupEnv("BP_LIVE_RELOAD_ENABLED"); ok {
-		shouldEnableReload, err := strconv.ParseBool(reload)
-		if err != nil {
-			return false, fmt.Errorf("failed to parse BP_LIVE_RELOAD_ENABLED value %s: %w", reload, err)
-		}
-		return shouldEnableReload, nil
-	}
-	return false, nil
-}
@@ -0,0 +1,290 @@
+package npmstart
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// PackageJsonLocation is the package.json found by LocatePackageJson.
+type PackageJsonLocation struct {
+	// ProjectPath is the directory that holds the package.json.
+	ProjectPath string
+
+	// Path is the path of the package.json.
+	Path string
+}
+
+// LocatePackageJson resolves the project path and checks that it holds a
+// package.json. The returned error matches ErrMissingPackageJson when it does
+// not.
+func LocatePackageJson(ctx context.Context, projectPathParser PathParser, workingDir string) (PackageJsonLocation, error) {
+	var projectPath string
+	err := cancellable(ctx, "resolving the project path", func() error {
+		var err error
+		projectPath, _, _, err = resolveProjectPath(projectPathParser, workingDir)
+		return err
+	})
+	if err != nil {
+		return PackageJsonLocation{}, err
+	}
+
+	location := PackageJsonLocation{
+		ProjectPath: projectPath,
+		Path:        filepath.Join(projectPath, "package.json"),
+	}
+
+	err = cancellable(ctx, "reading package.json", func() error {
+		_, err := os.Stat(location.Path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return classify(ErrMissingPackageJson, fmt.Errorf("no package.json in %s", projectPath))
+			}
+			return fmt.Errorf("failed to stat package.json: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return PackageJsonLocation{}, err
+	}
+
+	return location, nil
+}
+
+// LoadPackageJson reads and decodes the located package.json. The returned
+// error wraps the *fs.PathError when the file cannot be read, and the decoder
+// error when its content is malformed.
+func LoadPackageJson(ctx context.Context, location PackageJsonLocation) (*PackageJson, error) {
+	var pkg *PackageJson
+	err := cancellable(ctx, "reading package.json", func() error {
+		content, err := os.ReadFile(location.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read package.json: %w", err)
+		}
+
+		pkg, err = parsePackageJson(content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pkg, nil
+}
+
+// ValidatePackageJson checks that the package.json can be started on the
+// target. It returns a packit.Fail error that explains why when it cannot.
+func ValidatePackageJson(pkg PackageJson) error {
+	return validatePackageJson(processEnvironment, pkg)
+}
+
+// validatePackageJson is ValidatePackageJson for the given environment.
+func validatePackageJson(env Environment, pkg PackageJson) error {
+	if !NewStartChain(pkg, StartChainOptions{}).HasStartScript() {
+		_, ok, err := pkg.soleBin()
+		if err != nil {
+			return packit.Fail.WithMessage("%s", err)
+		}
+
+		if !ok {
+			return packit.Fail.WithMessage(NoStartScriptError)
+		}
+	}
+
+	targetOS, targetArch := lookupTarget(env)
+	if !pkg.OS.Allows(targetOS) {
+		return packit.Fail.WithMessage("package.json \"os\" field %q excludes the target operating system %s", []string(pkg.OS), targetOS)
+	}
+
+	if !pkg.CPU.Allows(targetArch) {
+		return packit.Fail.WithMessage("package.json \"cpu\" field %q excludes the target architecture %s", []string(pkg.CPU), targetArch)
+	}
+
+	return nil
+}
+
+// checkStaticSite warns when the start script runs the development server of
+// a project that builds to a static site, which is better served by a web
+// server buildpack than by the dev server. Under
+// BP_NPM_START_REJECT_DEV_SERVERS=true, it returns a packit.Fail error
+// instead, even when warnings are suppressed. The start script is quoted with
+// the given excerpts.
+func checkStaticSite(config Configuration, excerpts excerpter, pkg PackageJson, location PackageJsonLocation, logger scribe.Emitter) error {
+	class, server := classifyScript(pkg.Scripts.Start)
+	if class != ScriptClassDevServer || pkg.Scripts.Build == "" {
+		return nil
+	}
+
+	var siteConfig string
+	for _, name := range staticSiteConfigs[server] {
+		if _, err := os.Stat(filepath.Join(location.ProjectPath, filepath.FromSlash(name))); err == nil {
+			siteConfig = name
+			break
+		}
+	}
+
+	if siteConfig == "" {
+		return nil
+	}
+
+	if config.RejectDevServers {
+		return packit.Fail.WithMessage("start script %s runs the %s development server of a static site (BP_NPM_START_REJECT_DEV_SERVERS=true)", excerpts.quote(pkg.Scripts.Start), server)
+	}
+
+	if !newCheckSet(config).enabled(CheckDevServer) {
+		return nil
+	}
+
+	warn(logger, CheckDevServer, "the start script %s runs the %s development server", excerpts.quote(pkg.Scripts.Start), server)
+	logger.Subprocess("%s and the build script suggest that the app builds to a static site.", siteConfig)
+	logger.Subprocess("Development servers are slow and large in production; build the site and serve it with a web server buildpack instead: https://github.com/paketo-buildpacks/web-servers#readme")
+	logger.Break()
+
+	return nil
+}
+
+// competingRuntimes are the JavaScript runtimes other than node, by the
+// command that runs them, along with the manifests that mark a project as
+// theirs.
+var competingRuntimes = []struct {
+	Command   string
+	Name      string
+	Manifests []string
+}{
+	{Command: "deno", Name: "Deno", Manifests: []string{"deno.json", "deno.jsonc"}},
+	{Command: "bun", Name: "Bun", Manifests: []string{"bunfig.toml"}},
+}
+
+// checkCompetingRuntime returns a packit.Fail error when the project path
+// holds the manifest of another JavaScript runtime and the start script runs
+// that runtime, so that a buildpack for that runtime can claim the app
+// instead. BP_NPM_START_FORCE=true claims the app regardless.
+func checkCompetingRuntime(config Configuration, pkg PackageJson, location PackageJsonLocation) error {
+	fields := strings.Fields(pkg.Scripts.Start)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	for _, runtime := range competingRuntimes {
+		if fields[0] != runtime.Command {
+			continue
+		}
+
+		for _, manifest := range runtime.Manifests {
+			_, err := os.Stat(filepath.Join(location.ProjectPath, manifest))
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return fmt.Errorf("failed to stat %s: %w", manifest, err)
+			}
+
+			if config.Force {
+				return nil
+			}
+
+			return packit.Fail.WithMessage("start script %q runs %s and the project has a %s: use a %s buildpack for the app, or set BP_NPM_START_FORCE=true to start it with npm-start", pkg.Scripts.Start, runtime.Command, manifest, runtime.Name)
+		}
+	}
+
+	return nil
+}
+
+// PlanRequirements assembles the build plan requirements of a validated
+// package.json under the given configuration. When BP_NPM_START_EXPLAIN is
+// set, it logs why each requirement is made.
+func PlanRequirements(config Configuration, pkg PackageJson, logger scribe.Emitter) packit.BuildPlan {
+	if pkg.Scripts.Prepare != "" && pkg.Scripts.PreStart == "" && !config.RunPrepare {
+		logger.Process("Note: package.json declares a \"prepare\" script but no \"prestart\" script")
+		logger.Subprocess("npm 7+ only runs \"prepare\" during install, so it will not run when the app is launched.")
+		logger.Subprocess("To run it at launch, rename it to \"prestart\" or set BP_NPM_START_RUN_PREPARE=true.")
+		logger.Break()
+	}
+
+	nodeMetadata := map[string]interface{}{
+		"launch": true,
+	}
+	nodeReason := "runs the start command at launch"
+
+	// The command hook is run with node during the build.
+	if config.CommandHook != "" {
+		nodeMetadata["build"] = true
+		nodeReason += " and BP_NPM_START_COMMAND_HOOK during the build"
+	}
+
+	// The start script is run as written, so the experimental features it
+	// relies on need a node release that has them.
+	if invocation, ok := ParseNodeInvocation(pkg.Scripts.Start); ok && invocation.Experimental() {
+		nodeMetadata["version"] = ExperimentalNodeVersion
+		nodeMetadata["version-source"] = NodeVersionSourceStartScriptFlags
+		nodeReason += fmt.Sprintf(", at version %s for the experimental features of the start script", ExperimentalNodeVersion)
+	}
+
+	startReason := "package.json declares a start script"
+	if pkg.Scripts.Start == "" {
+		startReason = "package.json declares a bin entry to start"
+	}
+
+	modulesReason := fmt.Sprintf("package.json declares %d dependencies", len(pkg.Dependencies))
+	if len(pkg.Dependencies) == 1 {
+		modulesReason = "package.json declares 1 dependency"
+	}
+
+	requirements := []packit.BuildPlanRequirement{
+		{
+			Name:     Node,
+			Metadata: nodeMetadata,
+		},
+		{
+			Name: Npm,
+			Metadata: map[string]interface{}{
+				"launch": true,
+			},
+		},
+		{
+			Name: NodeModules,
+			Metadata: map[string]interface{}{
+				"launch": true,
+			},
+		},
+	}
+
+	rationales := []Rationale{
+		{Subject: Node, Reason: nodeReason},
+		{Subject: Npm, Reason: startReason},
+		{Subject: NodeModules, Reason: modulesReason},
+	}
+
+	// The decision is recorded in the plan so that Build does not depend on
+	// BP_LIVE_RELOAD_ENABLED having the same value in both phases.
+	if config.LiveReload {
+		provider := config.LiveReloadProvider
+		requirements = append(requirements, packit.BuildPlanRequirement{
+			Name: provider,
+			Metadata: map[string]interface{}{
+				"launch":             true,
+				LiveReloadPlanMarker: true,
+			},
+		})
+
+		reason := "BP_LIVE_RELOAD_ENABLED=true"
+		if provider != Watchexec {
+			reason += " and BP_LIVE_RELOAD_PROVIDER=" + provider
+		}
+		rationales = append(rationales, Rationale{Subject: provider, Reason: reason})
+	}
+
+	if config.Explain {
+		logRationales(logger, "the build plan requirements", rationales)
+	}
+
+	return packit.BuildPlan{
+		Requires: requirements,
+	}
+}
@@ -0,0 +1,204 @@
+package npmstart_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/paketo-buildpacks/npm-start/fakes"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDetectSteps(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir        string
+		projectPath       string
+		projectPathParser *fakes.PathParser
+	)
+
+	it.Before(func() {
+		workingDir = t.TempDir()
+		projectPath = filepath.Join(workingDir, "custom")
+		Expect(os.Mkdir(projectPath, os.ModePerm)).To(Succeed())
+
+		projectPathParser = &fakes.PathParser{}
+		projectPathParser.GetCall.Returns.ProjectPath = projectPath
+	})
+
+	context("LocatePackageJson", func() {
+		it("returns the location of the package.json in the project path", func() {
+			Expect(os.WriteFile(filepath.Join(projectPath, "package.json"), []byte(`{}`), 0600)).To(Succeed())
+
+			location, err := npmstart.LocatePackageJson(gocontext.Background(), projectPathParser, workingDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(location).To(Equal(npmstart.PackageJsonLocation{
+				ProjectPath: projectPath,
+				Path:        filepath.Join(projectPath, "package.json"),
+			}))
+			Expect(projectPathParser.GetCall.Receives.Path).To(Equal(workingDir))
+		})
+
+		context("when there is no package.json", func() {
+			it("returns an error matching ErrMissingPackageJson", func() {
+				_, err := npmstart.LocatePackageJson(gocontext.Background(), projectPathParser, workingDir)
+				Expect(err).To(MatchError(npmstart.ErrMissingPackageJson))
+				Expect(err).To(MatchError("no package.json in " + projectPath))
+			})
+		})
+
+		context("when the project path cannot be resolved", func() {
+			it.Before(func() {
+				projectPathParser.GetCall.Returns.Err = errors.New("some-error")
+			})
+
+			it("returns an error matching ErrProjectPath", func() {
+				_, err := npmstart.LocatePackageJson(gocontext.Background(), projectPathParser, workingDir)
+				Expect(err).To(MatchError(npmstart.ErrProjectPath))
+			})
+		})
+	})
+
+	context("LoadPackageJson", func() {
+		var location npmstart.PackageJsonLocation
+
+		it.Before(func() {
+			location = npmstart.PackageJsonLocation{
+				ProjectPath: projectPath,
+				Path:        filepath.Join(projectPath, "package.json"),
+			}
+		})
+
+		it("reads and decodes the package.json", func() {
+			Expect(os.WriteFile(location.Path, []byte(`{"scripts": {"start": "node server.js"}}`), 0600)).To(Succeed())
+
+			pkg, err := npmstart.LoadPackageJson(gocontext.Background(), location)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pkg.Scripts.Start).To(Equal("node server.js"))
+		})
+
+		context("when the package.json cannot be read", func() {
+			it.Before(func() {
+				Expect(os.Mkdir(location.Path, os.ModePerm)).To(Succeed())
+			})
+
+			it("returns an error wrapping the path error", func() {
+				_, err := npmstart.LoadPackageJson(gocontext.Background(), location)
+				Expect(err).To(MatchError(ContainSubstring("failed to read package.json:")))
+
+				var pathError *fs.PathError
+				Expect(errors.As(err, &pathError)).To(BeTrue())
+			})
+		})
+
+		context("when the package.json is malformed", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(location.Path, []byte(`{"scripts":`), 0600)).To(Succeed())
+			})
+
+			it("returns an error wrapping the decoder error", func() {
+				_, err := npmstart.LoadPackageJson(gocontext.Background(), location)
+				Expect(err).To(MatchError(ContainSubstring("unable to decode package.json")))
+
+				var pathError *fs.PathError
+				Expect(errors.As(err, &pathError)).To(BeFalse())
+			})
+		})
+	})
+
+	context("ValidatePackageJson", func() {
+		it("accepts a package.json with a start script", func() {
+			Expect(npmstart.ValidatePackageJson(npmstart.PackageJson{
+				Scripts: npmstart.PackageScripts{Start: "node server.js"},
+			})).To(Succeed())
+		})
+
+		it("accepts a package.json with a sole bin entry", func() {
+			Expect(npmstart.ValidatePackageJson(npmstart.PackageJson{
+				Bin: npmstart.PackageBin{"some-app": "bin/some-app.js"},
+			})).To(Succeed())
+		})
+
+		context("when there is no start script", func() {
+			it("fails detection", func() {
+				err := npmstart.ValidatePackageJson(npmstart.PackageJson{})
+				Expect(err).To(MatchError(packit.Fail.WithMessage(npmstart.NoStartScriptError)))
+			})
+		})
+
+		context("when the os field excludes the target", func() {
+			it.Before(func() {
+				os.Setenv("CNB_TARGET_OS", "linux")
+			})
+
+			it.After(func() {
+				os.Unsetenv("CNB_TARGET_OS")
+			})
+
+			it("fails detection", func() {
+				err := npmstart.ValidatePackageJson(npmstart.PackageJson{
+					Scripts: npmstart.PackageScripts{Start: "node server.js"},
+					OS:      npmstart.PackagePlatforms{"darwin"},
+				})
+				Expect(err).To(MatchError(`package.json "os" field ["darwin"] excludes the target operating system linux`))
+			})
+		})
+	})
+
+	context("PlanRequirements", func() {
+		var buffer *bytes.Buffer
+
+		it.Before(func() {
+			buffer = bytes.NewBuffer(nil)
+		})
+
+		it("requires node, npm and node_modules at launch", func() {
+			plan := npmstart.PlanRequirements(npmstart.Configuration{}, npmstart.PackageJson{}, scribe.NewEmitter(buffer))
+			Expect(plan).To(Equal(packit.BuildPlan{
+				Requires: []packit.BuildPlanRequirement{
+					{
+						Name: "node",
+						Metadata: map[string]interface{}{
+							"launch": true,
+						},
+					},
+					{
+						Name: "npm",
+						Metadata: map[string]interface{}{
+							"launch": true,
+						},
+					},
+					{
+						Name: "node_modules",
+						Metadata: map[string]interface{}{
+							"launch": true,
+						},
+					},
+				},
+			}))
+		})
+
+		context("when the configuration runs prepare at launch", func() {
+			it("does not note the prepare script", func() {
+				pkg := npmstart.PackageJson{Scripts: npmstart.PackageScripts{Prepare: "some-prepare-command"}}
+
+				npmstart.PlanRequirements(npmstart.Configuration{}, pkg, scribe.NewEmitter(buffer))
+				Expect(buffer.String()).To(ContainSubstring(`declares a "prepare" script`))
+
+				buffer.Reset()
+				npmstart.PlanRequirements(npmstart.Configuration{RunPrepare: true}, pkg, scribe.NewEmitter(buffer))
+				Expect(buffer.String()).To(BeEmpty())
+			})
+		})
+	})
+}
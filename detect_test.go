@@ -1,15 +1,21 @@
 package npmstart_test
 
 import (
+	"bytes"
+	gocontext "context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	npmstart "github.com/paketo-buildpacks/npm-start"
 	"github.com/paketo-buildpacks/npm-start/fakes"
 	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
 	"github.com/sclevine/spec"
 
 	. "github.com/onsi/gomega"
@@ -21,6 +27,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 
 		workingDir        string
 		projectPathParser *fakes.PathParser
+		buffer            *bytes.Buffer
 		detect            packit.DetectFunc
 	)
 
@@ -33,7 +40,9 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		projectPathParser = &fakes.PathParser{}
 		projectPathParser.GetCall.Returns.ProjectPath = filepath.Join(workingDir, "custom")
 
-		detect = npmstart.Detect(projectPathParser)
+		buffer = bytes.NewBuffer(nil)
+
+		detect = npmstart.DetectWithContext(gocontext.Background(), projectPathParser, scribe.NewEmitter(buffer))
 	})
 
 	it.After(func() {
@@ -117,13 +126,289 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 						{
 							Name: "watchexec",
 							Metadata: map[string]interface{}{
-								"launch": true,
+								"launch":      true,
+								"live-reload": true,
 							},
 						},
 					},
 				}))
 			})
 		})
+
+		context("and BP_NPM_START_COMMAND_HOOK is set", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_COMMAND_HOOK", "hooks/command.js")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_COMMAND_HOOK")
+			})
+
+			it("requires node at build time", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires).To(ContainElement(packit.BuildPlanRequirement{
+					Name: npmstart.Node,
+					Metadata: map[string]interface{}{
+						"build":  true,
+						"launch": true,
+					},
+				}))
+			})
+		})
+
+		context("and BP_LIVE_RELOAD_PROVIDER is set", func() {
+			it.Before(func() {
+				os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+				os.Setenv("BP_LIVE_RELOAD_PROVIDER", "entr")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+				os.Unsetenv("BP_LIVE_RELOAD_PROVIDER")
+			})
+
+			it("requires the given provider at launch", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires).To(ContainElement(packit.BuildPlanRequirement{
+					Name: "entr",
+					Metadata: map[string]interface{}{
+						"launch":      true,
+						"live-reload": true,
+					},
+				}))
+				Expect(result.Plan.Requires).NotTo(ContainElement(HaveField("Name", "watchexec")))
+			})
+		})
+	})
+
+	context("when there is a package.json with a prepare script", func() {
+		it.Before(func() {
+			content := npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+				Prepare: "npm run setup",
+				Start:   "node server.js",
+			}}
+
+			bytes, err := json.Marshal(content)
+			Expect(err).To(BeNil())
+
+			Expect(os.WriteFile(filepath.Join(workingDir, "custom", "package.json"), bytes, 0600)).To(Succeed())
+		})
+
+		it("notes that the prepare script will not run at launch", func() {
+			_, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring(`package.json declares a "prepare" script but no "prestart" script`))
+			Expect(buffer.String()).To(ContainSubstring("set BP_NPM_START_RUN_PREPARE=true"))
+		})
+
+		context("and BP_NPM_START_RUN_PREPARE = true", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_RUN_PREPARE", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_RUN_PREPARE")
+			})
+
+			it("does not emit the note", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(BeEmpty())
+			})
+		})
+
+		context("and a prestart script", func() {
+			it.Before(func() {
+				content := npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Prepare:  "npm run setup",
+					PreStart: "npm run migrate",
+					Start:    "node server.js",
+				}}
+
+				bytes, err := json.Marshal(content)
+				Expect(err).To(BeNil())
+
+				Expect(os.WriteFile(filepath.Join(workingDir, "custom", "package.json"), bytes, 0600)).To(Succeed())
+			})
+
+			it("does not emit the note", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(BeEmpty())
+			})
+		})
+	})
+
+	context("when there is a package.json with neither a prepare nor a prestart script", func() {
+		it.Before(func() {
+			content := npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+				Start: "node server.js",
+			}}
+
+			bytes, err := json.Marshal(content)
+			Expect(err).To(BeNil())
+
+			Expect(os.WriteFile(filepath.Join(workingDir, "custom", "package.json"), bytes, 0600)).To(Succeed())
+		})
+
+		it("does not emit the note", func() {
+			_, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(BeEmpty())
+		})
+	})
+
+	context("when the package.json declares os and cpu fields", func() {
+		it.Before(func() {
+			os.Setenv("CNB_TARGET_OS", "linux")
+			os.Setenv("CNB_TARGET_ARCH", "arm64")
+		})
+
+		it.After(func() {
+			os.Unsetenv("CNB_TARGET_OS")
+			os.Unsetenv("CNB_TARGET_ARCH")
+		})
+
+		var writePackageJson = func(platforms string) {
+			content := fmt.Sprintf(`{%s "scripts": {"start": "node server.js"}}`, platforms)
+			Expect(os.WriteFile(filepath.Join(workingDir, "custom", "package.json"), []byte(content), 0600)).To(Succeed())
+		}
+
+		context("and they allow the target", func() {
+			it.Before(func() {
+				writePackageJson(`"os": ["darwin", "linux"], "cpu": "arm64",`)
+			})
+
+			it("detects", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("and the os field excludes the target", func() {
+			it.Before(func() {
+				writePackageJson(`"os": ["darwin", "win32"],`)
+			})
+
+			it("fails detection", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(`package.json "os" field ["darwin" "win32"] excludes the target operating system linux`))
+			})
+		})
+
+		context("and the cpu field excludes the target", func() {
+			it.Before(func() {
+				writePackageJson(`"cpu": ["x64"],`)
+			})
+
+			it("fails detection", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(`package.json "cpu" field ["x64"] excludes the target architecture arm64`))
+			})
+		})
+
+		context("and they only contain negated entries that do not match the target", func() {
+			it.Before(func() {
+				writePackageJson(`"os": ["!win32"], "cpu": ["!x64", "!ia32"],`)
+			})
+
+			it("detects", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("and a negated entry matches the target", func() {
+			it.Before(func() {
+				writePackageJson(`"cpu": ["arm64", "!arm64"],`)
+			})
+
+			it("fails detection", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(ContainSubstring("excludes the target architecture arm64")))
+			})
+		})
+
+		context("and they allow any target", func() {
+			it.Before(func() {
+				writePackageJson(`"os": ["any"], "cpu": "any",`)
+			})
+
+			it("detects", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("and they list any among other targets", func() {
+			it.Before(func() {
+				writePackageJson(`"os": ["any", "darwin"],`)
+			})
+
+			it("fails detection", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(`package.json "os" field ["any" "darwin"] excludes the target operating system linux`))
+			})
+		})
+
+		context("and they list any but negate the target", func() {
+			it.Before(func() {
+				writePackageJson(`"os": ["any", "!linux"],`)
+			})
+
+			it("fails detection", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(`package.json "os" field ["any" "!linux"] excludes the target operating system linux`))
+			})
+		})
+
+		context("and the target is given with Go architecture names", func() {
+			it.Before(func() {
+				os.Setenv("CNB_TARGET_ARCH", "amd64")
+				writePackageJson(`"cpu": ["x64"],`)
+			})
+
+			it("matches it against the Node.js names", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
 	})
 
 	context("when there is a package.json without a start script", func() {
@@ -149,6 +434,366 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 			})
 			Expect(err).To(MatchError(ContainSubstring(npmstart.NoStartScriptError)))
 		})
+
+		context("when BP_NPM_START_DESCRIPTOR names a descriptor with a command", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_DESCRIPTOR", "manifest.yml")
+				Expect(os.WriteFile(filepath.Join(workingDir, "manifest.yml"), []byte("applications:\n- name: some-app\n  command: node server.js\n"), 0600)).To(Succeed())
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_DESCRIPTOR")
+			})
+
+			it("detects", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires).NotTo(BeEmpty())
+			})
+		})
+	})
+
+	context("when there is a package.json without a start script but with bin entries", func() {
+		var writePackageJson = func(bin string) {
+			content := fmt.Sprintf(`{"name": "some-service", "bin": %s}`, bin)
+			Expect(os.WriteFile(filepath.Join(workingDir, "custom", "package.json"), []byte(content), 0600)).To(Succeed())
+		}
+
+		context("and there is a single entry", func() {
+			it.Before(func() {
+				writePackageJson(`{"some-service": "./dist/cli.js"}`)
+			})
+
+			it("detects", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("and the entry is given as a string", func() {
+			it.Before(func() {
+				writePackageJson(`"./dist/cli.js"`)
+			})
+
+			it("detects", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("and there are several entries", func() {
+			it.Before(func() {
+				writePackageJson(`{"some-service": "./dist/cli.js", "other-service": "./dist/other.js"}`)
+			})
+
+			it("fails detection asking for a start script", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError("package.json has no start script and declares several bin entries (other-service, some-service): add a start script that runs one of them"))
+			})
+		})
+	})
+
+	context("when BP_NPM_START_EXPLAIN = true", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "custom", "package.json"), []byte(`{
+				"scripts": {
+					"start": "node server.js"
+				},
+				"dependencies": {
+					"express": "^4.18.0",
+					"pg": "^8.8.0"
+				}
+			}`), 0600)).To(Succeed())
+
+			os.Setenv("BP_NPM_START_EXPLAIN", "true")
+			os.Setenv("BP_LIVE_RELOAD_ENABLED", "true")
+			os.Setenv("BP_LIVE_RELOAD_PROVIDER", "entr")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_EXPLAIN")
+			os.Unsetenv("BP_LIVE_RELOAD_ENABLED")
+			os.Unsetenv("BP_LIVE_RELOAD_PROVIDER")
+		})
+
+		it("explains every requirement", func() {
+			_, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(Equal(`  Explaining the build plan requirements
+    node: runs the start command at launch
+    npm: package.json declares a start script
+    node_modules: package.json declares 2 dependencies
+    entr: BP_LIVE_RELOAD_ENABLED=true and BP_LIVE_RELOAD_PROVIDER=entr
+
+`))
+		})
+	})
+
+	context("when the start script at the workspace root runs a monorepo task runner", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{
+				"scripts": {
+					"start": "turbo run start --filter=web"
+				}
+			}`), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "turbo.json"), []byte(`{}`), 0600)).To(Succeed())
+
+			projectPathParser.GetCall.Returns.Err = errors.New("some-error")
+		})
+
+		it("detects without resolving the project path", func() {
+			_, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(projectPathParser.GetCall.CallCount).To(Equal(0))
+		})
+	})
+
+	context("when the start script relies on experimental node features", func() {
+		it("requires a node version that has them", func() {
+			for _, start := range []string{
+				"node --experimental-wasm-modules server.mjs",
+				"node --enable-source-maps --experimental-vm-modules server.mjs",
+				"node app.wasm",
+			} {
+				Expect(os.WriteFile(filepath.Join(workingDir, "custom", "package.json"), []byte(fmt.Sprintf(`{"scripts": {"start": %q}}`, start)), 0600)).To(Succeed())
+
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires[0]).To(Equal(packit.BuildPlanRequirement{
+					Name: "node",
+					Metadata: map[string]interface{}{
+						"launch":         true,
+						"version":        ">=18",
+						"version-source": "start-script-flags",
+					},
+				}), start)
+			}
+		})
+
+		it("does not require a version for other flags", func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "custom", "package.json"), []byte(`{"scripts": {"start": "node --enable-source-maps server.js"}}`), 0600)).To(Succeed())
+
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan.Requires[0].Metadata).To(Equal(map[string]interface{}{
+				"launch": true,
+			}))
+		})
+	})
+
+	context("when the project has the manifest of another JavaScript runtime", func() {
+		var writeProject func(start, manifest string)
+
+		it.Before(func() {
+			writeProject = func(start, manifest string) {
+				Expect(os.RemoveAll(filepath.Join(workingDir, "custom"))).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(workingDir, "custom"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "custom", "package.json"), []byte(fmt.Sprintf(`{
+					"scripts": {
+						"start": %q
+					}
+				}`, start)), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "custom", manifest), nil, 0600)).To(Succeed())
+			}
+		})
+
+		it("fails detection when the start script runs that runtime", func() {
+			for _, project := range []struct{ start, manifest, runtime string }{
+				{"deno run --allow-net main.ts", "deno.json", "Deno"},
+				{"deno run main.ts", "deno.jsonc", "Deno"},
+				{"bun run index.ts", "bunfig.toml", "Bun"},
+			} {
+				writeProject(project.start, project.manifest)
+
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(fmt.Sprintf("start script %q runs %s and the project has a %s: use a %s buildpack for the app, or set BP_NPM_START_FORCE=true to start it with npm-start", project.start, strings.Fields(project.start)[0], project.manifest, project.runtime)), project.manifest)
+
+				fail := packit.Fail
+				Expect(errors.As(err, &fail)).To(BeTrue(), project.manifest)
+			}
+		})
+
+		it("detects when the runtime is not the first word of the start script", func() {
+			for _, project := range []struct{ start, manifest string }{
+				{"node server.js", "deno.json"},
+				{"echo deno && node server.js", "deno.json"},
+				{"bun run index.ts", "deno.json"},
+				{"deno run main.ts", "bunfig.toml"},
+			} {
+				writeProject(project.start, project.manifest)
+
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred(), project.start)
+			}
+		})
+
+		context("when BP_NPM_START_FORCE=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_FORCE", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_FORCE")
+			})
+
+			it("detects", func() {
+				writeProject("deno run main.ts", "deno.json")
+
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	context("when the start script runs the dev server of a static site", func() {
+		var writeProject func(start, config string)
+
+		it.Before(func() {
+			writeProject = func(start, config string) {
+				Expect(os.WriteFile(filepath.Join(workingDir, "custom", "package.json"), []byte(fmt.Sprintf(`{
+					"scripts": {
+						"build": "some-build-command",
+						"start": %q
+					}
+				}`, start)), 0600)).To(Succeed())
+
+				Expect(os.MkdirAll(filepath.Dir(filepath.Join(workingDir, "custom", config)), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "custom", config), nil, 0600)).To(Succeed())
+			}
+		})
+
+		it("warns for each framework", func() {
+			for _, project := range []struct{ start, config, server string }{
+				{"react-scripts start", "public/index.html", "react-scripts"},
+				{"vite", "vite.config.ts", "vite"},
+				{"ng serve", "angular.json", "ng"},
+			} {
+				buffer.Reset()
+				writeProject(project.start, project.config)
+
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("WARNING: the start script %q runs the %s development server", project.start, project.server)), project.start)
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("%s and the build script suggest that the app builds to a static site.", project.config)), project.start)
+				Expect(buffer.String()).To(ContainSubstring("https://github.com/paketo-buildpacks/web-servers#readme"), project.start)
+			}
+		})
+
+		context("when the start script embeds a secret", func() {
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_REJECT_DEV_SERVERS")
+			})
+
+			it("never shows the secret in the warning or the failure", func() {
+				writeProject("VITE_API_TOKEN=sentinel-secret-0123456789 vite", "vite.config.ts")
+
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).To(ContainSubstring(`WARNING: the start script "VITE_API_TOKEN=[redacted] vite" runs the vite development server`))
+
+				os.Setenv("BP_NPM_START_REJECT_DEV_SERVERS", "true")
+				_, err = detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).NotTo(ContainSubstring("sentinel-secret-0123456789"))
+				Expect(buffer.String()).NotTo(ContainSubstring("sentinel-secret-0123456789"))
+			})
+		})
+
+		context("when the project has no static site configuration", func() {
+			it("does not warn", func() {
+				writeProject("vite", "server.js")
+
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).NotTo(ContainSubstring("WARNING"))
+			})
+		})
+
+		context("when the dev server is run with a production configuration", func() {
+			it("does not warn", func() {
+				writeProject("ng serve --prod", "angular.json")
+
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).NotTo(ContainSubstring("WARNING"))
+			})
+		})
+
+		context("when BP_NPM_START_DISABLED_CHECKS lists dev-server", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_DISABLED_CHECKS", "dev-deps,dev-server")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_DISABLED_CHECKS")
+			})
+
+			it("does not warn", func() {
+				writeProject("react-scripts start", "public/index.html")
+
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).NotTo(ContainSubstring("WARNING"))
+			})
+		})
+
+		context("when BP_NPM_START_REJECT_DEV_SERVERS=true", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_REJECT_DEV_SERVERS", "true")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_REJECT_DEV_SERVERS")
+			})
+
+			it("fails detection", func() {
+				writeProject("react-scripts start", "public/index.html")
+
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(`start script "react-scripts start" runs the react-scripts development server of a static site (BP_NPM_START_REJECT_DEV_SERVERS=true)`))
+
+				fail := packit.Fail
+				Expect(errors.As(err, &fail)).To(BeTrue())
+			})
+		})
 	})
 
 	context("when there is no package.json", func() {
@@ -188,6 +833,61 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 					WorkingDir: workingDir,
 				})
 				Expect(err).To(MatchError("some-error"))
+				Expect(err).To(MatchError(npmstart.ErrProjectPath))
+			})
+		})
+
+		context("when the context is canceled while the project path is being resolved", func() {
+			var release chan struct{}
+
+			it.Before(func() {
+				release = make(chan struct{})
+				projectPathParser.GetCall.Stub = func(string) (string, error) {
+					<-release
+					return filepath.Join(workingDir, "custom"), nil
+				}
+
+				ctx, cancel := gocontext.WithCancel(gocontext.Background())
+				time.AfterFunc(10*time.Millisecond, cancel)
+
+				detect = npmstart.DetectWithContext(ctx, projectPathParser, scribe.NewEmitter(buffer))
+			})
+
+			it.After(func() {
+				close(release)
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(gocontext.Canceled))
+				Expect(err).To(MatchError(ContainSubstring("canceled while resolving the project path")))
+			})
+		})
+
+		context("when BP_NPM_START_RUN_PREPARE is set to an invalid value", func() {
+			it.Before(func() {
+				content := npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start: "node server.js",
+				}}
+
+				bytes, err := json.Marshal(content)
+				Expect(err).To(BeNil())
+
+				Expect(os.WriteFile(filepath.Join(workingDir, "custom", "package.json"), bytes, 0600)).To(Succeed())
+				os.Setenv("BP_NPM_START_RUN_PREPARE", "not-a-bool")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_RUN_PREPARE")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to parse BP_NPM_START_RUN_PREPARE value not-a-bool")))
 			})
 		})
 
@@ -213,6 +913,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 					WorkingDir: workingDir,
 				})
 				Expect(err).To(MatchError(ContainSubstring("failed to parse BP_LIVE_RELOAD_ENABLED value not-a-bool")))
+				Expect(err).To(MatchError(npmstart.ErrInvalidConfiguration))
 			})
 		})
 	})
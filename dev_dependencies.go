@@ -0,0 +1,94 @@
+package npmstart
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// DevDependencyWarningThreshold is the number of installed
+	// devDependencies above which Build warns that they were not pruned.
+	DevDependencyWarningThreshold = 3
+
+	// devDependencySizeBudget bounds the time spent measuring installed
+	// devDependencies so that large module trees do not slow down the build.
+	devDependencySizeBudget = 2 * time.Second
+)
+
+var errSizeBudgetExceeded = errors.New("size budget exceeded")
+
+// installedDevDependencies returns the sorted names of the devDependencies
+// that have a top-level directory in nodeModules.
+func installedDevDependencies(nodeModules string, devDependencies map[string]string) ([]string, error) {
+	var installed []string
+	for name := range devDependencies {
+		_, err := os.Stat(filepath.Join(nodeModules, filepath.FromSlash(name)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to stat %s: %w", filepath.Join(nodeModules, name), err)
+		}
+
+		installed = append(installed, name)
+	}
+
+	sort.Strings(installed)
+
+	return installed, nil
+}
+
+// estimateSize sums the size of the regular files under the given
+// directories. It stops once the deadline has passed, in which case the
+// returned size is a lower bound and the boolean return is false.
+func estimateSize(dirs []string, deadline time.Time) (int64, bool) {
+	var size int64
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+
+			if time.Now().After(deadline) {
+				return errSizeBudgetExceeded
+			}
+
+			if entry.Type().IsRegular() {
+				info, err := entry.Info()
+				if err == nil {
+					size += info.Size()
+				}
+			}
+
+			return nil
+		})
+		if errors.Is(err, errSizeBudgetExceeded) {
+			return size, false
+		}
+	}
+
+	return size, true
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if value < unit || suffix == "GB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+
+	return ""
+}
@@ -0,0 +1,19 @@
+package npmstart
+
+// CheckEngines is the name of the exec.d helper, shipped in the buildpack bin
+// directory, that BP_NPM_START_ENGINES_STRICT installs. It refuses to start a
+// process, with exit code 64, when the node of the run image is outside the
+// engines.node range of the package.json.
+const CheckEngines = "check-engines"
+
+// EnginesNodeEnv holds the engines.node range of the package.json at launch,
+// for CheckEngines to compare the node version against.
+const EnginesNodeEnv = "BPL_NPM_START_ENGINES_NODE"
+
+// EnginesNodeMetadata is the key of the start layer metadata that records the
+// engines.node range that the image was built for.
+const EnginesNodeMetadata = "engines-node"
+
+type PackageEngines struct {
+	Node string `json:"node"`
+}
@@ -0,0 +1,47 @@
+package npmstart
+
+import (
+	"os"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// Environment looks up the variables of the build environment. Detect and
+// Build read the environment of the process, Preview the variables that it is
+// given.
+type Environment func(name string) (string, bool)
+
+// processEnvironment is the environment of the running process.
+var processEnvironment Environment = os.LookupEnv
+
+// mapEnvironment returns an environment that holds the given variables.
+func mapEnvironment(variables map[string]string) Environment {
+	return func(name string) (string, bool) {
+		value, ok := variables[name]
+		return value, ok
+	}
+}
+
+// get returns the value of the named variable, or an empty string when it is
+// unset.
+func (e Environment) get(name string) string {
+	value, _ := e(name)
+	return value
+}
+
+// LiveReloadPlanMarker is the metadata key that marks the build plan
+// requirement of the live reload provider.
+const LiveReloadPlanMarker = "live-reload"
+
+// lookupLiveReloadPlanEntry returns the build plan entry that Detect marked
+// as the live reload provider. The boolean return is false when Detect did
+// not enable live reload.
+func lookupLiveReloadPlanEntry(plan packit.BuildpackPlan) (packit.BuildpackPlanEntry, bool) {
+	for _, entry := range plan.Entries {
+		if marked, ok := entry.Metadata[LiveReloadPlanMarker].(bool); ok && marked {
+			return entry, true
+		}
+	}
+
+	return packit.BuildpackPlanEntry{}, false
+}
@@ -0,0 +1,201 @@
+package npmstart
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// Errors returned by Detect and Build can be matched against these classes
+// with errors.Is. Their exit codes are documented in the README.
+var (
+	ErrMissingPackageJson   = errors.New("missing package.json")
+	ErrNoStartScript        = errors.New("no start script")
+	ErrInvalidConfiguration = errors.New("invalid configuration")
+	ErrProjectPath          = errors.New("project path resolution failure")
+)
+
+// Exit codes for the classes of errors returned by Detect and Build.
+const (
+	ExitMissingPackageJson   = 10
+	ExitNoStartScript        = 11
+	ExitInvalidConfiguration = 12
+	ExitProjectPath          = 13
+	ExitInternal             = 20
+)
+
+// classifiedError attaches an error class to an error without changing its
+// message.
+type classifiedError struct {
+	class error
+	err   error
+}
+
+func classify(class, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return classifiedError{class: class, err: err}
+}
+
+func (e classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e classifiedError) Unwrap() error {
+	return e.err
+}
+
+func (e classifiedError) Is(target error) bool {
+	return target == e.class
+}
+
+// MaxJoinedErrors is the number of errors that JoinErrors lists; the others
+// are only counted.
+const MaxJoinedErrors = 20
+
+// joinedError holds the errors of a validation phase that are reported
+// together.
+type joinedError struct {
+	subject string
+	errs    []error
+	omitted int
+}
+
+// JoinErrors returns the given errors as a single error whose message lists
+// them as problems with the given subject, so that every problem of a
+// validation phase is reported at once. Nil errors are left out; it returns
+// nil when no error is left, and a lone error as it is. Errors that
+// JoinErrors returned are listed by their problems. errors.Is and errors.As
+// match each of the joined errors, up to MaxJoinedErrors of them.
+func JoinErrors(subject string, errs ...error) error {
+	joined := joinedError{subject: subject}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		if nested, ok := err.(joinedError); ok {
+			joined.errs = append(joined.errs, nested.errs...)
+			joined.omitted += nested.omitted
+			if len(joined.errs) > MaxJoinedErrors {
+				joined.omitted += len(joined.errs) - MaxJoinedErrors
+				joined.errs = joined.errs[:MaxJoinedErrors]
+			}
+			continue
+		}
+
+		if len(joined.errs) == MaxJoinedErrors {
+			joined.omitted++
+			continue
+		}
+
+		joined.errs = append(joined.errs, err)
+	}
+
+	switch len(joined.errs) {
+	case 0:
+		return nil
+	case 1:
+		return joined.errs[0]
+	default:
+		return joined
+	}
+}
+
+func (e joinedError) Error() string {
+	message := fmt.Sprintf("found %d problems with %s:", len(e.errs)+e.omitted, e.subject)
+	for i, err := range e.errs {
+		message += fmt.Sprintf("\n  %d. %s", i+1, err)
+	}
+
+	if e.omitted > 0 {
+		message += fmt.Sprintf("\n  ... and %d more", e.omitted)
+	}
+
+	return message
+}
+
+// Unwrap returns the joined errors, for the Go releases whose errors package
+// walks multi-errors.
+func (e joinedError) Unwrap() []error {
+	return e.errs
+}
+
+func (e joinedError) Is(target error) bool {
+	for _, err := range e.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e joinedError) As(target interface{}) bool {
+	for _, err := range e.errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExitCode returns the exit code for the given error. The boolean return is
+// false for errors that do not belong to a known class, which should be
+// handled by packit's default exit handling.
+func ExitCode(err error) (int, bool) {
+	var pathError *fs.PathError
+
+	switch {
+	case errors.Is(err, ErrMissingPackageJson):
+		return ExitMissingPackageJson, true
+	case errors.Is(err, ErrNoStartScript):
+		return ExitNoStartScript, true
+	case errors.Is(err, ErrInvalidConfiguration):
+		return ExitInvalidConfiguration, true
+	case errors.Is(err, ErrProjectPath):
+		return ExitProjectPath, true
+	case errors.As(err, &pathError):
+		return ExitInternal, true
+	}
+
+	return 0, false
+}
+
+// ExitHandler exits with the documented exit code for errors returned by
+// Detect and Build. It is passed to packit.Run using packit.WithExitHandler.
+type ExitHandler struct {
+	stderr io.Writer
+	exit   func(int)
+}
+
+func NewExitHandler(stderr io.Writer, exit func(int)) ExitHandler {
+	return ExitHandler{
+		stderr: stderr,
+		exit:   exit,
+	}
+}
+
+func (h ExitHandler) Error(err error) {
+	fmt.Fprintln(h.stderr, err)
+
+	fail := packit.Fail
+	switch {
+	case err == nil:
+		h.exit(0)
+	case errors.As(err, &fail):
+		h.exit(100)
+	default:
+		code, ok := ExitCode(err)
+		if !ok {
+			code = 1
+		}
+		h.exit(code)
+	}
+}
@@ -0,0 +1,139 @@
+package npmstart_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testErrors(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ExitCode", func() {
+		it("returns the exit code for each error class", func() {
+			for err, code := range map[error]int{
+				fmt.Errorf("failed: %w", npmstart.ErrMissingPackageJson):                                         10,
+				fmt.Errorf("failed: %w", npmstart.ErrNoStartScript):                                              11,
+				fmt.Errorf("failed: %w", npmstart.ErrInvalidConfiguration):                                       12,
+				fmt.Errorf("failed: %w", npmstart.ErrProjectPath):                                                13,
+				fmt.Errorf("failed: %w", &fs.PathError{Op: "open", Path: "package.json", Err: fs.ErrPermission}): 20,
+			} {
+				actual, ok := npmstart.ExitCode(err)
+				Expect(ok).To(BeTrue(), err.Error())
+				Expect(actual).To(Equal(code), err.Error())
+			}
+		})
+
+		context("when the error does not belong to a class", func() {
+			it("returns false", func() {
+				_, ok := npmstart.ExitCode(errors.New("some error"))
+				Expect(ok).To(BeFalse())
+			})
+		})
+	})
+
+	context("JoinErrors", func() {
+		it("lists the errors as numbered problems", func() {
+			err := npmstart.JoinErrors("the build configuration",
+				errors.New("first problem"),
+				nil,
+				errors.New("second problem"),
+			)
+			Expect(err).To(MatchError("found 2 problems with the build configuration:\n  1. first problem\n  2. second problem"))
+		})
+
+		it("returns a lone error as it is", func() {
+			lone := errors.New("some problem")
+			Expect(npmstart.JoinErrors("the build configuration", nil, lone)).To(BeIdenticalTo(lone))
+		})
+
+		it("returns nil without errors", func() {
+			Expect(npmstart.JoinErrors("the build configuration", nil, nil)).To(BeNil())
+		})
+
+		it("lists at most MaxJoinedErrors errors", func() {
+			var errs []error
+			for i := 1; i <= npmstart.MaxJoinedErrors+5; i++ {
+				errs = append(errs, fmt.Errorf("problem %d", i))
+			}
+
+			err := npmstart.JoinErrors("the build configuration", errs...)
+			Expect(err.Error()).To(HavePrefix("found 25 problems with the build configuration:\n  1. problem 1\n"))
+			Expect(err.Error()).To(HaveSuffix("\n  20. problem 20\n  ... and 5 more"))
+			Expect(err.Error()).NotTo(ContainSubstring("problem 21"))
+		})
+
+		it("lists the problems of joined errors along with the others", func() {
+			first := npmstart.JoinErrors("the build configuration",
+				errors.New("first problem"),
+				errors.New("second problem"),
+			)
+
+			err := npmstart.JoinErrors("the build configuration", first, errors.New("third problem"))
+			Expect(err).To(MatchError("found 3 problems with the build configuration:\n  1. first problem\n  2. second problem\n  3. third problem"))
+		})
+
+		it("matches each of the joined errors", func() {
+			pathError := &fs.PathError{Op: "open", Path: "package.json", Err: fs.ErrPermission}
+			err := npmstart.JoinErrors("the build configuration",
+				fmt.Errorf("failed: %w", npmstart.ErrInvalidConfiguration),
+				fmt.Errorf("failed: %w", pathError),
+			)
+
+			Expect(errors.Is(err, npmstart.ErrInvalidConfiguration)).To(BeTrue())
+			Expect(errors.Is(err, fs.ErrPermission)).To(BeTrue())
+			Expect(errors.Is(err, npmstart.ErrNoStartScript)).To(BeFalse())
+
+			var target *fs.PathError
+			Expect(errors.As(err, &target)).To(BeTrue())
+			Expect(target).To(BeIdenticalTo(pathError))
+
+			code, ok := npmstart.ExitCode(err)
+			Expect(ok).To(BeTrue())
+			Expect(code).To(Equal(12))
+		})
+	})
+
+	context("ExitHandler", func() {
+		var (
+			stderr *bytes.Buffer
+			code   int
+			h      npmstart.ExitHandler
+		)
+
+		it.Before(func() {
+			stderr = bytes.NewBuffer(nil)
+			code = -1
+			h = npmstart.NewExitHandler(stderr, func(c int) { code = c })
+		})
+
+		it("prints the error and exits with its exit code", func() {
+			h.Error(fmt.Errorf("failed to parse BP_LIVE_RELOAD_ENABLED: %w", npmstart.ErrInvalidConfiguration))
+			Expect(code).To(Equal(12))
+			Expect(stderr.String()).To(Equal("failed to parse BP_LIVE_RELOAD_ENABLED: invalid configuration\n"))
+		})
+
+		it("exits with 100 when detection fails", func() {
+			h.Error(packit.Fail.WithMessage("no package.json"))
+			Expect(code).To(Equal(100))
+		})
+
+		it("exits with 0 when there is no error", func() {
+			h.Error(nil)
+			Expect(code).To(Equal(0))
+		})
+
+		it("exits with 1 for any other error", func() {
+			h.Error(errors.New("some error"))
+			Expect(code).To(Equal(1))
+		})
+	})
+}
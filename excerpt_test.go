@@ -0,0 +1,75 @@
+package npmstart_test
+
+import (
+	"strings"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testScriptExcerpt(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		redactor npmstart.Redactor
+	)
+
+	it.Before(func() {
+		redactor = npmstart.NewRedactor([]string{"NPM_TOKEN=some-npm-token"})
+	})
+
+	it("leaves short scripts without secrets as they are", func() {
+		Expect(npmstart.ScriptExcerpt("node server.js --port 8080", 200, redactor)).To(Equal("node server.js --port 8080"))
+	})
+
+	it("cuts long scripts with an ellipsis", func() {
+		excerpt := npmstart.ScriptExcerpt(strings.Repeat("a", 50), 10, redactor)
+		Expect(excerpt).To(Equal("aaaaaaaaa…"))
+		Expect([]rune(excerpt)).To(HaveLen(10))
+	})
+
+	it("cuts by characters rather than bytes", func() {
+		Expect(npmstart.ScriptExcerpt("echo café café", 10, redactor)).To(Equal("echo café…"))
+	})
+
+	it("strips terminal escape sequences", func() {
+		Expect(npmstart.ScriptExcerpt("echo \x1b[31mred\x1b[0m \x1b]0;title\x07done", 200, redactor)).To(Equal("echo red done"))
+	})
+
+	it("collapses line breaks", func() {
+		Expect(npmstart.ScriptExcerpt("node server.js &&\n  \r\n  echo done\n", 200, redactor)).To(Equal("node server.js && echo done"))
+	})
+
+	it("redacts the secrets of the environment", func() {
+		Expect(npmstart.ScriptExcerpt("npm_config__authToken=some-npm-token node server.js", 200, redactor)).To(Equal("npm_config__authToken=[redacted] node server.js"))
+		Expect(npmstart.ScriptExcerpt("node server.js some-npm-token", 200, redactor)).To(Equal("node server.js [redacted]"))
+	})
+
+	it("redacts secret-looking assignments, flags and URL passwords", func() {
+		fixtures := map[string]string{
+			"API_TOKEN=abc123 node server.js":                 "API_TOKEN=[redacted] node server.js",
+			`DB_PASSWORD="p a s s" node server.js`:            "DB_PASSWORD=[redacted] node server.js",
+			"node server.js --token abc123 --port 8080":       "node server.js --token [redacted] --port 8080",
+			"node server.js --api-key=abc123":                 "node server.js --api-key=[redacted]",
+			"node server.js --auth --port 8080":               "node server.js --auth --port 8080",
+			"node server.js --password abc && echo done":      "node server.js --password [redacted] && echo done",
+			"node server.js --db https://user:abc@db:5432/db": "node server.js --db https://user:[redacted]@db:5432/db",
+			"NODE_ENV=production node server.js":              "NODE_ENV=production node server.js",
+		}
+
+		for script, expected := range fixtures {
+			Expect(npmstart.ScriptExcerpt(script, 200, redactor)).To(Equal(expected), script)
+		}
+	})
+
+	it("leaves references to variables as they are", func() {
+		Expect(npmstart.ScriptExcerpt(`API_TOKEN=$GITHUB_TOKEN node server.js --token "${TOKEN}"`, 200, redactor)).To(Equal(`API_TOKEN=$GITHUB_TOKEN node server.js --token "${TOKEN}"`))
+	})
+
+	it("redacts before cutting so that no part of a secret remains", func() {
+		Expect(npmstart.ScriptExcerpt("API_TOKEN=abcdefghijklmnop node server.js", 14, redactor)).To(Equal("API_TOKEN=[re…"))
+	})
+}
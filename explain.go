@@ -0,0 +1,40 @@
+package npmstart
+
+import (
+	"fmt"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// Rationale is a one-line explanation of why a build plan requirement or a
+// launch process exists. It is recorded by the code that decides to create
+// the requirement or process, and logged when BP_NPM_START_EXPLAIN is set.
+type Rationale struct {
+	Subject string
+	Reason  string
+}
+
+// logRationales logs the given rationales under the given title.
+func logRationales(logger scribe.Emitter, title string, rationales []Rationale) {
+	logger.Process("Explaining %s", title)
+	for _, rationale := range rationales {
+		logger.Subprocess("%s: %s", rationale.Subject, rationale.Reason)
+	}
+	logger.Break()
+}
+
+// Explain returns the reason recorded for each pair of enabled options that
+// can be used together.
+func (m OptionMatrix) Explain(enabled map[string]bool) []Rationale {
+	var rationales []Rationale
+	for _, pair := range m {
+		if pair.Compatible && enabled[pair.Options[0]] && enabled[pair.Options[1]] {
+			rationales = append(rationales, Rationale{
+				Subject: fmt.Sprintf("%s with %s", pair.Options[0], pair.Options[1]),
+				Reason:  pair.Reason,
+			})
+		}
+	}
+
+	return rationales
+}
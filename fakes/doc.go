@@ -0,0 +1,6 @@
+// Package fakes provides test doubles for the interfaces that npmstart.Detect
+// and npmstart.Build accept. The fakes are generated by faux and are
+// supported for use by buildpacks that compose npm-start: the field layout of
+// each <Method>Call struct (CallCount, Receives, Returns and Stub) is kept
+// stable across releases.
+package fakes
@@ -0,0 +1,26 @@
+package fakes_test
+
+import (
+	"fmt"
+
+	"github.com/paketo-buildpacks/npm-start/fakes"
+)
+
+func ExamplePathParser() {
+	pathParser := &fakes.PathParser{}
+	pathParser.GetCall.Returns.ProjectPath = "/workspace/some-project-dir"
+
+	projectPath, err := pathParser.Get("/workspace")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(projectPath)
+	fmt.Println(pathParser.GetCall.CallCount)
+	fmt.Println(pathParser.GetCall.Receives.Path)
+
+	// Output:
+	// /workspace/some-project-dir
+	// 1
+	// /workspace
+}
@@ -0,0 +1,12 @@
+package fakes_test
+
+import (
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/paketo-buildpacks/npm-start/fakes"
+)
+
+var (
+	_ npmstart.Executable   = &fakes.Executable{}
+	_ npmstart.PathParser   = &fakes.PathParser{}
+	_ npmstart.SpanExporter = &fakes.SpanExporter{}
+)
@@ -0,0 +1,35 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+)
+
+type SpanExporter struct {
+	ExportCall struct {
+		sync.Mutex
+		CallCount int
+		Receives  struct {
+			Ctx   context.Context
+			Spans []npmstart.Span
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(context.Context, []npmstart.Span) error
+	}
+}
+
+func (f *SpanExporter) Export(param1 context.Context, param2 []npmstart.Span) error {
+	f.ExportCall.Lock()
+	defer f.ExportCall.Unlock()
+	f.ExportCall.CallCount++
+	f.ExportCall.Receives.Ctx = param1
+	f.ExportCall.Receives.Spans = param2
+	if f.ExportCall.Stub != nil {
+		return f.ExportCall.Stub(param1, param2)
+	}
+	return f.ExportCall.Returns.Error
+}
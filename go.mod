@@ -8,4 +8,5 @@ require (
 	github.com/paketo-buildpacks/occam v0.10.0
 	github.com/paketo-buildpacks/packit/v2 v2.3.1
 	github.com/sclevine/spec v1.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
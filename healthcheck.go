@@ -0,0 +1,91 @@
+package npmstart
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+const (
+	// HealthcheckProcess is the type of the process that runs the script
+	// named by $BP_NPM_START_HEALTHCHECK_SCRIPT.
+	HealthcheckProcess = "health"
+
+	// HealthcheckLabel names the process that runs the healthcheck script,
+	// for controllers that configure exec probes.
+	HealthcheckLabel = "io.paketo.npm-start.healthcheck.process"
+)
+
+// npmVariable matches references to the variables that npm sets for the
+// scripts it runs, such as $npm_package_version, which a script run without
+// npm does not see unless the build exports it. The name is the first
+// submatch.
+var npmVariable = regexp.MustCompile(`\$\{?(npm_[A-Za-z0-9_]*)`)
+
+// healthcheckProcess returns the non-default process that runs the named
+// script for probes. The script, along with its pre and post scripts, is run
+// by bash as the start chain is, which saves starting npm on every probe.
+// Scripts that cannot be run that way are run with npm, and the returned
+// reason tells why.
+func healthcheckProcess(name string, pkg PackageJson, workingDir, projectPath string, silent bool, excerpts excerpter) (packit.Process, string, error) {
+	if _, ok := pkg.Scripts.Script(name); !ok {
+		return packit.Process{}, "", classify(ErrInvalidConfiguration, fmt.Errorf("BP_NPM_START_HEALTHCHECK_SCRIPT names the script %q, which package.json does not define", name))
+	}
+
+	var chainDir string
+	if projectPath != workingDir {
+		chainDir = projectPath
+	}
+
+	chain := NewScriptChain(pkg, name, chainDir)
+
+	// The config of the package.json is exported into the launch
+	// environment, so the scripts see it without npm as well.
+	exported := PackageConfigEnv(pkg.Config)
+
+	var reason string
+	for _, segment := range chain.Segments {
+		if segment.Script && readsNpmVariables(segment.Command, exported) {
+			reason = fmt.Sprintf("the %s script reads variables that only npm sets", segment.Name)
+			break
+		}
+	}
+
+	switch {
+	case reason != "":
+	case chain.validate(excerpts) != nil:
+		reason = chain.validate(excerpts).Error()
+	case !chain.Inline():
+		reason = fmt.Sprintf("the script is longer than the %d bytes that are passed to the shell inline", MaxInlineCommandSize)
+	}
+
+	if reason != "" {
+		return packit.Process{
+			Type:    HealthcheckProcess,
+			Command: "npm",
+			Args:    jobArgs(name, workingDir, projectPath, silent),
+			Direct:  true,
+		}, reason, nil
+	}
+
+	command, args := chain.Executable()
+	return packit.Process{
+		Type:    HealthcheckProcess,
+		Command: command,
+		Args:    args,
+		Direct:  true,
+	}, "", nil
+}
+
+// readsNpmVariables reports whether command refers to a variable that npm
+// sets and that is not in exported.
+func readsNpmVariables(command string, exported map[string]string) bool {
+	for _, match := range npmVariable.FindAllStringSubmatch(command, -1) {
+		if _, ok := exported[match[1]]; !ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -13,5 +13,26 @@ func TestUnitGoBuild(t *testing.T) {
 	suite("Detect", testDetect)
 	suite("ProjectPathParser", testProjectPathParser)
 	suite("PackageJsonParser", testPackageJsonParser)
+	suite("StartChain", testStartChain)
+	suite("CachingPathParser", testCachingPathParser)
+	suite("Options", testOptions)
+	suite("ProcessEnv", testProcessEnv)
+	suite("ScriptClass", testScriptClass)
+	suite("Errors", testErrors)
+	suite("DetectSteps", testDetectSteps)
+	suite("Redactor", testRedactor)
+	suite("Npx", testNpx)
+	suite("ChainPathParser", testChainPathParser)
+	suite("Preview", testPreview)
+	suite("Labels", testLabels)
+	suite("NodeOptions", testNodeOptions)
+	suite("NodeInvocation", testNodeInvocation)
+	suite("Atomic", testAtomic)
+	suite("Wrappers", testWrappers)
+	suite("Descriptor", testDescriptor)
+	suite("PackageConfig", testPackageConfig)
+	suite("Tracing", testTracing)
+	suite("ScriptExcerpt", testScriptExcerpt)
+	suite("Configuration", testConfiguration)
 	suite.Run(t)
 }
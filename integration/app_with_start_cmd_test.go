@@ -5,9 +5,9 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"path/filepath"
 	"testing"
 
+	"github.com/paketo-buildpacks/npm-start/testing/fixtures"
 	"github.com/paketo-buildpacks/occam"
 	"github.com/sclevine/spec"
 
@@ -53,7 +53,7 @@ func testAppWithStartCmd(t *testing.T, context spec.G, it spec.S) {
 
 		it("builds a working OCI image and runs given start cmd", func() {
 			var err error
-			source, err = occam.Source(filepath.Join("testdata", "app_with_start_cmd"))
+			source, err = generateSource(fixtures.Scenarios()["start-command"])
 			Expect(err).NotTo(HaveOccurred())
 
 			var logs fmt.Stringer
@@ -6,10 +6,10 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/paketo-buildpacks/npm-start/testing/fixtures"
 	"github.com/paketo-buildpacks/occam"
 	"github.com/sclevine/spec"
 
@@ -56,7 +56,7 @@ func testGracefulShutdown(t *testing.T, context spec.G, it spec.S) {
 
 		it("builds a working OCI image and gracefully shuts down", func() {
 			var err error
-			source, err = occam.Source(filepath.Join("testdata", "graceful_shutdown_app"))
+			source, err = generateSource(fixtures.Scenarios()["graceful-shutdown"])
 			Expect(err).NotTo(HaveOccurred())
 
 			var logs fmt.Stringer
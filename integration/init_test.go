@@ -2,12 +2,14 @@ package integration_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/npm-start/testing/fixtures"
 	"github.com/paketo-buildpacks/occam"
 	"github.com/paketo-buildpacks/occam/packagers"
 	"github.com/sclevine/spec"
@@ -87,5 +89,22 @@ func TestIntegration(t *testing.T) {
 	suite("GracefulShutdown", testGracefulShutdown)
 	suite("ProjectPath", testProjectPath)
 	suite("StartCommand", testAppWithStartCmd)
+	suite("Jobs", testJobs)
 	suite.Run(t)
 }
+
+// generateSource writes the given fixture app into a new temporary directory
+// and returns that directory.
+func generateSource(app fixtures.App) (string, error) {
+	source, err := os.MkdirTemp("", "source")
+	if err != nil {
+		return "", fmt.Errorf("failed to create the source directory: %w", err)
+	}
+
+	err = app.Generate(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate the %s app: %w", app.Name, err)
+	}
+
+	return source, nil
+}
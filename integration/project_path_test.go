@@ -5,9 +5,9 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"path/filepath"
 	"testing"
 
+	"github.com/paketo-buildpacks/npm-start/testing/fixtures"
 	"github.com/paketo-buildpacks/occam"
 	"github.com/sclevine/spec"
 
@@ -52,8 +52,10 @@ func testProjectPath(t *testing.T, context spec.G, it spec.S) {
 		})
 
 		it("builds a working OCI image and runs given start cmd", func() {
+			app := fixtures.Scenarios()["project-path"]
+
 			var err error
-			source, err = occam.Source(filepath.Join("testdata", "project_path_app"))
+			source, err = generateSource(app)
 			Expect(err).NotTo(HaveOccurred())
 
 			var logs fmt.Stringer
@@ -64,7 +66,7 @@ func testProjectPath(t *testing.T, context spec.G, it spec.S) {
 					settings.Buildpacks.NPMStart.Online,
 				).
 				WithPullPolicy("never").
-				WithEnv(map[string]string{"BP_NODE_PROJECT_PATH": "server"}).
+				WithEnv(app.Env()).
 				Execute(name, source)
 			Expect(err).NotTo(HaveOccurred(), logs.String())
 
@@ -106,8 +108,10 @@ func testProjectPath(t *testing.T, context spec.G, it spec.S) {
 
 		context("when BP_LIVE_RELOAD_ENABLED=true during the build", func() {
 			it("makes the default process reloadable and watches the correct subdirectory", func() {
+				app := fixtures.Scenarios()["live-reload"]
+
 				var err error
-				source, err = occam.Source(filepath.Join("testdata", "project_path_app"))
+				source, err = generateSource(app)
 				Expect(err).NotTo(HaveOccurred())
 
 				var logs fmt.Stringer
@@ -119,10 +123,7 @@ func testProjectPath(t *testing.T, context spec.G, it spec.S) {
 						settings.Buildpacks.NPMStart.Online,
 					).
 					WithPullPolicy("never").
-					WithEnv(map[string]string{
-						"BP_NODE_PROJECT_PATH":   "server",
-						"BP_LIVE_RELOAD_ENABLED": "true",
-					}).
+					WithEnv(app.Env()).
 					Execute(name, source)
 				Expect(err).NotTo(HaveOccurred(), logs.String())
 
@@ -0,0 +1,62 @@
+package npmstart
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// JobEnv is set in the launch environment of every job process, so that
+// launch helpers can tell a job that runs once and exits from a server.
+const JobEnv = "BPL_NPM_START_JOB"
+
+// processType matches the names that the lifecycle accepts as process types.
+var processType = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ParseJobs parses the value of $BP_NPM_START_JOBS, a list of package.json
+// script names separated by ",". Each script must be defined by the
+// package.json and its name must be usable as a process type.
+func ParseJobs(value string, scripts PackageScripts) ([]string, error) {
+	var (
+		jobs []string
+		seen = map[string]bool{}
+	)
+
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if _, ok := scripts.Script(name); !ok {
+			return nil, classify(ErrInvalidConfiguration, fmt.Errorf("BP_NPM_START_JOBS names the script %q, which package.json does not define", name))
+		}
+
+		if !processType.MatchString(name) {
+			return nil, classify(ErrInvalidConfiguration, fmt.Errorf("BP_NPM_START_JOBS names the script %q, which is not a valid process type: use letters, digits, \".\", \"-\" and \"_\" only", name))
+		}
+
+		jobs = append(jobs, name)
+	}
+
+	return jobs, nil
+}
+
+// jobArgs returns the arguments with which npm runs the script of a job.
+// npm runs the script in the project path, which is passed with --prefix when
+// it is not the working directory that processes are launched in. When silent
+// is set, npm does not print its banner ahead of the output of the script.
+func jobArgs(name, workingDir, projectPath string, silent bool) []string {
+	var args []string
+	if projectPath != workingDir {
+		args = append(args, "--prefix", projectPath)
+	}
+
+	args = append(args, "run", name)
+	if silent {
+		args = append(args, "--silent")
+	}
+
+	return args
+}
@@ -0,0 +1,54 @@
+package npmstart
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ReservedLabelPrefix is the prefix of the image labels that the lifecycle
+// writes, which BP_NPM_START_LABELS cannot set.
+const ReservedLabelPrefix = "io.buildpacks."
+
+// BuildpackLabelPrefix is the prefix of the image labels that the buildpack
+// sets itself, which BP_NPM_START_LABELS cannot set either.
+const BuildpackLabelPrefix = "io.paketo.npm-start."
+
+// labelKey matches the keys of image labels: alphanumeric characters
+// separated by ".", "-", "_" or "/", eg. autoscaling.knative.dev/target.
+var labelKey = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]*[A-Za-z0-9])?$`)
+
+// ParseLabels parses the value of $BP_NPM_START_LABELS, a list of
+// <key>=<value> entries separated by ",". A literal "," or "\" in a value is
+// written as "\," or "\\"; the key ends at the first "=", so values may
+// contain "=" as is.
+func ParseLabels(value string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, entry := range splitEscaped(value, ',') {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse BP_NPM_START_LABELS entry %q: expected <key>=<value>", entry))
+		}
+		key, val := strings.TrimSpace(parts[0]), parts[1]
+
+		if !labelKey.MatchString(key) {
+			return nil, classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse BP_NPM_START_LABELS entry %q: %q is not a valid label key", entry, key))
+		}
+
+		if strings.HasPrefix(key, ReservedLabelPrefix) {
+			return nil, classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse BP_NPM_START_LABELS entry %q: keys starting with %s are reserved for the lifecycle", entry, ReservedLabelPrefix))
+		}
+
+		if strings.ContainsAny(val, "\r\n") {
+			return nil, classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse BP_NPM_START_LABELS entry %q: values must be a single line", entry))
+		}
+
+		labels[key] = val
+	}
+
+	return labels, nil
+}
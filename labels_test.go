@@ -0,0 +1,70 @@
+package npmstart_test
+
+import (
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testLabels(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseLabels", func() {
+		it("parses each entry", func() {
+			labels, err := npmstart.ParseLabels("autoscaling.knative.dev/target=10, autoscaling.knative.dev/class=kpa.autoscaling.knative.dev")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).To(Equal(map[string]string{
+				"autoscaling.knative.dev/target": "10",
+				"autoscaling.knative.dev/class":  "kpa.autoscaling.knative.dev",
+			}))
+		})
+
+		it("unescapes separators and backslashes in values and keeps equals signs", func() {
+			labels, err := npmstart.ParseLabels(`some-list=a\,b\\,some-query=a=1&b=2`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).To(Equal(map[string]string{
+				"some-list":  `a,b\`,
+				"some-query": "a=1&b=2",
+			}))
+		})
+
+		it("allows empty values and ignores empty entries", func() {
+			labels, err := npmstart.ParseLabels("some-key=,,")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).To(Equal(map[string]string{"some-key": ""}))
+		})
+
+		it("returns nothing for an empty value", func() {
+			labels, err := npmstart.ParseLabels("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).To(BeEmpty())
+		})
+
+		context("failure cases", func() {
+			it("rejects entries without a value", func() {
+				_, err := npmstart.ParseLabels("some-key")
+				Expect(err).To(MatchError(`failed to parse BP_NPM_START_LABELS entry "some-key": expected <key>=<value>`))
+			})
+
+			it("rejects invalid keys", func() {
+				for _, entry := range []string{"=value", "some key=value", ".some-key=value", "some-key/=value", "some:key=value"} {
+					_, err := npmstart.ParseLabels(entry)
+					Expect(err).To(MatchError(ContainSubstring("is not a valid label key")), entry)
+				}
+			})
+
+			it("rejects keys reserved for the lifecycle", func() {
+				_, err := npmstart.ParseLabels("io.buildpacks.build.metadata=some-value")
+				Expect(err).To(MatchError(`failed to parse BP_NPM_START_LABELS entry "io.buildpacks.build.metadata=some-value": keys starting with io.buildpacks. are reserved for the lifecycle`))
+			})
+
+			it("rejects values spanning several lines", func() {
+				_, err := npmstart.ParseLabels("some-key=first\nsecond")
+				Expect(err).To(MatchError(ContainSubstring("values must be a single line")))
+			})
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package npmstart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// MaintenanceServer is the name of the helper executable, shipped in the
+// buildpack bin directory, that serves the maintenance placeholder page.
+const MaintenanceServer = "maintenance-server"
+
+// maintenanceProcess returns a non-default process that runs the
+// maintenance server installed by installMaintenanceServer into the layers
+// directory. When BP_NPM_START_MAINTENANCE_PAGE is set, the referenced file
+// (relative to the working directory) is served in place of the built-in 503
+// page.
+func maintenanceProcess(page, workingDir, layersPath string) (packit.Process, error) {
+	var args []string
+	if page != "" {
+		if !filepath.IsAbs(page) {
+			page = filepath.Join(workingDir, page)
+		}
+
+		_, err := os.Stat(page)
+		if err != nil {
+			return packit.Process{}, classify(ErrInvalidConfiguration, fmt.Errorf("failed to find maintenance page: %w", err))
+		}
+
+		args = []string{"--page", page}
+	}
+
+	return packit.Process{
+		Type:    "maintenance",
+		Command: filepath.Join(layersPath, "maintenance", "bin", MaintenanceServer),
+		Args:    args,
+		Direct:  true,
+	}, nil
+}
+
+// installMaintenanceServer installs the maintenance server into a launch
+// layer.
+func installMaintenanceServer(context packit.BuildContext) (packit.Layer, error) {
+	layer, err := resetLayer(context.Layers, "maintenance")
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	layer.Launch = true
+
+	err = os.MkdirAll(filepath.Join(layer.Path, "bin"), os.ModePerm)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	err = copyFileAtomic(filepath.Join(context.CNBPath, "bin", MaintenanceServer), filepath.Join(layer.Path, "bin", MaintenanceServer))
+	if err != nil {
+		return packit.Layer{}, fmt.Errorf("failed to install %s: %w", MaintenanceServer, err)
+	}
+
+	return layer, nil
+}
@@ -0,0 +1,54 @@
+package npmstart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MandatoryNodeArgsFile is the file, relative to the buildpack directory, in
+// which a builder lists the node flags that every process must run with, eg.
+// "--require /platform/agent.js". It is placed there when the builder is
+// assembled.
+const MandatoryNodeArgsFile = "config/mandatory-node-args"
+
+// MandatoryNodeArgsLabel is the image label that records the mandatory node
+// arguments that were injected.
+const MandatoryNodeArgsLabel = "io.paketo.npm-start.mandatory-node-args"
+
+// readMandatoryNodeArgs returns the flags of the MandatoryNodeArgsFile of the
+// buildpack at cnbPath as a NODE_OPTIONS value, or an empty value when the
+// file does not exist. Lines are joined with spaces; blank lines and lines
+// starting with "#" are skipped.
+func readMandatoryNodeArgs(cnbPath string) (string, error) {
+	if cnbPath == "" {
+		return "", nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(cnbPath, MandatoryNodeArgsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", MandatoryNodeArgsFile, err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	// Merging the value on its own validates its flags and drops repeated
+	// ones.
+	value, err := MergeNodeOptions(strings.Join(lines, " "))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", MandatoryNodeArgsFile, err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,83 @@
+package npmstart
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GlobalPackages lists npm packages that are often installed globally on
+// developer machines and then run by start scripts without being declared as
+// dependencies.
+var GlobalPackages = map[string]bool{
+	"forever":     true,
+	"http-server": true,
+	"nodemon":     true,
+	"pm2":         true,
+	"serve":       true,
+}
+
+// shellBuiltins are commands that the shell provides itself.
+var shellBuiltins = map[string]bool{
+	".":      true,
+	":":      true,
+	"[":      true,
+	"cd":     true,
+	"echo":   true,
+	"exec":   true,
+	"export": true,
+	"false":  true,
+	"set":    true,
+	"test":   true,
+	"true":   true,
+}
+
+// runtimeCommands are provided at launch by the node and npm buildpacks and
+// the run image. They are never reported missing, whether or not the build
+// has them on its PATH.
+var runtimeCommands = map[string]bool{
+	"bash": true,
+	"node": true,
+	"npm":  true,
+	"npx":  true,
+	"sh":   true,
+}
+
+// missingCommands returns the commands run by the given script that are in
+// none of the binPaths directories. The PATH of the build is not consulted:
+// it holds the layers of the build, not those of the launch, so a command
+// found there may be missing at launch and one missing there may be
+// provided. Commands run through npx are not checked, as npx installs them
+// itself.
+func missingCommands(script string, binPaths []string) []string {
+	var missing []string
+	for _, command := range scriptSeparator.Split(script, -1) {
+		fields := strings.Fields(command)
+		for len(fields) > 0 && fields[0] != "npx" && (envAssignment.MatchString(fields[0]) || scriptLaunchers[fields[0]]) {
+			fields = fields[1:]
+		}
+
+		if len(fields) == 0 || fields[0] == "npx" {
+			continue
+		}
+
+		name := fields[0]
+		if strings.Contains(name, "/") || shellBuiltins[name] || runtimeCommands[name] || hasLocalBin(name, binPaths) {
+			continue
+		}
+
+		missing = append(missing, name)
+	}
+
+	return missing
+}
+
+func hasLocalBin(name string, binPaths []string) bool {
+	for _, dir := range binPaths {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,116 @@
+package npmstart
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MonorepoRunner is a monorepo task runner that selects and starts the
+// workspace packages itself when it is run from the workspace root.
+type MonorepoRunner struct {
+	// Name is the executable of the runner.
+	Name string
+
+	// Config is the file at the workspace root that configures the runner.
+	Config string
+
+	// FilterFlag is the flag that selects the packages to run.
+	FilterFlag string
+
+	// FilterAliases are other spellings of FilterFlag.
+	FilterAliases []string
+}
+
+// MonorepoRunners lists the monorepo task runners recognized in the start
+// script at the workspace root.
+var MonorepoRunners = []MonorepoRunner{
+	{Name: "turbo", Config: "turbo.json", FilterFlag: "--filter", FilterAliases: []string{"-F"}},
+	{Name: "nx", Config: "nx.json", FilterFlag: "--projects", FilterAliases: []string{"-p"}},
+}
+
+// lookupMonorepoRunner returns the monorepo task runner that the start script
+// of the package.json at the workspace root runs, if the runner's config file
+// is also present there.
+func lookupMonorepoRunner(workingDir string) (MonorepoRunner, bool, error) {
+	pkg, err := NewPackageJsonFromPath(filepath.Join(workingDir, "package.json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return MonorepoRunner{}, false, nil
+		}
+		return MonorepoRunner{}, false, err
+	}
+
+	fields := strings.Fields(scriptSeparator.Split(pkg.Scripts.Start, 2)[0])
+	for len(fields) > 0 && (envAssignment.MatchString(fields[0]) || scriptLaunchers[fields[0]]) {
+		fields = fields[1:]
+	}
+
+	if len(fields) == 0 {
+		return MonorepoRunner{}, false, nil
+	}
+
+	for _, runner := range MonorepoRunners {
+		if fields[0] != runner.Name {
+			continue
+		}
+
+		_, err := os.Stat(filepath.Join(workingDir, runner.Config))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return MonorepoRunner{}, false, nil
+			}
+			return MonorepoRunner{}, false, fmt.Errorf("failed to stat %s: %w", runner.Config, err)
+		}
+
+		return runner, true, nil
+	}
+
+	return MonorepoRunner{}, false, nil
+}
+
+// resolveProjectPath returns the directory whose package.json provides the
+// start command. A workspace root whose start script runs a monorepo task
+// runner is used as is, since the runner selects the packages to start;
+// otherwise the path parser resolves BP_NODE_PROJECT_PATH.
+func resolveProjectPath(pathParser PathParser, workingDir string) (string, MonorepoRunner, bool, error) {
+	runner, ok, err := lookupMonorepoRunner(workingDir)
+	if err != nil {
+		return "", MonorepoRunner{}, false, err
+	}
+
+	if ok {
+		return workingDir, runner, true, nil
+	}
+
+	projectPath, err := pathParser.Get(workingDir)
+	if err != nil {
+		return "", MonorepoRunner{}, false, classify(ErrProjectPath, err)
+	}
+
+	return projectPath, MonorepoRunner{}, false, nil
+}
+
+// withFilter appends the filter flag with the given value to the start
+// script, unless the value is empty or the script already selects packages.
+func (r MonorepoRunner) withFilter(script, filter string) string {
+	if filter == "" || r.hasFilter(script) {
+		return script
+	}
+
+	return fmt.Sprintf("%s %s=%s", script, r.FilterFlag, shellQuote(filter))
+}
+
+func (r MonorepoRunner) hasFilter(script string) bool {
+	for _, field := range strings.Fields(script) {
+		for _, flag := range append([]string{r.FilterFlag}, r.FilterAliases...) {
+			if field == flag || strings.HasPrefix(field, flag+"=") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
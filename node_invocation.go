@@ -0,0 +1,197 @@
+package npmstart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExperimentalNodeVersion is the node version that Detect requires for start
+// scripts that rely on experimental node features, which older releases
+// either lack or implement differently.
+const ExperimentalNodeVersion = ">=18"
+
+// NodeVersionSourceStartScriptFlags is the version-source of the node
+// requirement that Detect makes for ExperimentalNodeVersion.
+const NodeVersionSourceStartScriptFlags = "start-script-flags"
+
+// NodeInvocation is a start script that consists of nothing but running a
+// file with node, eg. "node --experimental-wasm-modules server.mjs".
+type NodeInvocation struct {
+	// Flags are the node flags ahead of the entrypoint, as written.
+	Flags []string
+
+	// Entrypoint is the file that node runs.
+	Entrypoint string
+
+	// Args are the arguments given to the entrypoint.
+	Args []string
+}
+
+// ParseNodeInvocation returns the node invocation that makes up the given
+// script. Every word ahead of the entrypoint that starts with "-" is a node
+// flag, along with the value of the flags that take the next word as their
+// value. The boolean return is false when the script does more than run a
+// file with node.
+func ParseNodeInvocation(script string) (NodeInvocation, bool) {
+	if scriptSeparator.MatchString(script) {
+		return NodeInvocation{}, false
+	}
+
+	fields := strings.Fields(script)
+	if len(fields) < 2 || fields[0] != Node {
+		return NodeInvocation{}, false
+	}
+
+	var invocation NodeInvocation
+	for i := 1; i < len(fields); i++ {
+		field := fields[i]
+		if !strings.HasPrefix(field, "-") {
+			invocation.Entrypoint = field
+			invocation.Args = fields[i+1:]
+			return invocation, true
+		}
+
+		invocation.Flags = append(invocation.Flags, field)
+		if separateValueFlags[field] && i+1 < len(fields) {
+			i++
+			invocation.Flags = append(invocation.Flags, fields[i])
+		}
+	}
+
+	return NodeInvocation{}, false
+}
+
+// Experimental reports whether the invocation relies on an experimental node
+// feature: an --experimental-* flag, or a WebAssembly entrypoint.
+func (i NodeInvocation) Experimental() bool {
+	for _, flag := range i.Flags {
+		if strings.HasPrefix(flag, "--experimental-") {
+			return true
+		}
+	}
+
+	return strings.HasSuffix(i.Entrypoint, ".wasm")
+}
+
+// ResolveEntrypoint returns the file that node runs for the entrypoint when
+// started in dir, following the rules of node for the main module: the
+// entrypoint itself or with a .js, .json or .node extension, and for a
+// directory, such as "." in "node .", the main file of its package.json
+// before its index file. The returned path is relative to dir when the
+// entrypoint is.
+func (i NodeInvocation) ResolveEntrypoint(dir string) (string, error) {
+	path := i.Entrypoint
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	resolved, ok := resolveNodeFile(path)
+	if !ok {
+		resolved, ok = resolveNodeDirectory(path)
+	}
+	if !ok {
+		if variants := caseVariants(i.Entrypoint, path); len(variants) > 0 {
+			return "", EntrypointCaseError{Entrypoint: i.Entrypoint, Variants: variants}
+		}
+
+		return "", fmt.Errorf("node cannot resolve the entrypoint %s: no such file, nor a directory with a main or index file", i.Entrypoint)
+	}
+
+	if !filepath.IsAbs(i.Entrypoint) {
+		if rel, err := filepath.Rel(dir, resolved); err == nil {
+			return rel, nil
+		}
+	}
+
+	return resolved, nil
+}
+
+// resolveNodeFile resolves path as node loads a file.
+func resolveNodeFile(path string) (string, bool) {
+	for _, extension := range []string{"", ".js", ".json", ".node"} {
+		if info, err := os.Stat(path + extension); err == nil && !info.IsDir() {
+			return path + extension, true
+		}
+	}
+
+	return "", false
+}
+
+// resolveNodeDirectory resolves path as node loads a directory: through the
+// main field of its package.json, falling back to its index file.
+func resolveNodeDirectory(path string) (string, bool) {
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return "", false
+	}
+
+	if pkg, err := NewPackageJsonFromPath(filepath.Join(path, "package.json")); err == nil && pkg.Main != "" {
+		main := filepath.Join(path, pkg.Main)
+		if resolved, ok := resolveNodeFile(main); ok {
+			return resolved, true
+		}
+
+		if resolved, ok := resolveNodeFile(filepath.Join(main, "index")); ok {
+			return resolved, true
+		}
+	}
+
+	return resolveNodeFile(filepath.Join(path, "index"))
+}
+
+// EntrypointCaseError is returned by ResolveEntrypoint when node cannot
+// resolve the entrypoint, but files in its directory have names that differ
+// from it only in case, as when "./Server.js" is written on a
+// case-insensitive file system for a server.js file.
+type EntrypointCaseError struct {
+	// Entrypoint is the entrypoint as written in the start script.
+	Entrypoint string
+
+	// Variants are the spellings of the entrypoint, in the form it is written
+	// in, that name the files that node would resolve.
+	Variants []string
+}
+
+func (e EntrypointCaseError) Error() string {
+	if len(e.Variants) == 1 {
+		return fmt.Sprintf("node cannot resolve the entrypoint %s: no such file, but %s differs from it only in case", e.Entrypoint, e.Variants[0])
+	}
+
+	return fmt.Sprintf("node cannot resolve the entrypoint %s: no such file, but %s differ from it only in case", e.Entrypoint, strings.Join(e.Variants, ", "))
+}
+
+// caseVariants returns the spellings of entrypoint that name a file or
+// directory in the parent of path which node would resolve and whose name
+// equals the base of path, or the base with a .js, .json or .node extension,
+// regardless of case.
+func caseVariants(entrypoint, path string) []string {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+
+	base := filepath.Base(path)
+	prefix := strings.TrimSuffix(entrypoint, filepath.Base(entrypoint))
+
+	var variants []string
+	for _, entry := range entries {
+		for _, extension := range []string{"", ".js", ".json", ".node"} {
+			if !strings.EqualFold(entry.Name(), base+extension) {
+				continue
+			}
+
+			candidate := filepath.Join(filepath.Dir(path), entry.Name())
+			if _, ok := resolveNodeFile(candidate); !ok {
+				if _, ok := resolveNodeDirectory(candidate); !ok {
+					continue
+				}
+			}
+
+			variants = append(variants, prefix+entry.Name())
+			break
+		}
+	}
+
+	return variants
+}
@@ -0,0 +1,189 @@
+package npmstart_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testNodeInvocation(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseNodeInvocation", func() {
+		it("keeps every flag ahead of the entrypoint as written", func() {
+			invocation, ok := npmstart.ParseNodeInvocation("node --experimental-wasm-modules --max-old-space-size=4096 -r ./tracing.js server.mjs --port 8080")
+			Expect(ok).To(BeTrue())
+			Expect(invocation).To(Equal(npmstart.NodeInvocation{
+				Flags:      []string{"--experimental-wasm-modules", "--max-old-space-size=4096", "-r", "./tracing.js"},
+				Entrypoint: "server.mjs",
+				Args:       []string{"--port", "8080"},
+			}))
+		})
+
+		it("parses scripts without flags", func() {
+			invocation, ok := npmstart.ParseNodeInvocation("node server.js")
+			Expect(ok).To(BeTrue())
+			Expect(invocation).To(Equal(npmstart.NodeInvocation{Entrypoint: "server.js", Args: []string{}}))
+		})
+
+		it("rejects scripts that do more than run node", func() {
+			for _, script := range []string{
+				"",
+				"node",
+				"node --inspect",
+				"npm run serve",
+				"node migrate.js && node server.js",
+				"NODE_ENV=production node server.js",
+			} {
+				_, ok := npmstart.ParseNodeInvocation(script)
+				Expect(ok).To(BeFalse(), script)
+			}
+		})
+	})
+
+	context("Experimental", func() {
+		it("reports experimental flags and WebAssembly entrypoints", func() {
+			for script, experimental := range map[string]bool{
+				"node --experimental-wasm-modules server.mjs": true,
+				"node --experimental-loader ./loader.mjs app": true,
+				"node app.wasm": true,
+				"node --enable-source-maps --no-warnings app": false,
+				"node --max-old-space-size=4096 server.js":    false,
+				"node server.js --experimental-flag-for-app":  false,
+			} {
+				invocation, ok := npmstart.ParseNodeInvocation(script)
+				Expect(ok).To(BeTrue(), script)
+				Expect(invocation.Experimental()).To(Equal(experimental), script)
+			}
+		})
+	})
+
+	context("ResolveEntrypoint", func() {
+		var dir string
+
+		it.Before(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "project")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		resolve := func(script string) (string, error) {
+			invocation, ok := npmstart.ParseNodeInvocation(script)
+			Expect(ok).To(BeTrue(), script)
+
+			return invocation.ResolveEntrypoint(dir)
+		}
+
+		it("resolves . through the main field of the package.json", func() {
+			Expect(os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"main": "lib/server.js"}`), 0600)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "lib"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "lib", "server.js"), nil, 0600)).To(Succeed())
+
+			for _, script := range []string{"node .", "node ./", "node --watch .", "node --watch-path=./lib ./ --port 8080"} {
+				entrypoint, err := resolve(script)
+				Expect(err).NotTo(HaveOccurred(), script)
+				Expect(entrypoint).To(Equal(filepath.Join("lib", "server.js")), script)
+			}
+		})
+
+		it("resolves a main without extension and a main directory", func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "lib"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "lib", "index.js"), nil, 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "server.js"), nil, 0600)).To(Succeed())
+
+			Expect(os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"main": "server"}`), 0600)).To(Succeed())
+			Expect(resolve("node .")).To(Equal("server.js"))
+
+			Expect(os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"main": "lib"}`), 0600)).To(Succeed())
+			Expect(resolve("node .")).To(Equal(filepath.Join("lib", "index.js")))
+		})
+
+		it("falls back to the index file of the directory", func() {
+			Expect(os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"main": "missing.js"}`), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "index.js"), nil, 0600)).To(Succeed())
+
+			Expect(resolve("node .")).To(Equal("index.js"))
+		})
+
+		it("resolves a subdirectory through its own package.json", func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "packages", "api"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "packages", "api", "package.json"), []byte(`{"main": "main.mjs"}`), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "packages", "api", "main.mjs"), nil, 0600)).To(Succeed())
+
+			Expect(resolve("node packages/api")).To(Equal(filepath.Join("packages", "api", "main.mjs")))
+		})
+
+		it("resolves files with and without extension", func() {
+			Expect(os.WriteFile(filepath.Join(dir, "server.js"), nil, 0600)).To(Succeed())
+
+			Expect(resolve("node server.js")).To(Equal("server.js"))
+			Expect(resolve("node server")).To(Equal("server.js"))
+		})
+
+		context("when a directory has neither a main nor an index file", func() {
+			it("returns an error", func() {
+				Expect(os.MkdirAll(filepath.Join(dir, "lib"), os.ModePerm)).To(Succeed())
+
+				_, err := resolve("node lib")
+				Expect(err).To(MatchError("node cannot resolve the entrypoint lib: no such file, nor a directory with a main or index file"))
+
+				_, err = resolve("node .")
+				Expect(err).To(MatchError("node cannot resolve the entrypoint .: no such file, nor a directory with a main or index file"))
+			})
+		})
+
+		context("when the entrypoint differs from a file only in case", func() {
+			it("resolves an exact match as it is", func() {
+				Expect(os.WriteFile(filepath.Join(dir, "server.js"), nil, 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dir, "Server.js"), nil, 0600)).To(Succeed())
+
+				Expect(resolve("node ./Server.js")).To(Equal("Server.js"))
+			})
+
+			it("names the file that differs only in case", func() {
+				Expect(os.MkdirAll(filepath.Join(dir, "src"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dir, "src", "server.js"), nil, 0600)).To(Succeed())
+
+				_, err := resolve("node ./src/Server.js")
+				Expect(err).To(MatchError("node cannot resolve the entrypoint ./src/Server.js: no such file, but ./src/server.js differs from it only in case"))
+
+				var caseErr npmstart.EntrypointCaseError
+				Expect(errors.As(err, &caseErr)).To(BeTrue())
+				Expect(caseErr.Variants).To(Equal([]string{"./src/server.js"}))
+
+				_, err = resolve("node src/SERVER")
+				Expect(err).To(MatchError("node cannot resolve the entrypoint src/SERVER: no such file, but src/server.js differs from it only in case"))
+			})
+
+			it("names a directory that node would resolve", func() {
+				Expect(os.MkdirAll(filepath.Join(dir, "lib"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dir, "lib", "index.js"), nil, 0600)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(dir, "empty"), os.ModePerm)).To(Succeed())
+
+				_, err := resolve("node Lib")
+				Expect(err).To(MatchError("node cannot resolve the entrypoint Lib: no such file, but lib differs from it only in case"))
+
+				_, err = resolve("node Empty")
+				Expect(err).To(MatchError("node cannot resolve the entrypoint Empty: no such file, nor a directory with a main or index file"))
+			})
+
+			it("names every variant when there are several", func() {
+				Expect(os.WriteFile(filepath.Join(dir, "server.js"), nil, 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dir, "SERVER.js"), nil, 0600)).To(Succeed())
+
+				_, err := resolve("node Server.js")
+				Expect(err).To(MatchError("node cannot resolve the entrypoint Server.js: no such file, but SERVER.js, server.js differ from it only in case"))
+			})
+		})
+	})
+}
@@ -0,0 +1,139 @@
+package npmstart
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// locateNodeModules returns the node_modules directory from which the
+// project's dependencies will be resolved at launch. A node_modules directory
+// in the project path is always used when it exists. Otherwise, the parent
+// directories of the project path, up to and including the working
+// directory, are searched for a node_modules directory that contains every
+// dependency. The boolean return is false when no such directory exists.
+func locateNodeModules(workingDir, projectPath string, dependencies map[string]string) (string, bool, error) {
+	local := filepath.Join(projectPath, NodeModules)
+	_, err := os.Stat(local)
+	if err == nil {
+		return local, true, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", false, fmt.Errorf("failed to stat %s: %w", local, err)
+	}
+
+	if len(dependencies) == 0 {
+		return "", false, nil
+	}
+
+	dir := projectPath
+	for dir != workingDir {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+
+		candidate := filepath.Join(dir, NodeModules)
+		satisfied, err := containsDependencies(candidate, dependencies)
+		if err != nil {
+			return "", false, err
+		}
+
+		if satisfied {
+			return candidate, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+func containsDependencies(nodeModules string, dependencies map[string]string) (bool, error) {
+	for name := range dependencies {
+		_, err := os.Stat(filepath.Join(nodeModules, filepath.FromSlash(name)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+
+			return false, fmt.Errorf("failed to stat %s: %w", filepath.Join(nodeModules, name), err)
+		}
+	}
+
+	return true, nil
+}
+
+// localBinPaths returns the directories holding locally installed executables
+// that should be on the PATH when the start command runs: the project's
+// node_modules/.bin, the directory named by "directories.bin" in its
+// package.json, and, when the project is a member of a workspace rooted at
+// the working directory, the workspace root's node_modules/.bin.
+func localBinPaths(workingDir, projectPath string, pkg PackageJson) ([]string, error) {
+	paths := []string{filepath.Join(projectPath, NodeModules, ".bin")}
+
+	if pkg.Directories.Bin != "" {
+		paths = append(paths, filepath.Join(projectPath, pkg.Directories.Bin))
+	}
+
+	if projectPath != workingDir {
+		root, err := NewPackageJsonFromPath(filepath.Join(workingDir, "package.json"))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		} else if root.hasWorkspaces() {
+			paths = append(paths, filepath.Join(workingDir, NodeModules, ".bin"))
+		}
+	}
+
+	return paths, nil
+}
+
+// staleNodeModulesLink reports whether node_modules in the project path is a
+// symlink into the layers root whose target does not exist, as happens when
+// the buildpack that installed the modules in a previous build has left the
+// buildpack group. It returns the target of the symlink and the ID of the
+// buildpack that owns the layer it points into.
+func staleNodeModulesLink(projectPath, layersRoot string) (string, string, bool, error) {
+	path := filepath.Join(projectPath, NodeModules)
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", false, nil
+		}
+
+		return "", "", false, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		return "", "", false, nil
+	}
+
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to read symlink %s: %w", path, err)
+	}
+
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(projectPath, target)
+	}
+
+	rel, err := filepath.Rel(layersRoot, target)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", "", false, nil
+	}
+
+	_, err = os.Stat(target)
+	if err == nil || !os.IsNotExist(err) {
+		return "", "", false, nil
+	}
+
+	// The lifecycle names the layers directory of a buildpack after its ID,
+	// with every "/" replaced by "_".
+	owner := strings.ReplaceAll(strings.Split(filepath.ToSlash(rel), "/")[0], "_", "/")
+
+	return target, owner, true, nil
+}
@@ -0,0 +1,202 @@
+package npmstart
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NodeOption is a single flag of a NODE_OPTIONS value.
+type NodeOption struct {
+	// Flag is the flag as written, eg. "--max-old-space-size" or "-r".
+	Flag string
+
+	// Value is the value of the flag, or empty for a boolean flag.
+	Value string
+
+	// Separate indicates that the value follows the flag as a separate word,
+	// as in "--require ./tracing.js", rather than after "=".
+	Separate bool
+}
+
+// separateValueFlags are the flags that NODE_OPTIONS accepts with their value
+// as the next word.
+var separateValueFlags = map[string]bool{
+	"-r":                    true,
+	"--require":             true,
+	"--import":              true,
+	"--loader":              true,
+	"--experimental-loader": true,
+	"--title":               true,
+}
+
+// repeatableFlags are the flags that node applies once per occurrence rather
+// than honoring the last one.
+var repeatableFlags = map[string]bool{
+	"--require":             true,
+	"--import":              true,
+	"--loader":              true,
+	"--experimental-loader": true,
+}
+
+// flagAliases maps short flags to the long flag that they stand for.
+var flagAliases = map[string]string{
+	"-r": "--require",
+}
+
+// name returns the name that identifies the flag when options are merged:
+// aliases resolve to their long flag, and "--no-<name>" toggles share the
+// name of "--<name>".
+func (o NodeOption) name() string {
+	flag := o.Flag
+	if alias, ok := flagAliases[flag]; ok {
+		flag = alias
+	}
+
+	return strings.Replace(flag, "--no-", "--", 1)
+}
+
+// String returns the option as written in NODE_OPTIONS.
+func (o NodeOption) String() string {
+	switch {
+	case o.Value == "" && !o.Separate:
+		return o.Flag
+	case o.Separate:
+		return o.Flag + " " + quoteNodeOption(o.Value)
+	default:
+		return o.Flag + "=" + quoteNodeOption(o.Value)
+	}
+}
+
+// ParseNodeOptions splits a NODE_OPTIONS value into its flags. Words are
+// separated by whitespace; double quotes group words, and a backslash inside
+// them escapes the next character, as node reads them.
+func ParseNodeOptions(value string) ([]NodeOption, error) {
+	words, err := splitNodeOptions(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []NodeOption
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "-") {
+			return nil, fmt.Errorf("failed to parse NODE_OPTIONS value %s: %q is not a flag", value, word)
+		}
+
+		if parts := strings.SplitN(word, "=", 2); len(parts) == 2 {
+			options = append(options, NodeOption{Flag: parts[0], Value: parts[1]})
+			continue
+		}
+
+		if separateValueFlags[word] {
+			if i+1 == len(words) {
+				return nil, fmt.Errorf("failed to parse NODE_OPTIONS value %s: %s expects a value", value, word)
+			}
+
+			options = append(options, NodeOption{Flag: word, Value: words[i+1], Separate: true})
+			i++
+			continue
+		}
+
+		options = append(options, NodeOption{Flag: word})
+	}
+
+	return options, nil
+}
+
+// MergeNodeOptions merges the NODE_OPTIONS contributions of several features
+// into a single value. Contributions are given from the lowest precedence to
+// the highest: buildpack defaults first, then the BPL_* variables, then the
+// environment the user sets at launch. A flag that occurs more than once
+// keeps the value of its highest precedence occurrence, at the position of
+// its first occurrence; repeatable flags such as --require keep every
+// distinct value.
+func MergeNodeOptions(contributions ...string) (string, error) {
+	var (
+		merged  []NodeOption
+		indices = map[string]int{}
+	)
+
+	for _, contribution := range contributions {
+		options, err := ParseNodeOptions(contribution)
+		if err != nil {
+			return "", err
+		}
+
+		for _, option := range options {
+			name := option.name()
+			key := name
+			if repeatableFlags[name] {
+				key = name + "\x00" + option.Value
+			}
+
+			if i, ok := indices[key]; ok {
+				if !repeatableFlags[name] {
+					merged[i] = option
+				}
+				continue
+			}
+
+			indices[key] = len(merged)
+			merged = append(merged, option)
+		}
+	}
+
+	var words []string
+	for _, option := range merged {
+		words = append(words, option.String())
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+func splitNodeOptions(value string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quoted  bool
+		escaped bool
+	)
+
+	for _, r := range value {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("failed to parse NODE_OPTIONS value %s: unterminated quote", value)
+	}
+
+	if inWord {
+		words = append(words, current.String())
+	}
+
+	return words, nil
+}
+
+// quoteNodeOption quotes a value that NODE_OPTIONS would otherwise split.
+func quoteNodeOption(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\"\\") {
+		return value
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
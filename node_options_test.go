@@ -0,0 +1,109 @@
+package npmstart_test
+
+import (
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testNodeOptions(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseNodeOptions", func() {
+		it("parses each flag", func() {
+			options, err := npmstart.ParseNodeOptions(`--max-old-space-size=4096  -r ./tracing.js --enable-source-maps --title "some app"`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(options).To(Equal([]npmstart.NodeOption{
+				{Flag: "--max-old-space-size", Value: "4096"},
+				{Flag: "-r", Value: "./tracing.js", Separate: true},
+				{Flag: "--enable-source-maps"},
+				{Flag: "--title", Value: "some app", Separate: true},
+			}))
+		})
+
+		it("unescapes quoted values", func() {
+			options, err := npmstart.ParseNodeOptions(`--require "./some \"odd\" dir/hook.js"`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(options).To(Equal([]npmstart.NodeOption{
+				{Flag: "--require", Value: `./some "odd" dir/hook.js`, Separate: true},
+			}))
+		})
+
+		context("failure cases", func() {
+			it("rejects words that are not flags", func() {
+				_, err := npmstart.ParseNodeOptions("--inspect server.js")
+				Expect(err).To(MatchError(`failed to parse NODE_OPTIONS value --inspect server.js: "server.js" is not a flag`))
+			})
+
+			it("rejects flags missing their value", func() {
+				_, err := npmstart.ParseNodeOptions("--require")
+				Expect(err).To(MatchError(ContainSubstring("--require expects a value")))
+			})
+
+			it("rejects unterminated quotes", func() {
+				_, err := npmstart.ParseNodeOptions(`--title "some app`)
+				Expect(err).To(MatchError(ContainSubstring("unterminated quote")))
+			})
+		})
+	})
+
+	context("MergeNodeOptions", func() {
+		var (
+			defaults = "--max-old-space-size=1024 --enable-source-maps"
+			bpl      = "--max-old-space-size=2048 --no-warnings"
+			runtime  = "--max-old-space-size=4096 --warnings"
+		)
+
+		it("lets the runtime environment win over BPL_* variables", func() {
+			merged, err := npmstart.MergeNodeOptions(bpl, runtime)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged).To(Equal("--max-old-space-size=4096 --warnings"))
+		})
+
+		it("lets BPL_* variables win over buildpack defaults", func() {
+			merged, err := npmstart.MergeNodeOptions(defaults, bpl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged).To(Equal("--max-old-space-size=2048 --enable-source-maps --no-warnings"))
+		})
+
+		it("lets the runtime environment win over buildpack defaults", func() {
+			merged, err := npmstart.MergeNodeOptions(defaults, runtime)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged).To(Equal("--max-old-space-size=4096 --enable-source-maps --warnings"))
+		})
+
+		it("applies every contribution in order of precedence", func() {
+			merged, err := npmstart.MergeNodeOptions(defaults, bpl, runtime)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged).To(Equal("--max-old-space-size=4096 --enable-source-maps --warnings"))
+		})
+
+		it("keeps every distinct value of repeatable flags", func() {
+			merged, err := npmstart.MergeNodeOptions("--require ./tracing.js", "-r ./metrics.js --require=./tracing.js", "--import ./loader.mjs")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged).To(Equal("--require ./tracing.js -r ./metrics.js --import ./loader.mjs"))
+		})
+
+		it("quotes values that contain whitespace", func() {
+			merged, err := npmstart.MergeNodeOptions(`--title "some app"`, `--title="other app"`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged).To(Equal(`--title="other app"`))
+		})
+
+		it("ignores empty contributions", func() {
+			merged, err := npmstart.MergeNodeOptions("", "--enable-source-maps", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged).To(Equal("--enable-source-maps"))
+		})
+
+		context("failure cases", func() {
+			it("returns the parse error of a malformed contribution", func() {
+				_, err := npmstart.MergeNodeOptions("--enable-source-maps", "server.js")
+				Expect(err).To(MatchError(ContainSubstring(`"server.js" is not a flag`)))
+			})
+		})
+	})
+}
@@ -0,0 +1,79 @@
+package npmstart
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NpxInvocation is a start script that consists of nothing but running a
+// package with npx, eg. "npx --yes serve@14 -s build".
+type NpxInvocation struct {
+	// Package is the name of the package that npx runs.
+	Package string
+
+	// Version is the version the package is pinned to, or empty when it is
+	// not pinned.
+	Version string
+
+	// Args are the arguments given to the package.
+	Args []string
+}
+
+// ParseNpxInvocation returns the npx invocation that makes up the given
+// script. The boolean return is false when the script does more than run a
+// package with npx, or passes npx flags other than --yes.
+func ParseNpxInvocation(script string) (NpxInvocation, bool) {
+	if scriptSeparator.MatchString(script) {
+		return NpxInvocation{}, false
+	}
+
+	fields := strings.Fields(script)
+	if len(fields) == 0 || fields[0] != "npx" {
+		return NpxInvocation{}, false
+	}
+
+	fields = fields[1:]
+	for len(fields) > 0 && (fields[0] == "--yes" || fields[0] == "-y") {
+		fields = fields[1:]
+	}
+
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "-") {
+		return NpxInvocation{}, false
+	}
+
+	invocation := NpxInvocation{Package: fields[0], Args: fields[1:]}
+
+	// The leading @ of a scoped package is not a version separator.
+	if i := strings.LastIndex(invocation.Package, "@"); i > 0 {
+		invocation.Package, invocation.Version = invocation.Package[:i], invocation.Package[i+1:]
+	}
+
+	return invocation, true
+}
+
+// Bin returns the name of the executable that the package installs, which
+// for a scoped package is its name without the scope.
+func (i NpxInvocation) Bin() string {
+	return i.Package[strings.LastIndex(i.Package, "/")+1:]
+}
+
+// localBin returns the path of the executable of the package in one of the
+// binPaths directories, relative to projectPath when it is installed there.
+// The boolean return is false when the package is not installed locally.
+func (i NpxInvocation) localBin(projectPath string, binPaths []string) (string, bool) {
+	for _, dir := range binPaths {
+		path := filepath.Join(dir, i.Bin())
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		if rel, err := filepath.Rel(projectPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel, true
+		}
+
+		return path, true
+	}
+
+	return "", false
+}
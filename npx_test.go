@@ -0,0 +1,52 @@
+package npmstart_test
+
+import (
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testNpx(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseNpxInvocation", func() {
+		it("parses scripts that only run a package with npx", func() {
+			for script, invocation := range map[string]npmstart.NpxInvocation{
+				"npx serve -s build":          {Package: "serve", Args: []string{"-s", "build"}},
+				"npx --yes serve":             {Package: "serve", Args: []string{}},
+				"npx -y serve@14 -s build":    {Package: "serve", Version: "14", Args: []string{"-s", "build"}},
+				"npx @angular/cli@17.1 serve": {Package: "@angular/cli", Version: "17.1", Args: []string{"serve"}},
+				"npx @nestjs/cli start":       {Package: "@nestjs/cli", Args: []string{"start"}},
+			} {
+				parsed, ok := npmstart.ParseNpxInvocation(script)
+				Expect(ok).To(BeTrue(), script)
+				Expect(parsed).To(Equal(invocation), script)
+			}
+		})
+
+		it("rejects other scripts", func() {
+			for _, script := range []string{
+				"",
+				"serve -s build",
+				"npx",
+				"npx --yes",
+				"npx --package=serve serve",
+				"npx serve && echo done",
+				"NODE_ENV=production npx serve",
+			} {
+				_, ok := npmstart.ParseNpxInvocation(script)
+				Expect(ok).To(BeFalse(), script)
+			}
+		})
+	})
+
+	context("Bin", func() {
+		it("drops the scope of scoped packages", func() {
+			Expect(npmstart.NpxInvocation{Package: "serve"}.Bin()).To(Equal("serve"))
+			Expect(npmstart.NpxInvocation{Package: "@nestjs/cli"}.Bin()).To(Equal("cli"))
+		})
+	})
+}
@@ -0,0 +1,636 @@
+package npmstart
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The opt-in build options whose combinations are checked by
+// OptionMatrix.Validate.
+const (
+	OptionLiveReload       = "BP_LIVE_RELOAD_ENABLED"
+	OptionMaintenance      = "BP_NPM_START_MAINTENANCE"
+	OptionRunPrepare       = "BP_NPM_START_RUN_PREPARE"
+	OptionForwardPort      = "BP_NPM_START_FORWARD_PORT"
+	OptionNoDefault        = "BP_NPM_START_NO_DEFAULT_PROCESS"
+	OptionWorker           = "BP_NPM_START_WORKLOAD_TYPE=worker"
+	OptionSilent           = "BP_NPM_START_SILENT"
+	OptionMinimal          = "BP_NPM_START_MINIMAL_LAUNCH"
+	OptionDebug            = "BP_NPM_START_DEBUG_PROCESSES"
+	OptionEngines          = "BP_NPM_START_ENGINES_STRICT"
+	OptionSlim             = "BP_NPM_START_SLIM"
+	OptionLabels           = "BP_NPM_START_LABELS"
+	OptionHealthcheck      = "BP_NPM_START_HEALTHCHECK_SCRIPT"
+	OptionCommandHook      = "BP_NPM_START_COMMAND_HOOK"
+	OptionExplain          = "BP_NPM_START_EXPLAIN"
+	OptionArgsPolicy       = "BP_NPM_START_ARGS_POLICY"
+	OptionJobs             = "BP_NPM_START_JOBS"
+	OptionDescriptor       = "BP_NPM_START_DESCRIPTOR"
+	OptionRevision         = "BP_NPM_START_REVISION"
+	OptionDescriptorApp    = "BP_NPM_START_DESCRIPTOR_APP"
+	OptionMaintenancePage  = "BP_NPM_START_MAINTENANCE_PAGE"
+	OptionShell            = "BP_NPM_START_SHELL=bash"
+	OptionTraceWrappers    = "BP_NPM_START_TRACE_WRAPPERS"
+	OptionExcerptLength    = "BP_NPM_START_EXCERPT_LENGTH"
+	OptionStrict           = "BP_NPM_START_STRICT"
+	OptionRejectDevServers = "BP_NPM_START_REJECT_DEV_SERVERS"
+	OptionForce            = "BP_NPM_START_FORCE"
+	OptionSuppressWarnings = "BP_NPM_START_SUPPRESS_WARNINGS"
+	OptionDisabledChecks   = "BP_NPM_START_DISABLED_CHECKS"
+	OptionFilter           = "BP_NPM_START_FILTER"
+	OptionProcessEnv       = "BP_NPM_START_PROCESS_ENV"
+	OptionDeprecationsFile = "BP_NPM_START_DEPRECATIONS_FILE"
+)
+
+// Options lists every opt-in build option. OptionCompatibility must hold a
+// decision for each pair of them.
+var Options = []string{
+	OptionLiveReload,
+	OptionMaintenance,
+	OptionRunPrepare,
+	OptionForwardPort,
+	OptionNoDefault,
+	OptionWorker,
+	OptionSilent,
+	OptionMinimal,
+	OptionDebug,
+	OptionEngines,
+	OptionSlim,
+	OptionLabels,
+	OptionHealthcheck,
+	OptionCommandHook,
+	OptionExplain,
+	OptionArgsPolicy,
+	OptionJobs,
+	OptionDescriptor,
+	OptionRevision,
+	OptionDescriptorApp,
+	OptionMaintenancePage,
+	OptionShell,
+	OptionTraceWrappers,
+	OptionExcerptLength,
+	OptionStrict,
+	OptionRejectDevServers,
+	OptionForce,
+	OptionSuppressWarnings,
+	OptionDisabledChecks,
+	OptionFilter,
+	OptionProcessEnv,
+	OptionDeprecationsFile,
+}
+
+// OptionPair records whether two build options can be enabled together. When
+// they cannot, Reason explains the conflict and Resolution suggests a way out.
+type OptionPair struct {
+	Options    [2]string
+	Compatible bool
+	Reason     string
+	Resolution string
+}
+
+// OptionMatrix is a list of decisions about pairs of build options.
+type OptionMatrix []OptionPair
+
+// OptionCompatibility is the compatibility matrix of the build options in
+// Options.
+var OptionCompatibility = OptionMatrix{
+	{Options: [2]string{OptionLiveReload, OptionMaintenance}, Compatible: true, Reason: "the maintenance process is not wrapped by the reloader"},
+	{Options: [2]string{OptionLiveReload, OptionRunPrepare}, Compatible: true, Reason: "prepare runs again on every reload, as it would on a restart"},
+	{Options: [2]string{OptionLiveReload, OptionForwardPort}, Compatible: true, Reason: "the port flag is part of the reloaded command"},
+	{Options: [2]string{OptionMaintenance, OptionRunPrepare}, Compatible: true, Reason: "the maintenance process does not run package.json scripts"},
+	{Options: [2]string{OptionMaintenance, OptionForwardPort}, Compatible: true, Reason: "the maintenance server reads $PORT itself"},
+	{Options: [2]string{OptionRunPrepare, OptionForwardPort}, Compatible: true, Reason: "the port flag is only given to the start script"},
+	{Options: [2]string{OptionNoDefault, OptionLiveReload}, Compatible: true, Reason: "the reloaded command becomes the app process"},
+	{Options: [2]string{OptionNoDefault, OptionMaintenance}, Compatible: true, Reason: "the maintenance process is never the default"},
+	{Options: [2]string{OptionNoDefault, OptionRunPrepare}, Compatible: true, Reason: "the app process runs the same start chain"},
+	{Options: [2]string{OptionNoDefault, OptionForwardPort}, Compatible: true, Reason: "the app process runs the same start chain"},
+	{Options: [2]string{OptionWorker, OptionLiveReload}, Compatible: true, Reason: "the reloaded command becomes the worker process"},
+	{Options: [2]string{OptionWorker, OptionMaintenance}, Compatible: false, Reason: "the maintenance server answers on $PORT, which nothing probes on a worker", Resolution: "unset BP_NPM_START_MAINTENANCE"},
+	{Options: [2]string{OptionWorker, OptionRunPrepare}, Compatible: true, Reason: "the worker process runs the same start chain"},
+	{Options: [2]string{OptionWorker, OptionForwardPort}, Compatible: false, Reason: "a worker does not listen on $PORT", Resolution: "unset BP_NPM_START_FORWARD_PORT"},
+	{Options: [2]string{OptionWorker, OptionNoDefault}, Compatible: true, Reason: "the worker process is made non-default like any other"},
+	{Options: [2]string{OptionSilent, OptionLiveReload}, Compatible: true, Reason: "the reloaded start chain does not run through npm"},
+	{Options: [2]string{OptionSilent, OptionMaintenance}, Compatible: true, Reason: "the maintenance process does not run through npm"},
+	{Options: [2]string{OptionSilent, OptionRunPrepare}, Compatible: true, Reason: "prepare runs in the start chain, not through npm"},
+	{Options: [2]string{OptionSilent, OptionForwardPort}, Compatible: true, Reason: "the port flag is only given to the start script"},
+	{Options: [2]string{OptionSilent, OptionNoDefault}, Compatible: true, Reason: "job processes are never the default"},
+	{Options: [2]string{OptionSilent, OptionWorker}, Compatible: true, Reason: "job processes are not renamed for workers"},
+	{Options: [2]string{OptionMinimal, OptionLiveReload}, Compatible: true, Reason: "the reloader is part of the process, not a launch helper"},
+	{Options: [2]string{OptionMinimal, OptionMaintenance}, Compatible: true, Reason: "the maintenance server is a process, not a launch helper"},
+	{Options: [2]string{OptionMinimal, OptionRunPrepare}, Compatible: true, Reason: "prepare runs in the start chain"},
+	{Options: [2]string{OptionMinimal, OptionForwardPort}, Compatible: true, Reason: "the port flag is part of the start command"},
+	{Options: [2]string{OptionMinimal, OptionNoDefault}, Compatible: true, Reason: "the app process needs no launch helper"},
+	{Options: [2]string{OptionMinimal, OptionWorker}, Compatible: true, Reason: "the worker process needs no launch helper"},
+	{Options: [2]string{OptionMinimal, OptionSilent}, Compatible: true, Reason: "--silent is part of the job commands"},
+	{Options: [2]string{OptionDebug, OptionLiveReload}, Compatible: true, Reason: "the shell is not wrapped by the reloader"},
+	{Options: [2]string{OptionDebug, OptionMaintenance}, Compatible: true, Reason: "the shell and the maintenance server are separate processes"},
+	{Options: [2]string{OptionDebug, OptionRunPrepare}, Compatible: true, Reason: "the shell does not run the start chain"},
+	{Options: [2]string{OptionDebug, OptionForwardPort}, Compatible: true, Reason: "the shell does not listen on $PORT"},
+	{Options: [2]string{OptionDebug, OptionNoDefault}, Compatible: true, Reason: "the shell is never the default"},
+	{Options: [2]string{OptionDebug, OptionWorker}, Compatible: true, Reason: "the shell gets the environment of the worker process"},
+	{Options: [2]string{OptionDebug, OptionSilent}, Compatible: true, Reason: "the shell does not run through npm"},
+	{Options: [2]string{OptionDebug, OptionMinimal}, Compatible: true, Reason: "the shell needs no launch helper"},
+	{Options: [2]string{OptionEngines, OptionLiveReload}, Compatible: true, Reason: "the node version is checked once, before the reloader starts"},
+	{Options: [2]string{OptionEngines, OptionMaintenance}, Compatible: true, Reason: "the maintenance server is checked like any other process"},
+	{Options: [2]string{OptionEngines, OptionRunPrepare}, Compatible: true, Reason: "prepare runs after the node version is checked"},
+	{Options: [2]string{OptionEngines, OptionForwardPort}, Compatible: true, Reason: "the port flag is part of the start command"},
+	{Options: [2]string{OptionEngines, OptionNoDefault}, Compatible: true, Reason: "the check runs ahead of every process, default or not"},
+	{Options: [2]string{OptionEngines, OptionWorker}, Compatible: true, Reason: "the check runs ahead of the worker process too"},
+	{Options: [2]string{OptionEngines, OptionSilent}, Compatible: true, Reason: "the check does not run through npm"},
+	{Options: [2]string{OptionEngines, OptionMinimal}, Compatible: false, Reason: "a minimal launch leaves out the helper that checks the node version", Resolution: "unset BP_NPM_START_MINIMAL_LAUNCH"},
+	{Options: [2]string{OptionEngines, OptionDebug}, Compatible: true, Reason: "the shell refuses to open on the wrong node as well"},
+	{Options: [2]string{OptionSlim, OptionLiveReload}, Compatible: true, Reason: "live reload needs no metadata"},
+	{Options: [2]string{OptionSlim, OptionMaintenance}, Compatible: true, Reason: "the maintenance process needs no metadata"},
+	{Options: [2]string{OptionSlim, OptionRunPrepare}, Compatible: true, Reason: "prepare is part of the start command"},
+	{Options: [2]string{OptionSlim, OptionForwardPort}, Compatible: true, Reason: "the port flag is part of the start command"},
+	{Options: [2]string{OptionSlim, OptionNoDefault}, Compatible: true, Reason: "the processes are computed the same way"},
+	{Options: [2]string{OptionSlim, OptionWorker}, Compatible: false, Reason: "a slim build leaves out the label that tells the platform to skip HTTP health checks of a worker", Resolution: "unset BP_NPM_START_SLIM"},
+	{Options: [2]string{OptionSlim, OptionSilent}, Compatible: true, Reason: "the job scripts are computed the same way"},
+	{Options: [2]string{OptionSlim, OptionMinimal}, Compatible: true, Reason: "both leave out what the processes do not need"},
+	{Options: [2]string{OptionSlim, OptionDebug}, Compatible: true, Reason: "the debug processes need no metadata"},
+	{Options: [2]string{OptionSlim, OptionEngines}, Compatible: true, Reason: "the check reads its range from the launch environment, not the layer metadata"},
+	{Options: [2]string{OptionLabels, OptionLiveReload}, Compatible: true, Reason: "the labels are image metadata, apart from the reloaded process"},
+	{Options: [2]string{OptionLabels, OptionMaintenance}, Compatible: true, Reason: "the labels apply to the image, whichever process runs"},
+	{Options: [2]string{OptionLabels, OptionRunPrepare}, Compatible: true, Reason: "the labels do not change the start command"},
+	{Options: [2]string{OptionLabels, OptionForwardPort}, Compatible: true, Reason: "autoscaling labels do not change the port the app listens on"},
+	{Options: [2]string{OptionLabels, OptionNoDefault}, Compatible: true, Reason: "the labels do not depend on a default process"},
+	{Options: [2]string{OptionLabels, OptionWorker}, Compatible: true, Reason: "the workload label is set by the buildpack, and the labels of the platform cannot set it"},
+	{Options: [2]string{OptionLabels, OptionSilent}, Compatible: true, Reason: "the labels do not change the job commands"},
+	{Options: [2]string{OptionLabels, OptionMinimal}, Compatible: true, Reason: "the labels need no launch helper"},
+	{Options: [2]string{OptionLabels, OptionDebug}, Compatible: true, Reason: "the debug processes do not read the labels"},
+	{Options: [2]string{OptionLabels, OptionEngines}, Compatible: true, Reason: "the node version check does not read the labels"},
+	{Options: [2]string{OptionLabels, OptionSlim}, Compatible: false, Reason: "a slim build leaves out every image label, including those of BP_NPM_START_LABELS", Resolution: "unset BP_NPM_START_SLIM or BP_NPM_START_LABELS"},
+	{Options: [2]string{OptionHealthcheck, OptionLiveReload}, Compatible: true, Reason: "the health process is not wrapped by the reloader"},
+	{Options: [2]string{OptionHealthcheck, OptionMaintenance}, Compatible: true, Reason: "the health and maintenance processes are separate"},
+	{Options: [2]string{OptionHealthcheck, OptionRunPrepare}, Compatible: true, Reason: "prepare only runs in the start chain, not on every probe"},
+	{Options: [2]string{OptionHealthcheck, OptionForwardPort}, Compatible: true, Reason: "the port flag is only given to the start script"},
+	{Options: [2]string{OptionHealthcheck, OptionNoDefault}, Compatible: true, Reason: "the health process is never the default"},
+	{Options: [2]string{OptionHealthcheck, OptionWorker}, Compatible: true, Reason: "exec probes of a worker can run the health process"},
+	{Options: [2]string{OptionHealthcheck, OptionSilent}, Compatible: true, Reason: "the npm fallback of the health process is run with --silent as well"},
+	{Options: [2]string{OptionHealthcheck, OptionMinimal}, Compatible: true, Reason: "the health process needs no launch helper"},
+	{Options: [2]string{OptionHealthcheck, OptionDebug}, Compatible: true, Reason: "the shell and the health process are separate processes"},
+	{Options: [2]string{OptionHealthcheck, OptionEngines}, Compatible: true, Reason: "the node version is checked ahead of the health process too"},
+	{Options: [2]string{OptionHealthcheck, OptionSlim}, Compatible: false, Reason: "a slim build leaves out the label that tells controllers which process runs the health check", Resolution: "unset BP_NPM_START_SLIM"},
+	{Options: [2]string{OptionHealthcheck, OptionLabels}, Compatible: true, Reason: "the healthcheck label is set by the buildpack, and the labels of the platform cannot set it"},
+	{Options: [2]string{OptionCommandHook, OptionLiveReload}, Compatible: true, Reason: "live reload restarts the command that the hook returns"},
+	{Options: [2]string{OptionCommandHook, OptionMaintenance}, Compatible: true, Reason: "the maintenance process is not passed to the hook"},
+	{Options: [2]string{OptionCommandHook, OptionRunPrepare}, Compatible: true, Reason: "the hook sees the start command with prepare at its head"},
+	{Options: [2]string{OptionCommandHook, OptionForwardPort}, Compatible: true, Reason: "the hook sees the start command with the port flag"},
+	{Options: [2]string{OptionCommandHook, OptionNoDefault}, Compatible: true, Reason: "the hook rewrites the command, not the process type"},
+	{Options: [2]string{OptionCommandHook, OptionWorker}, Compatible: true, Reason: "the hook rewrites the command, not the process type"},
+	{Options: [2]string{OptionCommandHook, OptionSilent}, Compatible: true, Reason: "the hook does not rewrite the job commands"},
+	{Options: [2]string{OptionCommandHook, OptionMinimal}, Compatible: true, Reason: "the hook runs during the build, not as a launch helper"},
+	{Options: [2]string{OptionCommandHook, OptionDebug}, Compatible: true, Reason: "the shell is not passed to the hook"},
+	{Options: [2]string{OptionCommandHook, OptionEngines}, Compatible: true, Reason: "the node version is checked ahead of the command that the hook returns"},
+	{Options: [2]string{OptionCommandHook, OptionSlim}, Compatible: true, Reason: "the hook changes the command, not the metadata"},
+	{Options: [2]string{OptionCommandHook, OptionLabels}, Compatible: true, Reason: "the labels do not change the start command"},
+	{Options: [2]string{OptionCommandHook, OptionHealthcheck}, Compatible: true, Reason: "the health process is not passed to the hook"},
+	{Options: [2]string{OptionExplain, OptionLiveReload}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionMaintenance}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionRunPrepare}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionForwardPort}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionNoDefault}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionWorker}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionSilent}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionMinimal}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionDebug}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionEngines}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionSlim}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionLabels}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionHealthcheck}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionExplain, OptionCommandHook}, Compatible: true, Reason: "the explanations are only logged and change nothing that is built"},
+	{Options: [2]string{OptionArgsPolicy, OptionLiveReload}, Compatible: true, Reason: "the policy only decides whether launch arguments reach the start script that the reloader runs"},
+	{Options: [2]string{OptionArgsPolicy, OptionMaintenance}, Compatible: true, Reason: "the policy does not apply to the maintenance process"},
+	{Options: [2]string{OptionArgsPolicy, OptionRunPrepare}, Compatible: true, Reason: "prepare runs ahead of the start script, which alone receives launch arguments"},
+	{Options: [2]string{OptionArgsPolicy, OptionForwardPort}, Compatible: true, Reason: "the port flag is part of the start script whichever the policy"},
+	{Options: [2]string{OptionArgsPolicy, OptionNoDefault}, Compatible: true, Reason: "the app process gets launch arguments as the default process would"},
+	{Options: [2]string{OptionArgsPolicy, OptionWorker}, Compatible: true, Reason: "the worker process gets launch arguments as the web process would"},
+	{Options: [2]string{OptionArgsPolicy, OptionSilent}, Compatible: true, Reason: "the policy does not apply to the job processes"},
+	{Options: [2]string{OptionArgsPolicy, OptionMinimal}, Compatible: true, Reason: "launch arguments are forwarded by the start script, not by a launch helper"},
+	{Options: [2]string{OptionArgsPolicy, OptionDebug}, Compatible: true, Reason: "the policy does not apply to the shell"},
+	{Options: [2]string{OptionArgsPolicy, OptionEngines}, Compatible: true, Reason: "the node version check ignores the launch arguments"},
+	{Options: [2]string{OptionArgsPolicy, OptionSlim}, Compatible: true, Reason: "the policy changes the start command, not the metadata"},
+	{Options: [2]string{OptionArgsPolicy, OptionLabels}, Compatible: true, Reason: "the labels do not change the start command"},
+	{Options: [2]string{OptionArgsPolicy, OptionHealthcheck}, Compatible: true, Reason: "the policy does not apply to the health process"},
+	{Options: [2]string{OptionArgsPolicy, OptionCommandHook}, Compatible: true, Reason: "the hook sees the start command as the policy shapes it"},
+	{Options: [2]string{OptionArgsPolicy, OptionExplain}, Compatible: true, Reason: "the policy only decides whether launch arguments reach the start script"},
+	{Options: [2]string{OptionJobs, OptionLiveReload}, Compatible: true, Reason: "job processes run without live reload"},
+	{Options: [2]string{OptionJobs, OptionMaintenance}, Compatible: true, Reason: "a job cannot take the name of the maintenance process"},
+	{Options: [2]string{OptionJobs, OptionRunPrepare}, Compatible: true, Reason: "prepare only runs in the start chain, not ahead of a job"},
+	{Options: [2]string{OptionJobs, OptionForwardPort}, Compatible: true, Reason: "the port flag is only given to the start script"},
+	{Options: [2]string{OptionJobs, OptionNoDefault}, Compatible: true, Reason: "job processes are never the default"},
+	{Options: [2]string{OptionJobs, OptionWorker}, Compatible: true, Reason: "job processes are not renamed for workers"},
+	{Options: [2]string{OptionJobs, OptionSilent}, Compatible: true, Reason: "the jobs run with --silent"},
+	{Options: [2]string{OptionJobs, OptionMinimal}, Compatible: true, Reason: "job processes need no launch helper"},
+	{Options: [2]string{OptionJobs, OptionDebug}, Compatible: true, Reason: "a job cannot take the name of the shell process"},
+	{Options: [2]string{OptionJobs, OptionEngines}, Compatible: true, Reason: "the node version is checked ahead of the job processes too"},
+	{Options: [2]string{OptionJobs, OptionSlim}, Compatible: true, Reason: "job processes need no metadata"},
+	{Options: [2]string{OptionJobs, OptionLabels}, Compatible: true, Reason: "the labels do not change the job commands"},
+	{Options: [2]string{OptionJobs, OptionHealthcheck}, Compatible: true, Reason: "a job cannot take the name of the health process"},
+	{Options: [2]string{OptionJobs, OptionCommandHook}, Compatible: true, Reason: "the hook does not rewrite the job commands"},
+	{Options: [2]string{OptionJobs, OptionExplain}, Compatible: true, Reason: "the jobs are processes of their own"},
+	{Options: [2]string{OptionJobs, OptionArgsPolicy}, Compatible: true, Reason: "the policy does not apply to the job processes"},
+	{Options: [2]string{OptionDescriptor, OptionLiveReload}, Compatible: true, Reason: "live reload restarts the command of the descriptor like a start script"},
+	{Options: [2]string{OptionDescriptor, OptionMaintenance}, Compatible: true, Reason: "the maintenance process does not run the start command"},
+	{Options: [2]string{OptionDescriptor, OptionRunPrepare}, Compatible: true, Reason: "prepare runs ahead of the command of the descriptor"},
+	{Options: [2]string{OptionDescriptor, OptionForwardPort}, Compatible: true, Reason: "the command of the descriptor takes the port flag like a start script"},
+	{Options: [2]string{OptionDescriptor, OptionNoDefault}, Compatible: true, Reason: "the command of the descriptor becomes the app process"},
+	{Options: [2]string{OptionDescriptor, OptionWorker}, Compatible: true, Reason: "the command of the descriptor becomes the worker process"},
+	{Options: [2]string{OptionDescriptor, OptionSilent}, Compatible: true, Reason: "the descriptor does not replace the job scripts"},
+	{Options: [2]string{OptionDescriptor, OptionMinimal}, Compatible: true, Reason: "the command of the descriptor needs no launch helper"},
+	{Options: [2]string{OptionDescriptor, OptionDebug}, Compatible: true, Reason: "the shell does not run the start command"},
+	{Options: [2]string{OptionDescriptor, OptionEngines}, Compatible: true, Reason: "the node version is checked ahead of the command of the descriptor"},
+	{Options: [2]string{OptionDescriptor, OptionSlim}, Compatible: true, Reason: "the descriptor is read during the build and adds no metadata"},
+	{Options: [2]string{OptionDescriptor, OptionLabels}, Compatible: true, Reason: "the labels do not change the start command"},
+	{Options: [2]string{OptionDescriptor, OptionHealthcheck}, Compatible: true, Reason: "the descriptor does not replace the healthcheck script"},
+	{Options: [2]string{OptionDescriptor, OptionCommandHook}, Compatible: true, Reason: "the hook rewrites the command of the descriptor like a start script"},
+	{Options: [2]string{OptionDescriptor, OptionExplain}, Compatible: true, Reason: "the descriptor only replaces the start script"},
+	{Options: [2]string{OptionDescriptor, OptionArgsPolicy}, Compatible: true, Reason: "launch arguments reach the command of the descriptor as they reach a start script"},
+	{Options: [2]string{OptionDescriptor, OptionJobs}, Compatible: true, Reason: "the descriptor does not replace the job scripts"},
+	{Options: [2]string{OptionRevision, OptionLiveReload}, Compatible: true, Reason: "APP_REVISION is a launch default of every process, reloaded or not"},
+	{Options: [2]string{OptionRevision, OptionMaintenance}, Compatible: true, Reason: "APP_REVISION is a launch default of every process"},
+	{Options: [2]string{OptionRevision, OptionRunPrepare}, Compatible: true, Reason: "the revision does not change the processes"},
+	{Options: [2]string{OptionRevision, OptionForwardPort}, Compatible: true, Reason: "the revision does not change the processes"},
+	{Options: [2]string{OptionRevision, OptionNoDefault}, Compatible: true, Reason: "APP_REVISION is a launch default of every process"},
+	{Options: [2]string{OptionRevision, OptionWorker}, Compatible: true, Reason: "the revision label is set next to the workload label"},
+	{Options: [2]string{OptionRevision, OptionSilent}, Compatible: true, Reason: "the revision does not change the processes"},
+	{Options: [2]string{OptionRevision, OptionMinimal}, Compatible: true, Reason: "the revision needs no launch helper"},
+	{Options: [2]string{OptionRevision, OptionDebug}, Compatible: true, Reason: "the shell sees APP_REVISION like the start process"},
+	{Options: [2]string{OptionRevision, OptionEngines}, Compatible: true, Reason: "the node version check does not read the revision"},
+	{Options: [2]string{OptionRevision, OptionSlim}, Compatible: false, Reason: "a slim build leaves out the io.paketo.npm-start.revision label, so only APP_REVISION would be set", Resolution: "unset BP_NPM_START_SLIM or BP_NPM_START_REVISION"},
+	{Options: [2]string{OptionRevision, OptionLabels}, Compatible: true, Reason: "the revision label is set by the buildpack, and the labels of the platform cannot set it"},
+	{Options: [2]string{OptionRevision, OptionHealthcheck}, Compatible: true, Reason: "the revision and healthcheck labels are separate"},
+	{Options: [2]string{OptionRevision, OptionCommandHook}, Compatible: true, Reason: "the revision does not change the processes"},
+	{Options: [2]string{OptionRevision, OptionExplain}, Compatible: true, Reason: "the revision does not change the processes"},
+	{Options: [2]string{OptionRevision, OptionArgsPolicy}, Compatible: true, Reason: "the revision does not change the processes"},
+	{Options: [2]string{OptionRevision, OptionJobs}, Compatible: true, Reason: "the jobs see APP_REVISION like the start process"},
+	{Options: [2]string{OptionRevision, OptionDescriptor}, Compatible: true, Reason: "the revision does not depend on where the start command comes from"},
+	{Options: [2]string{OptionDescriptorApp, OptionLiveReload}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionMaintenance}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionRunPrepare}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionForwardPort}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionNoDefault}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionWorker}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionSilent}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionMinimal}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionDebug}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionEngines}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionSlim}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionLabels}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionHealthcheck}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionCommandHook}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionExplain}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionArgsPolicy}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionJobs}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionDescriptorApp, OptionDescriptor}, Compatible: true, Reason: "the name selects one of the applications of the descriptor"},
+	{Options: [2]string{OptionDescriptorApp, OptionRevision}, Compatible: true, Reason: "the name only selects the application of the descriptor that gives the start command"},
+	{Options: [2]string{OptionMaintenancePage, OptionLiveReload}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionMaintenance}, Compatible: true, Reason: "the page is what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionRunPrepare}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionForwardPort}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionNoDefault}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionWorker}, Compatible: true, Reason: "the page is only served with BP_NPM_START_MAINTENANCE, which is what conflicts with a worker"},
+	{Options: [2]string{OptionMaintenancePage, OptionSilent}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionMinimal}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionDebug}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionEngines}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionSlim}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionLabels}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionHealthcheck}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionCommandHook}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionExplain}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionArgsPolicy}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionJobs}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionDescriptor}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionRevision}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionMaintenancePage, OptionDescriptorApp}, Compatible: true, Reason: "the page only changes what the maintenance process serves"},
+	{Options: [2]string{OptionShell, OptionLiveReload}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionMaintenance}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionRunPrepare}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionForwardPort}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionNoDefault}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionWorker}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionSilent}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionMinimal}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionDebug}, Compatible: true, Reason: "bash is the shell that the shell process opens"},
+	{Options: [2]string{OptionShell, OptionEngines}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionSlim}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionLabels}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionHealthcheck}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionCommandHook}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionExplain}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionArgsPolicy}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionJobs}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionDescriptor}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionRevision}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionDescriptorApp}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionShell, OptionMaintenancePage}, Compatible: true, Reason: "the shell is only opened by the shell process"},
+	{Options: [2]string{OptionTraceWrappers, OptionLiveReload}, Compatible: true, Reason: "the trace logs the command that the reloader wraps"},
+	{Options: [2]string{OptionTraceWrappers, OptionMaintenance}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionRunPrepare}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionForwardPort}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionNoDefault}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionWorker}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionSilent}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionMinimal}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionDebug}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionEngines}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionSlim}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionLabels}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionHealthcheck}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionCommandHook}, Compatible: true, Reason: "the trace logs the command that the hook returns"},
+	{Options: [2]string{OptionTraceWrappers, OptionExplain}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionArgsPolicy}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionJobs}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionDescriptor}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionRevision}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionDescriptorApp}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionMaintenancePage}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionTraceWrappers, OptionShell}, Compatible: true, Reason: "the trace only logs the command after each wrapper"},
+	{Options: [2]string{OptionExcerptLength, OptionLiveReload}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionMaintenance}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionRunPrepare}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionForwardPort}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionNoDefault}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionWorker}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionSilent}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionMinimal}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionDebug}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionEngines}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionSlim}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionLabels}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionHealthcheck}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionCommandHook}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionExplain}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionArgsPolicy}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionJobs}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionDescriptor}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionRevision}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionDescriptorApp}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionMaintenancePage}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionShell}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionExcerptLength, OptionTraceWrappers}, Compatible: true, Reason: "the length only changes how scripts are quoted in the log"},
+	{Options: [2]string{OptionStrict, OptionLiveReload}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionMaintenance}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionRunPrepare}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionForwardPort}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionNoDefault}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionWorker}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionSilent}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionMinimal}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionDebug}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionEngines}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionSlim}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionLabels}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionHealthcheck}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionCommandHook}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionExplain}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionArgsPolicy}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionJobs}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionDescriptor}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionRevision}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionDescriptorApp}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionMaintenancePage}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionShell}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionTraceWrappers}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionStrict, OptionExcerptLength}, Compatible: true, Reason: "strict mode only turns warnings about the app into failures"},
+	{Options: [2]string{OptionRejectDevServers, OptionLiveReload}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionMaintenance}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionRunPrepare}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionForwardPort}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionNoDefault}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionWorker}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionSilent}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionMinimal}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionDebug}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionEngines}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionSlim}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionLabels}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionHealthcheck}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionCommandHook}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionExplain}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionArgsPolicy}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionJobs}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionDescriptor}, Compatible: true, Reason: "the dev server check looks at the start script of package.json, whatever the descriptor runs"},
+	{Options: [2]string{OptionRejectDevServers, OptionRevision}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionDescriptorApp}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionMaintenancePage}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionShell}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionTraceWrappers}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionExcerptLength}, Compatible: true, Reason: "the dev server check runs during detection and only looks at the start script"},
+	{Options: [2]string{OptionRejectDevServers, OptionStrict}, Compatible: true, Reason: "rejecting dev servers fails detection, and strict mode only fails the build"},
+	{Options: [2]string{OptionForce, OptionLiveReload}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionMaintenance}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionRunPrepare}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionForwardPort}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionNoDefault}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionWorker}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionSilent}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionMinimal}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionDebug}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionEngines}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionSlim}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionLabels}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionHealthcheck}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionCommandHook}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionExplain}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionArgsPolicy}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionJobs}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionDescriptor}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionRevision}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionDescriptorApp}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionMaintenancePage}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionShell}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionTraceWrappers}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionExcerptLength}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionStrict}, Compatible: true, Reason: "forcing only decides whether detection claims an app for another runtime"},
+	{Options: [2]string{OptionForce, OptionRejectDevServers}, Compatible: true, Reason: "a dev server is rejected whether or not the app is forced past another runtime"},
+	{Options: [2]string{OptionSuppressWarnings, OptionLiveReload}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionMaintenance}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionRunPrepare}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionForwardPort}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionNoDefault}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionWorker}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionSilent}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionMinimal}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionDebug}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionEngines}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionSlim}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionLabels}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionHealthcheck}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionCommandHook}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionExplain}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionArgsPolicy}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionJobs}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionDescriptor}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionRevision}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionDescriptorApp}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionMaintenancePage}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionShell}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionTraceWrappers}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionExcerptLength}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionSuppressWarnings, OptionStrict}, Compatible: true, Reason: "the failures of strict mode are not warnings, so suppressing the warnings leaves them in place"},
+	{Options: [2]string{OptionSuppressWarnings, OptionRejectDevServers}, Compatible: true, Reason: "a rejected dev server fails detection rather than warning"},
+	{Options: [2]string{OptionSuppressWarnings, OptionForce}, Compatible: true, Reason: "suppressing only silences the advisory warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionLiveReload}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionMaintenance}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionRunPrepare}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionForwardPort}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionNoDefault}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionWorker}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionSilent}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionMinimal}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionDebug}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionEngines}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionSlim}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionLabels}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionHealthcheck}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionCommandHook}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionExplain}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionArgsPolicy}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionJobs}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionDescriptor}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionRevision}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionDescriptorApp}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionMaintenancePage}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionShell}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionTraceWrappers}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionExcerptLength}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionStrict}, Compatible: true, Reason: "the failures of strict mode are not warnings, so disabling a check leaves them in place"},
+	{Options: [2]string{OptionDisabledChecks, OptionRejectDevServers}, Compatible: true, Reason: "a rejected dev server fails detection rather than warning"},
+	{Options: [2]string{OptionDisabledChecks, OptionForce}, Compatible: true, Reason: "disabling a check only silences its warnings"},
+	{Options: [2]string{OptionDisabledChecks, OptionSuppressWarnings}, Compatible: true, Reason: "disabling checks is redundant, but harmless, when every warning is suppressed"},
+	{Options: [2]string{OptionFilter, OptionLiveReload}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionMaintenance}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionRunPrepare}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionForwardPort}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionNoDefault}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionWorker}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionSilent}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionMinimal}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionDebug}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionEngines}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionSlim}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionLabels}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionHealthcheck}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionCommandHook}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionExplain}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionArgsPolicy}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionJobs}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionDescriptor}, Compatible: false, Reason: "the command of the descriptor replaces the start script that the filter is added to", Resolution: "unset BP_NPM_START_FILTER and select the packages in the command of the descriptor"},
+	{Options: [2]string{OptionFilter, OptionRevision}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionDescriptorApp}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionMaintenancePage}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionShell}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionTraceWrappers}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionExcerptLength}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionStrict}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionRejectDevServers}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionForce}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionSuppressWarnings}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionFilter, OptionDisabledChecks}, Compatible: true, Reason: "the filter only applies to a start script that runs turbo or nx"},
+	{Options: [2]string{OptionProcessEnv, OptionLiveReload}, Compatible: true, Reason: "the no-reload process can be given variables like any other"},
+	{Options: [2]string{OptionProcessEnv, OptionMaintenance}, Compatible: true, Reason: "the maintenance process can be given variables like any other"},
+	{Options: [2]string{OptionProcessEnv, OptionRunPrepare}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionForwardPort}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionNoDefault}, Compatible: true, Reason: "the app process can be given variables like any other"},
+	{Options: [2]string{OptionProcessEnv, OptionWorker}, Compatible: true, Reason: "the worker process can be given variables like any other"},
+	{Options: [2]string{OptionProcessEnv, OptionSilent}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionMinimal}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionDebug}, Compatible: true, Reason: "the variables set for shell take precedence over those of the start process"},
+	{Options: [2]string{OptionProcessEnv, OptionEngines}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionSlim}, Compatible: true, Reason: "the variables are part of the launch environment, which a slim build keeps"},
+	{Options: [2]string{OptionProcessEnv, OptionLabels}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionHealthcheck}, Compatible: true, Reason: "the health process can be given variables like any other"},
+	{Options: [2]string{OptionProcessEnv, OptionCommandHook}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionExplain}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionArgsPolicy}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionJobs}, Compatible: true, Reason: "the job processes can be given variables like any other"},
+	{Options: [2]string{OptionProcessEnv, OptionDescriptor}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionRevision}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionDescriptorApp}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionMaintenancePage}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionShell}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionTraceWrappers}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionExcerptLength}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionStrict}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionRejectDevServers}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionForce}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionSuppressWarnings}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionDisabledChecks}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionProcessEnv, OptionFilter}, Compatible: true, Reason: "the per-process variables do not change what the processes run"},
+	{Options: [2]string{OptionDeprecationsFile, OptionLiveReload}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionMaintenance}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionRunPrepare}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionForwardPort}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionNoDefault}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionWorker}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionSilent}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionMinimal}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionDebug}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionEngines}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionSlim}, Compatible: true, Reason: "a slim build leaves out the file, as it leaves out every report file, and says so in the log"},
+	{Options: [2]string{OptionDeprecationsFile, OptionLabels}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionHealthcheck}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionCommandHook}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionExplain}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionArgsPolicy}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionJobs}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionDescriptor}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionRevision}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionDescriptorApp}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionMaintenancePage}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionShell}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionTraceWrappers}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionExcerptLength}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionStrict}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionRejectDevServers}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionForce}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionSuppressWarnings}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionDisabledChecks}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionFilter}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+	{Options: [2]string{OptionDeprecationsFile, OptionProcessEnv}, Compatible: true, Reason: "the file only reports the scheduled changes of default behavior"},
+}
+
+// Lookup returns the decision for the given pair of options, in either
+// order.
+func (m OptionMatrix) Lookup(a, b string) (OptionPair, bool) {
+	for _, pair := range m {
+		if (pair.Options[0] == a && pair.Options[1] == b) || (pair.Options[0] == b && pair.Options[1] == a) {
+			return pair, true
+		}
+	}
+
+	return OptionPair{}, false
+}
+
+// Validate returns an error listing every incompatible pair among the
+// enabled options, or nil when they can all be used together.
+func (m OptionMatrix) Validate(enabled map[string]bool) error {
+	var names []string
+	for name, on := range enabled {
+		if on {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var conflicts []string
+	for i, a := range names {
+		for _, b := range names[i+1:] {
+			pair, ok := m.Lookup(a, b)
+			if !ok || pair.Compatible {
+				continue
+			}
+
+			conflicts = append(conflicts, fmt.Sprintf("%s and %s: %s; %s", pair.Options[0], pair.Options[1], pair.Reason, pair.Resolution))
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return classify(ErrInvalidConfiguration, fmt.Errorf("incompatible build options:\n  %s", strings.Join(conflicts, "\n  ")))
+}
@@ -0,0 +1,141 @@
+package npmstart_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testOptions(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("OptionCompatibility", func() {
+		it("decides every pair of options exactly once", func() {
+			for i, a := range npmstart.Options {
+				for _, b := range npmstart.Options[i+1:] {
+					var decisions int
+					for _, pair := range npmstart.OptionCompatibility {
+						if (pair.Options[0] == a && pair.Options[1] == b) || (pair.Options[0] == b && pair.Options[1] == a) {
+							decisions++
+						}
+					}
+
+					Expect(decisions).To(Equal(1), "expected one decision for %s and %s", a, b)
+				}
+			}
+		})
+
+		it("lists every BP_NPM_START_ variable of the configuration", func() {
+			var names []string
+			for _, option := range npmstart.Options {
+				names = append(names, strings.SplitN(option, "=", 2)[0])
+			}
+
+			fields := reflect.TypeOf(npmstart.Configuration{})
+			for i := 0; i < fields.NumField(); i++ {
+				name := fields.Field(i).Tag.Get("env")
+				if strings.HasPrefix(name, "BP_NPM_START_") {
+					Expect(names).To(ContainElement(name), "expected %s to be one of the Options", name)
+				}
+			}
+		})
+
+		it("only refers to known options", func() {
+			for _, pair := range npmstart.OptionCompatibility {
+				Expect(npmstart.Options).To(ContainElement(pair.Options[0]))
+				Expect(npmstart.Options).To(ContainElement(pair.Options[1]))
+				Expect(pair.Options[0]).NotTo(Equal(pair.Options[1]))
+			}
+		})
+
+		it("explains every decision", func() {
+			for _, pair := range npmstart.OptionCompatibility {
+				Expect(pair.Reason).NotTo(BeEmpty())
+				if !pair.Compatible {
+					Expect(pair.Resolution).NotTo(BeEmpty())
+				}
+			}
+		})
+
+		it("accepts every pair it marks compatible", func() {
+			for i, a := range npmstart.Options {
+				for _, b := range npmstart.Options[i+1:] {
+					pair, ok := npmstart.OptionCompatibility.Lookup(a, b)
+					Expect(ok).To(BeTrue())
+
+					err := npmstart.OptionCompatibility.Validate(map[string]bool{a: true, b: true})
+					if pair.Compatible {
+						Expect(err).NotTo(HaveOccurred())
+					} else {
+						Expect(err).To(MatchError(ContainSubstring(pair.Reason)))
+					}
+				}
+			}
+		})
+	})
+
+	context("Validate", func() {
+		var matrix npmstart.OptionMatrix
+
+		it.Before(func() {
+			matrix = npmstart.OptionMatrix{
+				{Options: [2]string{"SOME_OPTION", "OTHER_OPTION"}, Reason: "some-reason", Resolution: "unset SOME_OPTION"},
+				{Options: [2]string{"SOME_OPTION", "ANOTHER_OPTION"}, Reason: "other-reason", Resolution: "unset ANOTHER_OPTION"},
+				{Options: [2]string{"OTHER_OPTION", "ANOTHER_OPTION"}, Compatible: true, Reason: "some-reason"},
+			}
+		})
+
+		it("accepts compatible options", func() {
+			Expect(matrix.Validate(map[string]bool{
+				"OTHER_OPTION":   true,
+				"ANOTHER_OPTION": true,
+			})).To(Succeed())
+		})
+
+		it("ignores options that are not enabled", func() {
+			Expect(matrix.Validate(map[string]bool{
+				"SOME_OPTION":  true,
+				"OTHER_OPTION": false,
+			})).To(Succeed())
+		})
+
+		it("lists every conflict among the enabled options", func() {
+			err := matrix.Validate(map[string]bool{
+				"SOME_OPTION":    true,
+				"OTHER_OPTION":   true,
+				"ANOTHER_OPTION": true,
+			})
+			Expect(err).To(MatchError("incompatible build options:\n" +
+				"  SOME_OPTION and ANOTHER_OPTION: other-reason; unset ANOTHER_OPTION\n" +
+				"  SOME_OPTION and OTHER_OPTION: some-reason; unset SOME_OPTION"))
+		})
+	})
+
+	context("Explain", func() {
+		it("returns the reason for each compatible pair of enabled options", func() {
+			matrix := npmstart.OptionMatrix{
+				{Options: [2]string{"SOME_OPTION", "OTHER_OPTION"}, Reason: "some-reason", Resolution: "unset SOME_OPTION"},
+				{Options: [2]string{"OTHER_OPTION", "ANOTHER_OPTION"}, Compatible: true, Reason: "other-reason"},
+				{Options: [2]string{"SOME_OPTION", "ANOTHER_OPTION"}, Compatible: true, Reason: "another-reason"},
+			}
+
+			Expect(matrix.Explain(map[string]bool{
+				"SOME_OPTION":    true,
+				"OTHER_OPTION":   true,
+				"ANOTHER_OPTION": false,
+			})).To(BeEmpty())
+
+			Expect(matrix.Explain(map[string]bool{
+				"OTHER_OPTION":   true,
+				"ANOTHER_OPTION": true,
+			})).To(Equal([]npmstart.Rationale{
+				{Subject: "OTHER_OPTION with ANOTHER_OPTION", Reason: "other-reason"},
+			}))
+		})
+	})
+}
@@ -0,0 +1,69 @@
+package npmstart
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PackageConfigPrefix is the prefix of the variables that npm exports the
+// "config" field of the package.json as.
+const PackageConfigPrefix = "npm_package_config_"
+
+// PackageConfigEnv flattens the "config" field of a package.json into the
+// npm_package_config_<key> variables that npm exports to the scripts it runs.
+// The keys of nested objects are joined with "_", array elements are
+// suffixed with their index, and scalars are stringified as JavaScript does.
+func PackageConfigEnv(config map[string]interface{}) map[string]string {
+	env := map[string]string{}
+	flattenPackageConfig(env, PackageConfigPrefix, config)
+
+	return env
+}
+
+func flattenPackageConfig(env map[string]string, prefix string, value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, v := range value {
+			flattenPackageConfig(env, prefix+key+"_", v)
+		}
+	case []interface{}:
+		for i, v := range value {
+			flattenPackageConfig(env, prefix+strconv.Itoa(i)+"_", v)
+		}
+	default:
+		env[strings.TrimSuffix(prefix, "_")] = jsString(value)
+	}
+}
+
+// jsString returns a decoded JSON scalar as String() of JavaScript returns it.
+func jsString(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	case float64:
+		return jsNumber(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// jsNumber formats a number with the shortest representation that reads
+// back as the same number, switching to exponent notation outside of
+// [1e-6, 1e21) as JavaScript does.
+func jsNumber(f float64) string {
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		s := strconv.FormatFloat(f, 'e', -1, 64)
+
+		// JavaScript writes no leading zeros in the exponent.
+		mantissa, exponent := s[:strings.Index(s, "e")+2], s[strings.Index(s, "e")+2:]
+		return mantissa + strings.TrimLeft(exponent, "0")
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
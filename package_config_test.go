@@ -0,0 +1,82 @@
+package npmstart_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testPackageConfig(t *testing.T, context spec.G, it spec.S) {
+	Expect := NewWithT(t).Expect
+
+	it("flattens the config as npm exports it", func() {
+		fixtures := []struct {
+			config string
+			env    map[string]string
+		}{
+			{
+				config: `{}`,
+				env:    map[string]string{},
+			},
+			{
+				config: `{"port": "8080", "host": "0.0.0.0"}`,
+				env: map[string]string{
+					"npm_package_config_port": "8080",
+					"npm_package_config_host": "0.0.0.0",
+				},
+			},
+			{
+				config: `{"port": 8080, "ratio": 0.5, "big": 1e21, "tiny": 0.0000001, "negative": -1.0, "whole": 1.0}`,
+				env: map[string]string{
+					"npm_package_config_port":     "8080",
+					"npm_package_config_ratio":    "0.5",
+					"npm_package_config_big":      "1e+21",
+					"npm_package_config_tiny":     "1e-7",
+					"npm_package_config_negative": "-1",
+					"npm_package_config_whole":    "1",
+				},
+			},
+			{
+				config: `{"debug": true, "verbose": false, "proxy": null, "empty": ""}`,
+				env: map[string]string{
+					"npm_package_config_debug":   "true",
+					"npm_package_config_verbose": "false",
+					"npm_package_config_proxy":   "null",
+					"npm_package_config_empty":   "",
+				},
+			},
+			{
+				config: `{"db": {"host": "localhost", "pool": {"max": 10}}, "none": {}}`,
+				env: map[string]string{
+					"npm_package_config_db_host":     "localhost",
+					"npm_package_config_db_pool_max": "10",
+				},
+			},
+			{
+				config: `{"hosts": ["a", "b"], "matrix": [[1, 2], {"x": true}], "none": []}`,
+				env: map[string]string{
+					"npm_package_config_hosts_0":    "a",
+					"npm_package_config_hosts_1":    "b",
+					"npm_package_config_matrix_0_0": "1",
+					"npm_package_config_matrix_0_1": "2",
+					"npm_package_config_matrix_1_x": "true",
+				},
+			},
+		}
+
+		for _, fixture := range fixtures {
+			var config map[string]interface{}
+			Expect(json.Unmarshal([]byte(fixture.config), &config)).To(Succeed())
+
+			Expect(npmstart.PackageConfigEnv(config)).To(Equal(fixture.env), fixture.config)
+		}
+	})
+
+	it("returns no variables without a config", func() {
+		Expect(npmstart.PackageConfigEnv(nil)).To(BeEmpty())
+	})
+}
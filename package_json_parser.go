@@ -1,39 +1,202 @@
 package npmstart
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 type PackageScripts struct {
+	Build     string `json:"build"`
 	PostStart string `json:"poststart"`
 	PreStart  string `json:"prestart"`
+	Prepare   string `json:"prepare"`
 	Start     string `json:"start"`
+
+	// all holds every script of the package.json by name, including those
+	// without a field of their own.
+	all map[string]string
+}
+
+type PackageDirectories struct {
+	Bin string `json:"bin"`
 }
 
+// PackageBin is the "bin" field of a package.json, mapping command names to
+// the files that implement them. The string form of the field, which npm
+// names after the package, is stored under the package name.
+type PackageBin map[string]string
+
 type PackageJson struct {
-	Scripts PackageScripts `json:"scripts"`
+	Bin             PackageBin             `json:"bin,omitempty"`
+	CPU             PackagePlatforms       `json:"cpu,omitempty"`
+	Config          map[string]interface{} `json:"config,omitempty"`
+	Dependencies    map[string]string      `json:"dependencies"`
+	DevDependencies map[string]string      `json:"devDependencies"`
+	Directories     PackageDirectories     `json:"directories"`
+	Engines         PackageEngines         `json:"engines"`
+	Main            string                 `json:"main,omitempty"`
+	Name            string                 `json:"name,omitempty"`
+	OS              PackagePlatforms       `json:"os,omitempty"`
+	Scripts         PackageScripts         `json:"scripts"`
+	Version         string                 `json:"version,omitempty"`
+	Workspaces      json.RawMessage        `json:"workspaces,omitempty"`
+
+	// transcoded holds the offsets of the Latin-1 bytes that were transcoded
+	// to UTF-8 before the package.json was decoded.
+	transcoded []int
 }
 
 func NewPackageJsonFromPath(filelocation string) (*PackageJson, error) {
-	file, err := os.Open(filelocation)
+	content, err := os.ReadFile(filelocation)
 	if err != nil {
 		return nil, err
 	}
 
-	defer file.Close()
+	return parsePackageJson(content)
+}
+
+func parsePackageJson(content []byte) (*PackageJson, error) {
+	content, transcoded, err := transcodeLatin1(content)
+	if err != nil {
+		return nil, err
+	}
 
 	var pkg PackageJson
 
-	err = json.NewDecoder(file).Decode(&pkg)
+	err = json.NewDecoder(bytes.NewReader(content)).Decode(&pkg)
 	if err != nil {
 		return nil, fmt.Errorf("unable to decode package.json %w", err)
 	}
 
+	pkg.transcoded = transcoded
+
+	if path, ok := pkg.Bin[""]; ok {
+		delete(pkg.Bin, "")
+		pkg.Bin[pkg.Name] = path
+	}
+
 	return &pkg, nil
 }
 
-func (pkg PackageJson) hasStartCommand() bool {
-	return pkg.Scripts.Start != ""
+// transcodeLatin1 returns the content as valid UTF-8, reading each byte that
+// is not part of a UTF-8 sequence as a Latin-1 character, along with the
+// offsets of those bytes. It fails for bytes that are not printable Latin-1
+// characters either, since the content is then unlikely to be text.
+func transcodeLatin1(content []byte) ([]byte, []int, error) {
+	if utf8.Valid(content) {
+		return content, nil, nil
+	}
+
+	var (
+		transcoded []byte
+		offsets    []int
+	)
+	for offset := 0; offset < len(content); {
+		r, size := utf8.DecodeRune(content[offset:])
+		if r == utf8.RuneError && size == 1 {
+			b := content[offset]
+			if b < 0xA0 {
+				return nil, nil, fmt.Errorf("package.json is not valid UTF-8 or Latin-1 text: byte 0x%02X at offset %d is not a printable character", b, offset)
+			}
+
+			var encoded [utf8.UTFMax]byte
+			n := utf8.EncodeRune(encoded[:], rune(b))
+			transcoded = append(transcoded, encoded[:n]...)
+			offsets = append(offsets, offset)
+		} else {
+			transcoded = append(transcoded, content[offset:offset+size]...)
+		}
+		offset += size
+	}
+
+	return transcoded, offsets, nil
+}
+
+// maxListedOffsets is the number of offsets listed by formatOffsets.
+const maxListedOffsets = 10
+
+// formatOffsets lists the given byte offsets, truncating long lists.
+func formatOffsets(offsets []int) string {
+	var listed []string
+	for i, offset := range offsets {
+		if i == maxListedOffsets {
+			listed = append(listed, fmt.Sprintf("and %d more", len(offsets)-maxListedOffsets))
+			break
+		}
+		listed = append(listed, strconv.Itoa(offset))
+	}
+
+	return strings.Join(listed, ", ")
+}
+
+func (s *PackageScripts) UnmarshalJSON(data []byte) error {
+	var all map[string]string
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+
+	type fields PackageScripts
+	var scripts fields
+	if err := json.Unmarshal(data, &scripts); err != nil {
+		return err
+	}
+
+	*s = PackageScripts(scripts)
+	s.all = all
+	return nil
+}
+
+// Script returns the script of the given name. The boolean return is false
+// when the package.json does not define it.
+func (s PackageScripts) Script(name string) (string, bool) {
+	script, ok := s.all[name]
+	return script, ok
+}
+
+func (b *PackageBin) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*b = PackageBin{"": path}
+		return nil
+	}
+
+	var entries map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	*b = entries
+	return nil
+}
+
+// soleBin returns the path of the only "bin" entry of the package.json. The
+// boolean return is false when there are no entries, and an error is returned
+// when there is more than one.
+func (pkg PackageJson) soleBin() (string, bool, error) {
+	switch len(pkg.Bin) {
+	case 0:
+		return "", false, nil
+	case 1:
+		for _, path := range pkg.Bin {
+			return path, true, nil
+		}
+	}
+
+	var names []string
+	for name := range pkg.Bin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return "", false, fmt.Errorf("package.json has no start script and declares several bin entries (%s): add a start script that runs one of them", strings.Join(names, ", "))
+}
+
+func (pkg PackageJson) hasWorkspaces() bool {
+	return len(pkg.Workspaces) > 0 && string(pkg.Workspaces) != "null"
 }
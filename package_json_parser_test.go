@@ -49,6 +49,51 @@ func testPackageJsonParser(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("when the package.json declares bin entries", func() {
+		var packageLocation string
+		var workingDir string
+
+		it.Before(func() {
+			var err error
+			workingDir, err = os.MkdirTemp("", "working-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			packageLocation = filepath.Join(workingDir, "package.json")
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(workingDir)).To(Succeed())
+		})
+
+		it("parses the object form", func() {
+			Expect(os.WriteFile(packageLocation, []byte(`{"bin": {"some-cli": "./cli.js", "other-cli": "./other.js"}}`), 0600)).To(Succeed())
+
+			pkg, err := npmstart.NewPackageJsonFromPath(packageLocation)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pkg.Bin).To(Equal(npmstart.PackageBin{
+				"some-cli":  "./cli.js",
+				"other-cli": "./other.js",
+			}))
+		})
+
+		it("names the string form after the package", func() {
+			Expect(os.WriteFile(packageLocation, []byte(`{"name": "some-package", "bin": "./cli.js"}`), 0600)).To(Succeed())
+
+			pkg, err := npmstart.NewPackageJsonFromPath(packageLocation)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pkg.Bin).To(Equal(npmstart.PackageBin{
+				"some-package": "./cli.js",
+			}))
+		})
+
+		it("fails parsing any other form", func() {
+			Expect(os.WriteFile(packageLocation, []byte(`{"bin": ["./cli.js"]}`), 0600)).To(Succeed())
+
+			_, err := npmstart.NewPackageJsonFromPath(packageLocation)
+			Expect(err).To(MatchError(ContainSubstring("unable to decode package.json")))
+		})
+	})
+
 	context("when the package.json is not a valid json file", func() {
 		var packageLocation string
 		var workingDir string
@@ -72,6 +117,40 @@ func testPackageJsonParser(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("when the package.json contains Latin-1 bytes", func() {
+		var packageLocation string
+		var workingDir string
+
+		it.Before(func() {
+			var err error
+			workingDir, err = os.MkdirTemp("", "working-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			packageLocation = filepath.Join(workingDir, "package.json")
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(workingDir)).To(Succeed())
+		})
+
+		it("transcodes them to UTF-8", func() {
+			Expect(os.WriteFile(packageLocation, []byte("{\"scripts\": {\"start\": \"echo caf\xe9\"}}"), 0600)).To(Succeed())
+
+			pkg, err := npmstart.NewPackageJsonFromPath(packageLocation)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pkg.Scripts.Start).To(Equal("echo café"))
+		})
+
+		context("when the bytes are not printable Latin-1 characters", func() {
+			it("fails parsing", func() {
+				Expect(os.WriteFile(packageLocation, []byte("{\"scripts\": {\"start\": \"echo \x81\"}}"), 0600)).To(Succeed())
+
+				_, err := npmstart.NewPackageJsonFromPath(packageLocation)
+				Expect(err).To(MatchError("package.json is not valid UTF-8 or Latin-1 text: byte 0x81 at offset 28 is not a printable character"))
+			})
+		})
+	})
+
 	context("when the path to package.json is invalid", func() {
 		it("fails parsing", func() {
 			_, err := npmstart.NewPackageJsonFromPath("/tmp/non-existent")
@@ -0,0 +1,87 @@
+package npmstart
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// platformAPI is the <major>.<minor> version of the platform API that the
+// lifecycle advertises through $CNB_PLATFORM_API.
+type platformAPI struct {
+	major int
+	minor int
+}
+
+// lookupPlatformAPI returns the platform API advertised in the build
+// environment. The boolean return is false when no version is advertised.
+func lookupPlatformAPI(env Environment) (platformAPI, bool, error) {
+	value, ok := env("CNB_PLATFORM_API")
+	if !ok || value == "" {
+		return platformAPI{}, false, nil
+	}
+
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) != 2 {
+		return platformAPI{}, false, classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse CNB_PLATFORM_API value %s: expected <major>.<minor>", value))
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return platformAPI{}, false, classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse CNB_PLATFORM_API value %s: %w", value, err))
+	}
+
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return platformAPI{}, false, classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse CNB_PLATFORM_API value %s: %w", value, err))
+	}
+
+	return platformAPI{major: major, minor: minor}, true, nil
+}
+
+func (a platformAPI) atLeast(major, minor int) bool {
+	if a.major != major {
+		return a.major > major
+	}
+	return a.minor >= minor
+}
+
+func (a platformAPI) String() string {
+	return fmt.Sprintf("%d.%d", a.major, a.minor)
+}
+
+// argsOverridableSince is the platform API that lets users append arguments
+// to a process at launch.
+var argsOverridableSince = platformAPI{major: 0, minor: 10}
+
+// checkArgsPolicy reports how the arguments of the start process are
+// emitted. The first return is whether arguments appended at launch are
+// forwarded to the start command, the second whether the start command is
+// locked against them. Without BP_NPM_START_ARGS_POLICY, arguments are
+// forwarded whenever the platform API supports it (platform API 0.10+). The
+// policy is the value of BP_NPM_START_ARGS_POLICY, which the Configuration
+// has validated.
+func checkArgsPolicy(env Environment, policy string) (bool, bool, error) {
+	api, ok, err := lookupPlatformAPI(env)
+	if err != nil {
+		return false, false, err
+	}
+	supported := ok && api.atLeast(argsOverridableSince.major, argsOverridableSince.minor)
+
+	switch policy {
+	case ArgsPolicyOverridable:
+		if !ok {
+			return false, false, classify(ErrInvalidConfiguration, fmt.Errorf("BP_NPM_START_ARGS_POLICY=%s requires platform API %s or newer, but the platform does not advertise its API through CNB_PLATFORM_API", policy, argsOverridableSince))
+		}
+
+		if !supported {
+			return false, false, classify(ErrInvalidConfiguration, fmt.Errorf("BP_NPM_START_ARGS_POLICY=%s requires platform API %s or newer, but the platform implements %s, which does not let arguments be appended at launch", policy, argsOverridableSince, api))
+		}
+
+		return true, false, nil
+	case ArgsPolicyLocked:
+		return false, true, nil
+	default:
+		return supported, false, nil
+	}
+}
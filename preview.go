@@ -0,0 +1,140 @@
+package npmstart
+
+import (
+	"context"
+	"io"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// The features that produce the processes reported by Preview.
+const (
+	ProcessSourceStartScript = "start-script"
+	ProcessSourceBin         = "bin"
+	ProcessSourceEntrypoint  = "server.js"
+	ProcessSourceCommandHook = "command-hook"
+	ProcessSourceLiveReload  = "live-reload"
+	ProcessSourceMaintenance = "maintenance"
+	ProcessSourceJob         = "job"
+	ProcessSourceDebugShell  = "debug-shell"
+	ProcessSourceDescriptor  = "descriptor"
+	ProcessSourceHealthcheck = "healthcheck"
+)
+
+// PreviewLayersPath is the layers directory that the commands reported by
+// Preview refer to: the directory that the lifecycle gives this buildpack.
+const PreviewLayersPath = "/layers/paketo-buildpacks_npm-start"
+
+// ProcessPreview is a process that an image built from an app would launch.
+type ProcessPreview struct {
+	Type    string
+	Command string
+	Args    []string
+	Default bool
+
+	// WorkingDir is the directory that the process runs its command in.
+	WorkingDir string
+
+	// Source is the ProcessSource* feature that produced the process.
+	Source string
+}
+
+// Preview returns the processes that Build would launch the app in dir with,
+// had both Detect and Build run in a build environment holding env. It
+// resolves the processes with the same code as Build, never writes to disk,
+// does not read the environment of the process and is safe for concurrent
+// use. Apps that Detect would not pass return the same error that Detect
+// would, and apps configured with BP_NPM_START_COMMAND_HOOK cannot be
+// previewed, as the hook runs during the build.
+func Preview(dir string, env map[string]string) ([]ProcessPreview, error) {
+	ctx := context.Background()
+	environment := mapEnvironment(env)
+	logger := scribe.NewEmitter(io.Discard)
+	pathParser := ProjectPathParser{env: environment}
+
+	config, _, err := ParseConfiguration(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	var environ []string
+	for name, value := range env {
+		environ = append(environ, name+"="+value)
+	}
+	excerpts := newExcerpter(config, environ)
+
+	location, err := LocatePackageJson(ctx, pathParser, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := LoadPackageJson(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = applyDescriptor(config, pkg, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validatePackageJson(environment, *pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCompetingRuntime(config, *pkg, location)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkStaticSite(config, excerpts, *pkg, location, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// The lifecycle hands the requirements of Detect to Build as the entries
+	// of the buildpack plan.
+	requirements := PlanRequirements(config, *pkg, logger)
+
+	var plan packit.BuildpackPlan
+	for _, requirement := range requirements.Requires {
+		metadata, _ := requirement.Metadata.(map[string]interface{})
+		plan.Entries = append(plan.Entries, packit.BuildpackPlanEntry{
+			Name:     requirement.Name,
+			Metadata: metadata,
+		})
+	}
+
+	launch, err := computeLaunch(ctx, launchInputs{
+		WorkingDir: dir,
+		LayersPath: PreviewLayersPath,
+		Plan:       plan,
+		Env:        environment,
+		Environ:    environ,
+		PathParser: pathParser,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var previews []ProcessPreview
+	for i, process := range launch.processes {
+		workingDir := launch.projectPath
+		if launch.sources[i] == ProcessSourceMaintenance {
+			workingDir = dir
+		}
+
+		previews = append(previews, ProcessPreview{
+			Type:       process.Type,
+			Command:    process.Command,
+			Args:       process.Args,
+			Default:    process.Default,
+			WorkingDir: workingDir,
+			Source:     launch.sources[i],
+		})
+	}
+
+	return previews, nil
+}
@@ -0,0 +1,282 @@
+package npmstart_test
+
+import (
+	gocontext "context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testPreview(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		cnbDir     string
+		layersDir  string
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		cnbDir, err = os.MkdirTemp("", "cnb")
+		Expect(err).NotTo(HaveOccurred())
+
+		layersDir, err = os.MkdirTemp("", "layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Mkdir(filepath.Join(cnbDir, "bin"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "maintenance-server"), nil, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "wait-for-bindings"), nil, 0755)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+		Expect(os.RemoveAll(cnbDir)).To(Succeed())
+		Expect(os.RemoveAll(layersDir)).To(Succeed())
+	})
+
+	// previewBuild runs Detect and Build with env set in the environment of
+	// the process, handing the requirements of Detect to Build the way the
+	// lifecycle does, and returns the processes of the build as previews.
+	previewBuild := func(dir string, env map[string]string) []npmstart.ProcessPreview {
+		for name, value := range env {
+			os.Setenv(name, value)
+		}
+		defer func() {
+			for name := range env {
+				os.Unsetenv(name)
+			}
+		}()
+
+		pathParser := npmstart.NewProjectPathParser()
+		logger := scribe.NewEmitter(io.Discard)
+
+		detected, err := npmstart.DetectWithContext(gocontext.Background(), pathParser, logger)(packit.DetectContext{WorkingDir: dir})
+		Expect(err).NotTo(HaveOccurred())
+
+		var plan packit.BuildpackPlan
+		for _, requirement := range detected.Plan.Requires {
+			plan.Entries = append(plan.Entries, packit.BuildpackPlanEntry{
+				Name:     requirement.Name,
+				Metadata: requirement.Metadata.(map[string]interface{}),
+			})
+		}
+
+		result, err := npmstart.Build(pathParser, logger)(packit.BuildContext{
+			WorkingDir: dir,
+			CNBPath:    cnbDir,
+			Plan:       plan,
+			Layers:     packit.Layers{Path: layersDir},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		projectPath := filepath.Join(dir, env["BP_NODE_PROJECT_PATH"])
+
+		var previews []npmstart.ProcessPreview
+		for _, process := range result.Launch.Processes {
+			preview := npmstart.ProcessPreview{
+				Type:       process.Type,
+				Command:    strings.Replace(process.Command, layersDir, npmstart.PreviewLayersPath, 1),
+				Args:       process.Args,
+				Default:    process.Default,
+				WorkingDir: projectPath,
+			}
+			if process.Type == "maintenance" {
+				preview.WorkingDir = dir
+			}
+			previews = append(previews, preview)
+		}
+
+		return previews
+	}
+
+	writeApp := func(dir, packageJson string) {
+		Expect(os.MkdirAll(dir, os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "package.json"), []byte(packageJson), 0600)).To(Succeed())
+	}
+
+	it("returns the processes that Build launches the app with", func() {
+		writeApp(filepath.Join(workingDir, "start-script"), `{"scripts": {"prestart": "some-prestart-command", "start": "some-start-command"}}`)
+		writeApp(filepath.Join(workingDir, "bin"), `{"name": "some-cli", "bin": "./cli.js"}`)
+		Expect(os.WriteFile(filepath.Join(workingDir, "bin", "cli.js"), nil, 0600)).To(Succeed())
+		writeApp(filepath.Join(workingDir, "project", "some-project-dir"), `{"scripts": {"start": "some-start-command"}}`)
+
+		fixtures := []struct {
+			dir     string
+			env     map[string]string
+			sources []string
+		}{
+			{
+				dir:     "start-script",
+				sources: []string{npmstart.ProcessSourceStartScript},
+			},
+			{
+				dir:     "bin",
+				sources: []string{npmstart.ProcessSourceBin},
+			},
+			{
+				dir:     "project",
+				env:     map[string]string{"BP_NODE_PROJECT_PATH": "some-project-dir", "CNB_PLATFORM_API": "0.10"},
+				sources: []string{npmstart.ProcessSourceStartScript},
+			},
+			{
+				dir:     "start-script",
+				env:     map[string]string{"BP_LIVE_RELOAD_ENABLED": "true"},
+				sources: []string{npmstart.ProcessSourceLiveReload, npmstart.ProcessSourceStartScript},
+			},
+			{
+				dir:     "start-script",
+				env:     map[string]string{"BP_NPM_START_WORKLOAD_TYPE": "worker", "BP_NPM_START_NO_DEFAULT_PROCESS": "true"},
+				sources: []string{npmstart.ProcessSourceStartScript},
+			},
+			{
+				dir:     "start-script",
+				env:     map[string]string{"BP_NPM_START_MAINTENANCE": "true", "BP_NPM_START_RUN_PREPARE": "true"},
+				sources: []string{npmstart.ProcessSourceStartScript, npmstart.ProcessSourceMaintenance},
+			},
+			{
+				dir:     "start-script",
+				env:     map[string]string{"BP_NPM_START_HEALTHCHECK_SCRIPT": "prestart"},
+				sources: []string{npmstart.ProcessSourceStartScript, npmstart.ProcessSourceHealthcheck},
+			},
+		}
+
+		for _, fixture := range fixtures {
+			dir := filepath.Join(workingDir, fixture.dir)
+			description := fmt.Sprintf("%s %v", fixture.dir, fixture.env)
+
+			previews, err := npmstart.Preview(dir, fixture.env)
+			Expect(err).NotTo(HaveOccurred(), description)
+
+			var sources []string
+			for i := range previews {
+				sources = append(sources, previews[i].Source)
+				previews[i].Source = ""
+			}
+
+			Expect(sources).To(Equal(fixture.sources), description)
+			Expect(previews).To(Equal(previewBuild(dir, fixture.env)), description)
+		}
+	})
+
+	it("does not write to disk", func() {
+		writeApp(workingDir, `{"scripts": {"start": "some-start-command"}}`)
+
+		_, err := npmstart.Preview(workingDir, map[string]string{"BP_NPM_START_MAINTENANCE": "true"})
+		Expect(err).NotTo(HaveOccurred())
+
+		entries, err := os.ReadDir(workingDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+
+	it("does not read the environment of the process", func() {
+		writeApp(workingDir, `{"scripts": {"start": "some-start-command"}}`)
+
+		os.Setenv("BP_NPM_START_WORKLOAD_TYPE", "worker")
+		defer os.Unsetenv("BP_NPM_START_WORKLOAD_TYPE")
+
+		previews, err := npmstart.Preview(workingDir, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(previews).To(HaveLen(1))
+		Expect(previews[0].Type).To(Equal("web"))
+	})
+
+	it("is safe for concurrent use", func() {
+		writeApp(workingDir, `{"scripts": {"start": "some-start-command"}}`)
+
+		var wg sync.WaitGroup
+		types := make([]string, 8)
+		for i := range types {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				env := map[string]string{}
+				if i%2 == 1 {
+					env["BP_NPM_START_WORKLOAD_TYPE"] = "worker"
+				}
+
+				previews, err := npmstart.Preview(workingDir, env)
+				if err == nil && len(previews) == 1 {
+					types[i] = previews[0].Type
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(types).To(Equal([]string{"web", "worker", "web", "worker", "web", "worker", "web", "worker"}))
+	})
+
+	context("failure cases", func() {
+		context("when there is no package.json", func() {
+			it("returns an error", func() {
+				_, err := npmstart.Preview(workingDir, nil)
+				Expect(err).To(MatchError(ContainSubstring("no package.json in")))
+			})
+		})
+
+		context("when Detect would not pass the app", func() {
+			it("returns the error of Detect", func() {
+				writeApp(workingDir, `{}`)
+
+				_, err := npmstart.Preview(workingDir, nil)
+				Expect(err).To(MatchError(npmstart.NoStartScriptError))
+			})
+		})
+
+		context("when the build environment sets a command hook", func() {
+			it("returns an error", func() {
+				writeApp(workingDir, `{"scripts": {"start": "some-start-command"}}`)
+
+				_, err := npmstart.Preview(workingDir, map[string]string{"BP_NPM_START_COMMAND_HOOK": "hook.js"})
+				Expect(err).To(MatchError("BP_NPM_START_COMMAND_HOOK cannot be previewed, as the hook runs during the build"))
+			})
+		})
+
+		context("when a script cannot be joined into the start command", func() {
+			it.Before(func() {
+				os.Setenv("BP_NPM_START_EXCERPT_LENGTH", "500")
+				os.Setenv("HOST_TOKEN", "some-host-secret")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NPM_START_EXCERPT_LENGTH")
+				os.Unsetenv("HOST_TOKEN")
+			})
+
+			it("quotes it with the excerpt length and secrets of the given environment", func() {
+				writeApp(workingDir, `{"scripts": {"prestart": "echo some-preview-secret and then some more words &&", "start": "some-start-command"}}`)
+
+				_, err := npmstart.Preview(workingDir, map[string]string{
+					"BP_NPM_START_EXCERPT_LENGTH": "30",
+					"PREVIEW_TOKEN":               "some-preview-secret",
+				})
+				Expect(err).To(MatchError(ContainSubstring(`the prestart script "echo [redacted] and then some…" has a dangling "&&"`)))
+			})
+		})
+
+		context("when the build environment holds an invalid value", func() {
+			it("returns an error", func() {
+				writeApp(workingDir, `{"scripts": {"start": "some-start-command"}}`)
+
+				_, err := npmstart.Preview(workingDir, map[string]string{"BP_NPM_START_MAINTENANCE": "sometimes"})
+				Expect(err).To(MatchError(ContainSubstring("failed to parse BP_NPM_START_MAINTENANCE value sometimes")))
+			})
+		})
+	})
+}
@@ -0,0 +1,76 @@
+package npmstart
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProcessEnvVar is a launch environment variable scoped to a single process
+// type.
+type ProcessEnvVar struct {
+	Process string
+	Name    string
+	Value   string
+}
+
+// ParseProcessEnv parses the value of $BP_NPM_START_PROCESS_ENV, a list of
+// <process>:<name>=<value> entries separated by ";". A literal ";" or "\" in
+// a value is written as "\;" or "\\".
+func ParseProcessEnv(value string) ([]ProcessEnvVar, error) {
+	var vars []ProcessEnvVar
+	for _, entry := range splitEscaped(value, ';') {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse BP_NPM_START_PROCESS_ENV entry %q: expected <process>:<name>=<value>", entry))
+		}
+		process := strings.TrimSpace(parts[0])
+
+		parts = strings.SplitN(parts[1], "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse BP_NPM_START_PROCESS_ENV entry %q: expected <process>:<name>=<value>", entry))
+		}
+		name, val := strings.TrimSpace(parts[0]), parts[1]
+
+		vars = append(vars, ProcessEnvVar{Process: process, Name: name, Value: val})
+	}
+
+	return vars, nil
+}
+
+// splitEscaped splits s on every separator that is not preceded by a
+// backslash, and unescapes "\<separator>" and "\\" in the resulting fields.
+func splitEscaped(s string, separator rune) []string {
+	var (
+		fields  []string
+		current strings.Builder
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			if r != separator && r != '\\' {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == separator:
+			fields = append(fields, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if escaped {
+		current.WriteRune('\\')
+	}
+
+	return append(fields, current.String())
+}
@@ -0,0 +1,60 @@
+package npmstart_test
+
+import (
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testProcessEnv(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseProcessEnv", func() {
+		it("parses each entry", func() {
+			vars, err := npmstart.ParseProcessEnv("worker:NODE_OPTIONS=--max-old-space-size=4096; web:NODE_ENV=production")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vars).To(Equal([]npmstart.ProcessEnvVar{
+				{Process: "worker", Name: "NODE_OPTIONS", Value: "--max-old-space-size=4096"},
+				{Process: "web", Name: "NODE_ENV", Value: "production"},
+			}))
+		})
+
+		it("unescapes separators and backslashes in values", func() {
+			vars, err := npmstart.ParseProcessEnv(`web:SOME_LIST=a\;b\\;web:SOME_PATH=C:\dir`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vars).To(Equal([]npmstart.ProcessEnvVar{
+				{Process: "web", Name: "SOME_LIST", Value: `a;b\`},
+				{Process: "web", Name: "SOME_PATH", Value: `C:\dir`},
+			}))
+		})
+
+		it("allows empty values and ignores empty entries", func() {
+			vars, err := npmstart.ParseProcessEnv("web:NODE_ENV=;;")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vars).To(Equal([]npmstart.ProcessEnvVar{
+				{Process: "web", Name: "NODE_ENV", Value: ""},
+			}))
+		})
+
+		it("returns nothing for an empty value", func() {
+			vars, err := npmstart.ParseProcessEnv("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vars).To(BeEmpty())
+		})
+
+		context("failure cases", func() {
+			it("rejects entries without a process", func() {
+				_, err := npmstart.ParseProcessEnv("NODE_ENV=production")
+				Expect(err).To(MatchError(`failed to parse BP_NPM_START_PROCESS_ENV entry "NODE_ENV=production": expected <process>:<name>=<value>`))
+			})
+
+			it("rejects entries without a value", func() {
+				_, err := npmstart.ParseProcessEnv("web:NODE_ENV")
+				Expect(err).To(MatchError(ContainSubstring("expected <process>:<name>=<value>")))
+			})
+		})
+	})
+}
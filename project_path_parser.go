@@ -9,17 +9,26 @@ import (
 
 // ProjectPathParser provides a mechanism for determining the proper working
 // directory for the build process.
-type ProjectPathParser struct{}
+type ProjectPathParser struct {
+	env Environment
+}
 
 // NewProjectPathParser creates an instance of a ProjectPathParser.
 func NewProjectPathParser() ProjectPathParser {
-	return ProjectPathParser{}
+	return ProjectPathParser{env: processEnvironment}
 }
 
 // Get will resolve the $BP_NODE_PROJECT_PATH environment variable. It
 // validates that $BP_NODE_PROJECT_PATH is valid relative to the provided path.
+// The value is normalized first, so "./custom/", "custom/./" and "custom" all
+// resolve to the same project path.
 func (p ProjectPathParser) Get(path string) (string, error) {
-	customProjPath := os.Getenv("BP_NODE_PROJECT_PATH")
+	env := p.env
+	if env == nil {
+		env = processEnvironment
+	}
+
+	customProjPath := normalizeProjectPath(env.get("BP_NODE_PROJECT_PATH"))
 	if customProjPath == "" {
 		return path, nil
 	}
@@ -33,5 +42,21 @@ func (p ProjectPathParser) Get(path string) (string, error) {
 		return "", err
 	}
 
-	return filepath.Clean(filepath.Join(path, customProjPath)), nil
+	return filepath.Join(path, customProjPath), nil
+}
+
+// normalizeProjectPath cleans the given project path, removing any leading
+// "./" and trailing "/". It returns an empty string for a path that refers to
+// the working directory itself.
+func normalizeProjectPath(projectPath string) string {
+	if projectPath == "" {
+		return ""
+	}
+
+	projectPath = filepath.Clean(projectPath)
+	if projectPath == "." {
+		return ""
+	}
+
+	return projectPath
 }
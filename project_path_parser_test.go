@@ -45,6 +45,26 @@ func testProjectPathParser(t *testing.T, context spec.G, it spec.S) {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal(filepath.Join(workingDir, "custom", "path")))
 		})
+
+		it("normalizes the set project path", func() {
+			for _, projectPath := range []string{"custom/path", "./custom/path", "custom/path/", "./custom/path/./", "custom//path"} {
+				os.Setenv("BP_NODE_PROJECT_PATH", projectPath)
+
+				result, err := projectPathParser.Get(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(filepath.Join(workingDir, "custom", "path")), "resolving %q", projectPath)
+			}
+		})
+
+		it("returns the working directory when the project path refers to it", func() {
+			for _, projectPath := range []string{"", ".", "./", "custom/.."} {
+				os.Setenv("BP_NODE_PROJECT_PATH", projectPath)
+
+				result, err := projectPathParser.Get(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(workingDir), "resolving %q", projectPath)
+			}
+		})
 	})
 
 	context("failure cases", func() {
@@ -63,6 +83,21 @@ func testProjectPathParser(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when the normalized project path subdirectory does not exist", func() {
+			it.Before(func() {
+				os.Setenv("BP_NODE_PROJECT_PATH", "./some-garbage/")
+			})
+
+			it.After(func() {
+				os.Unsetenv("BP_NODE_PROJECT_PATH")
+			})
+
+			it("returns an error naming the normalized path", func() {
+				_, err := projectPathParser.Get(workingDir)
+				Expect(err).To(MatchError("expected value derived from BP_NODE_PROJECT_PATH [some-garbage] to be an existing directory"))
+			})
+		})
+
 		context("when the project path subdirectory does not exist", func() {
 			it.Before(func() {
 				os.Setenv("BP_NODE_PROJECT_PATH", "some-garbage")
@@ -0,0 +1,74 @@
+package npmstart
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the values of secrets in persisted image metadata.
+const Redacted = "[redacted]"
+
+// secretName matches the names of environment variables that are likely to
+// hold secrets.
+var secretName = regexp.MustCompile(`(?i)(TOKEN|SECRET|PASSWORD|PASSWD|PASSPHRASE|CREDENTIAL|API_?KEY|PRIVATE_?KEY|ACCESS_?KEY|AUTH)`)
+
+// minSecretLength is the length below which values are not redacted, since
+// they would match too many unrelated strings.
+const minSecretLength = 4
+
+// Redactor replaces the values of secret-looking environment variables in
+// strings that are persisted in image metadata, such as labels. References
+// to the variables, like $GITHUB_TOKEN, are left as they are.
+type Redactor struct {
+	secrets []string
+}
+
+// NewRedactor returns a Redactor for the secrets in the given environment,
+// in the form returned by os.Environ.
+func NewRedactor(environ []string) Redactor {
+	var secrets []string
+	for _, variable := range environ {
+		i := strings.Index(variable, "=")
+		if i < 0 {
+			continue
+		}
+
+		name, value := variable[:i], variable[i+1:]
+		if secretName.MatchString(name) && len(value) >= minSecretLength {
+			secrets = append(secrets, value)
+		}
+	}
+
+	// Longer secrets are replaced first so that a secret containing another
+	// one is redacted as a whole.
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+
+	return Redactor{secrets: secrets}
+}
+
+// Redact returns s with every secret value replaced by Redacted.
+func (r Redactor) Redact(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+
+	return s
+}
+
+// RedactLabels returns a copy of the labels with their keys and values
+// redacted.
+func (r Redactor) RedactLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(labels))
+	for key, value := range labels {
+		redacted[r.Redact(key)] = r.Redact(value)
+	}
+
+	return redacted
+}
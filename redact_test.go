@@ -0,0 +1,67 @@
+package npmstart_test
+
+import (
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRedactor(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		redactor npmstart.Redactor
+	)
+
+	it.Before(func() {
+		redactor = npmstart.NewRedactor([]string{
+			"GITHUB_TOKEN=ghp_some-token",
+			"DB_PASSWORD=some-password",
+			"NPM_AUTH=some-password-and-more",
+			"api_key=some-key",
+			"SHORT_SECRET=abc",
+			"NODE_ENV=production",
+			"MALFORMED",
+		})
+	})
+
+	context("Redact", func() {
+		it("replaces the values of secret-looking variables", func() {
+			Expect(redactor.Redact("node server.js --token ghp_some-token")).To(Equal("node server.js --token [redacted]"))
+			Expect(redactor.Redact("some-key:some-password")).To(Equal("[redacted]:[redacted]"))
+		})
+
+		it("redacts a secret that contains another one as a whole", func() {
+			Expect(redactor.Redact("auth=some-password-and-more")).To(Equal("auth=[redacted]"))
+		})
+
+		it("leaves references to the variables unexpanded", func() {
+			Expect(redactor.Redact("node server.js --token $GITHUB_TOKEN")).To(Equal("node server.js --token $GITHUB_TOKEN"))
+		})
+
+		it("leaves the values of other and very short variables alone", func() {
+			Expect(redactor.Redact("NODE_ENV=production abc")).To(Equal("NODE_ENV=production abc"))
+		})
+	})
+
+	context("RedactLabels", func() {
+		it("redacts the keys and values", func() {
+			Expect(redactor.RedactLabels(map[string]string{
+				"some-label":           "--token ghp_some-token",
+				"label-some-password":  "some-value",
+				"io.paketo.some-label": "production",
+			})).To(Equal(map[string]string{
+				"some-label":           "--token [redacted]",
+				"label-[redacted]":     "some-value",
+				"io.paketo.some-label": "production",
+			}))
+		})
+
+		it("returns nil for nil labels", func() {
+			Expect(redactor.RedactLabels(nil)).To(BeNil())
+		})
+	})
+}
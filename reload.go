@@ -0,0 +1,108 @@
+package npmstart
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"text/template"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+const (
+	// Watchexec is the default live reload provider.
+	Watchexec = "watchexec"
+
+	// Entr is the entr live reload provider.
+	Entr = "entr"
+)
+
+// reloadProcess returns the default web process that restarts the given
+// command whenever files in the project path change, using the given live
+// reload provider. A non-empty command template takes precedence over the
+// built-in command of known providers and is required for unknown ones. A
+// non-empty signal replaces the signal the provider restarts the command
+// with.
+func reloadProcess(provider, commandTemplate, signal, projectPath, command string, args []string) (packit.Process, error) {
+	ignored := []string{
+		filepath.Join(projectPath, "package.json"),
+		filepath.Join(projectPath, "package-lock.json"),
+		filepath.Join(projectPath, "node_modules"),
+	}
+
+	if commandTemplate != "" {
+		tmpl, err := template.New("BP_LIVE_RELOAD_COMMAND_TEMPLATE").Option("missingkey=error").Funcs(template.FuncMap{
+			"command": func() string { return shellJoin(append([]string{command}, args...)...) },
+			"watch":   func() string { return shellQuote(projectPath) },
+			"signal":  func() string { return signal },
+		}).Parse(commandTemplate)
+		if err != nil {
+			return packit.Process{}, classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse BP_LIVE_RELOAD_COMMAND_TEMPLATE: %w", err))
+		}
+
+		buffer := bytes.NewBuffer(nil)
+		err = tmpl.Execute(buffer, nil)
+		if err != nil {
+			return packit.Process{}, classify(ErrInvalidConfiguration, fmt.Errorf("failed to render BP_LIVE_RELOAD_COMMAND_TEMPLATE: %w", err))
+		}
+
+		return packit.Process{
+			Type:    "web",
+			Command: "bash",
+			Args:    []string{"-c", buffer.String()},
+			Default: true,
+			Direct:  true,
+		}, nil
+	}
+
+	switch provider {
+	case Watchexec:
+		options := []string{"--restart"}
+		if signal != "" {
+			options = append(options, "--signal", signal)
+		}
+
+		return packit.Process{
+			Type:    "web",
+			Command: "watchexec",
+			Args: append(append(options,
+				"--shell", "none",
+				"--watch", projectPath,
+				"--ignore", ignored[0],
+				"--ignore", ignored[1],
+				"--ignore", ignored[2],
+				"--",
+				command,
+			), args...),
+			Default: true,
+			Direct:  true,
+		}, nil
+
+	case Entr:
+		if signal != "" {
+			return packit.Process{}, classify(ErrInvalidConfiguration, fmt.Errorf("the entr live reload provider always restarts with SIGTERM: unset BP_LIVE_RELOAD_SIGNAL or set BP_LIVE_RELOAD_COMMAND_TEMPLATE"))
+		}
+
+		// entr reads the list of files to watch from stdin and, unlike
+		// watchexec, does not pick up files created after it has started.
+		files := shellJoin(
+			"find", projectPath,
+			"(", "-path", ignored[2], "-o", "-path", ignored[0], "-o", "-path", ignored[1], ")", "-prune",
+			"-o", "-type", "f", "-print",
+		)
+
+		return packit.Process{
+			Type:    "web",
+			Command: "bash",
+			Args: []string{
+				"-c",
+				fmt.Sprintf("%s | entr -n -r %s", files, shellJoin(append([]string{command}, args...)...)),
+			},
+			Default: true,
+			Direct:  true,
+		}, nil
+
+	default:
+		return packit.Process{}, classify(ErrInvalidConfiguration, fmt.Errorf("unknown live reload provider %q: set BP_LIVE_RELOAD_COMMAND_TEMPLATE to configure its command", provider))
+	}
+}
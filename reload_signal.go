@@ -0,0 +1,54 @@
+package npmstart
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GracefulRestartLibrary is a dependency that makes an app restart gracefully
+// on a particular signal, which the live reload provider should then send
+// instead of its default.
+type GracefulRestartLibrary struct {
+	Dependency string
+	Signal     string
+}
+
+// GracefulRestartLibraries lists the dependencies that select the live
+// reload signal when $BP_LIVE_RELOAD_SIGNAL is not set. The first entry
+// found in the app's dependencies wins.
+var GracefulRestartLibraries = []GracefulRestartLibrary{
+	{Dependency: "naught", Signal: "SIGUSR2"},
+	{Dependency: "up", Signal: "SIGUSR2"},
+	{Dependency: "pm2", Signal: "SIGUSR2"},
+}
+
+var signalName = regexp.MustCompile(`^SIG[A-Z0-9]+$`)
+
+// lookupReloadSignal returns the signal that the live reload provider should
+// send to restart the app: value, the value of $BP_LIVE_RELOAD_SIGNAL, or the
+// signal of the first graceful restart library among the app's dependencies.
+// The second return names that library, and is empty otherwise. An empty
+// signal leaves the provider's default in place.
+func lookupReloadSignal(value string, pkg PackageJson) (string, string, error) {
+	if value != "" {
+		signal := strings.ToUpper(value)
+		if !strings.HasPrefix(signal, "SIG") {
+			signal = "SIG" + signal
+		}
+
+		if !signalName.MatchString(signal) {
+			return "", "", classify(ErrInvalidConfiguration, fmt.Errorf("failed to parse BP_LIVE_RELOAD_SIGNAL value %s: not a signal name", value))
+		}
+
+		return signal, "", nil
+	}
+
+	for _, library := range GracefulRestartLibraries {
+		if _, ok := pkg.Dependencies[library.Dependency]; ok {
+			return library.Signal, library.Dependency, nil
+		}
+	}
+
+	return "", "", nil
+}
@@ -0,0 +1,112 @@
+package npmstart
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// ReproducibleTime is the modification time given to every file that Build
+// writes into its layers, so that identical builds write identical files.
+// It is the timestamp that the lifecycle normalizes image layers to.
+var ReproducibleTime = time.Date(1980, time.January, 1, 0, 0, 1, 0, time.UTC)
+
+// writeLayerFiles writes the environment and the exec.d executables of the
+// layer into it, laid out as packit lays them out after Build returns, and
+// clears them from the layer so that packit leaves the files alone. Build can
+// then give them ReproducibleTime along with the rest of the layer.
+func writeLayerFiles(layer *packit.Layer) error {
+	envs := map[string]packit.Environment{
+		"env":        layer.SharedEnv,
+		"env.launch": layer.LaunchEnv,
+		"env.build":  layer.BuildEnv,
+	}
+	for process, env := range layer.ProcessLaunchEnv {
+		envs[filepath.Join("env.launch", process)] = env
+	}
+
+	for dir, env := range envs {
+		err := writeEnvironment(filepath.Join(layer.Path, dir), env)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(layer.ExecD) > 0 {
+		dir := filepath.Join(layer.Path, "exec.d")
+		err := os.MkdirAll(dir, os.ModePerm)
+		if err != nil {
+			return err
+		}
+
+		// packit prefixes the copies with their index, padded so that they
+		// sort in the order that the lifecycle runs them.
+		width := 1 + int(math.Log10(float64(len(layer.ExecD))))
+		for i, executable := range layer.ExecD {
+			err = copyFileAtomic(executable, filepath.Join(dir, fmt.Sprintf("%0*d-%s", width, i, filepath.Base(executable))))
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("file %s does not exist. Be sure to include it in the buildpack.toml", executable)
+				}
+				return err
+			}
+		}
+	}
+
+	layer.SharedEnv = packit.Environment{}
+	layer.LaunchEnv = packit.Environment{}
+	layer.BuildEnv = packit.Environment{}
+	layer.ProcessLaunchEnv = map[string]packit.Environment{}
+	layer.ExecD = nil
+
+	return nil
+}
+
+// writeEnvironment writes a file named after each variable of env into dir,
+// as packit does. An empty environment writes nothing.
+func writeEnvironment(dir string, env packit.Environment) error {
+	if len(env) == 0 {
+		return nil
+	}
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range env {
+		value := value
+		err = WriteFileAtomic(filepath.Join(dir, name), 0644, func(w io.Writer) error {
+			_, err := io.WriteString(w, value)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// zeroTimestamps sets the access and modification times of root and
+// everything below it to ReproducibleTime. Symlinks are left alone, as
+// os.Chtimes would change the time of their targets instead.
+func zeroTimestamps(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+
+		return os.Chtimes(path, ReproducibleTime, ReproducibleTime)
+	})
+}
@@ -0,0 +1,171 @@
+package npmstart
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// RevisionEnv is the launch environment variable that holds the revision
+	// of the app.
+	RevisionEnv = "APP_REVISION"
+
+	// VersionEnv is the launch environment variable that holds the version
+	// field of the package.json.
+	VersionEnv = "APP_VERSION"
+
+	// RevisionLabel and VersionLabel are the image labels that hold the
+	// values of RevisionEnv and VersionEnv.
+	RevisionLabel = "io.paketo.npm-start.revision"
+	VersionLabel  = "io.paketo.npm-start.version"
+
+	// ShortRevisionLength is the number of characters of a commit that a
+	// revision read from git keeps, as in "git rev-parse --short".
+	ShortRevisionLength = 7
+)
+
+// commitID matches the SHA-1 and SHA-256 object names of git.
+var commitID = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// lookupRevision returns the revision of the app: value, the value of
+// $BP_NPM_START_REVISION, or the short commit that HEAD of the git repository
+// in dir resolves to. The second return tells where the revision came from.
+// Both are empty when value is empty and dir holds no git repository.
+func lookupRevision(value, dir string) (string, string, error) {
+	if value != "" {
+		return value, "BP_NPM_START_REVISION", nil
+	}
+
+	commit, ok, err := gitRevision(dir)
+	if err != nil || !ok {
+		return "", "", err
+	}
+
+	return commit[:ShortRevisionLength], "the git HEAD of the app", nil
+}
+
+// gitRevision returns the commit that HEAD of the git repository in dir
+// resolves to, following a symbolic ref through the loose refs and then the
+// packed-refs file. A detached HEAD holds the commit itself. The boolean
+// return is false when dir holds no git repository.
+func gitRevision(dir string) (string, bool, error) {
+	gitDir, ok, err := locateGitDir(dir)
+	if err != nil || !ok {
+		return "", false, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	head := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(head, "ref:") {
+		if !commitID.MatchString(head) {
+			return "", false, fmt.Errorf("HEAD holds neither a ref nor a commit: %q", head)
+		}
+
+		return head, true, nil
+	}
+
+	// The refs of a linked worktree are those of the repository that its
+	// commondir file names.
+	refsDir := gitDir
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		refsDir = resolveGitPath(gitDir, strings.TrimSpace(string(common)))
+	}
+
+	ref := strings.TrimSpace(strings.TrimPrefix(head, "ref:"))
+	commit, err := resolveGitRef(refsDir, ref)
+	if err != nil {
+		return "", false, err
+	}
+
+	return commit, true, nil
+}
+
+// locateGitDir returns the git directory of the repository in dir. It is the
+// .git directory, or the directory that a .git file names, as in linked
+// worktrees and submodules.
+func locateGitDir(dir string) (string, bool, error) {
+	path := filepath.Join(dir, ".git")
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to stat .git: %w", err)
+	}
+
+	if info.IsDir() {
+		return path, true, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read .git: %w", err)
+	}
+
+	target := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(target, "gitdir:") {
+		return "", false, fmt.Errorf(".git is a file that does not name a git directory")
+	}
+
+	return resolveGitPath(dir, strings.TrimSpace(strings.TrimPrefix(target, "gitdir:"))), true, nil
+}
+
+// resolveGitPath resolves a path that git recorded relative to dir.
+func resolveGitPath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, filepath.FromSlash(path))
+}
+
+// resolveGitRef returns the commit of the named ref, from its loose ref file
+// or, once git gc has packed it, from the packed-refs file.
+func resolveGitRef(gitDir, ref string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(gitDir, filepath.FromSlash(ref)))
+	if err == nil {
+		commit := strings.TrimSpace(string(content))
+		if !commitID.MatchString(commit) {
+			return "", fmt.Errorf("%s does not hold a commit: %q", ref, commit)
+		}
+		return commit, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read %s: %w", ref, err)
+	}
+
+	file, err := os.Open(filepath.Join(gitDir, "packed-refs"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("HEAD refers to %s, which has no commits", ref)
+		}
+		return "", fmt.Errorf("failed to read packed-refs: %w", err)
+	}
+	defer file.Close()
+
+	// Lines hold "<commit> <ref>"; comments start with "#" and the commits
+	// that annotated tags peel to with "^".
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == ref && commitID.MatchString(fields[0]) {
+			return fields[0], nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read packed-refs: %w", err)
+	}
+
+	return "", fmt.Errorf("HEAD refers to %s, which has no commits", ref)
+}
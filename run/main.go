@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"os"
 
 	npmstart "github.com/paketo-buildpacks/npm-start"
@@ -9,13 +10,22 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "--self-check" {
+		if err := npmstart.SelfCheck(os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
 	projectPathParser := npmstart.NewProjectPathParser()
+	logger := scribe.NewEmitter(os.Stdout).WithLevel(os.Getenv("BP_LOG_LEVEL"))
+
+	tracer := npmstart.TracerFromEnvironment(os.Getenv)
+	ctx := tracer.Context(context.Background())
 
 	packit.Run(
-		npmstart.Detect(projectPathParser),
-		npmstart.Build(
-			projectPathParser,
-			scribe.NewEmitter(os.Stdout),
-		),
+		npmstart.TraceDetect(tracer, npmstart.DetectWithContext(ctx, projectPathParser, logger)),
+		npmstart.TraceBuild(tracer, npmstart.BuildWithContext(ctx, projectPathParser, logger)),
+		packit.WithExitHandler(npmstart.NewExitHandler(os.Stderr, os.Exit)),
 	)
 }
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestUnitRun(t *testing.T) {
+	suite := spec.New("run", spec.Report(report.Terminal{}))
+	suite("SelfCheck", testSelfCheck)
+	suite("ExitCodes", testExitCodes)
+	suite.Run(t)
+}
+
+// TestSelfCheckHelperProcess runs main when the test binary is re-executed
+// by testSelfCheck, so that the flag is handled by the compiled binary.
+func TestSelfCheckHelperProcess(t *testing.T) {
+	if os.Getenv("NPM_START_SELF_CHECK_HELPER") != "1" {
+		return
+	}
+
+	os.Args = []string{"run", "--self-check"}
+	main()
+	os.Exit(0)
+}
+
+// TestExitCodeHelperProcess runs main as the detect or build binary when the
+// test binary is re-executed by testExitCodes. The phase and its arguments
+// follow "--" on the command line.
+func TestExitCodeHelperProcess(t *testing.T) {
+	if os.Getenv("NPM_START_EXIT_CODE_HELPER") != "1" {
+		return
+	}
+
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+
+	os.Args = append([]string{filepath.Join("bin", args[0])}, args[1:]...)
+	main()
+	os.Exit(0)
+}
+
+func testSelfCheck(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	it("checks the binary and exits successfully", func() {
+		command := exec.Command(os.Args[0], "-test.run=TestSelfCheckHelperProcess")
+		command.Env = append(os.Environ(), "NPM_START_SELF_CHECK_HELPER=1")
+
+		output, err := command.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(output))
+		Expect(string(output)).To(ContainSubstring("OK: parse package.json"))
+		Expect(string(output)).To(ContainSubstring("OK: build start command: bash [-c echo prestart && node server.js && echo poststart]"))
+		Expect(string(output)).To(ContainSubstring("OK: write layers"))
+	})
+
+	it("does not depend on the build environment", func() {
+		command := exec.Command(os.Args[0], "-test.run=TestSelfCheckHelperProcess")
+		command.Env = append(os.Environ(), "NPM_START_SELF_CHECK_HELPER=1", "CNB_PLATFORM_API=0.10", "BP_NPM_START_FORWARD_PORT=true", "BP_LIVE_RELOAD_ENABLED=not-a-bool")
+
+		output, err := command.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(output))
+		Expect(string(output)).To(ContainSubstring("OK: parse package.json"))
+		Expect(string(output)).To(ContainSubstring("OK: build start command: bash [-c echo prestart && node server.js && echo poststart]"))
+		Expect(string(output)).To(ContainSubstring("OK: write layers"))
+	})
+
+	context("when a check fails", func() {
+		it("prints the failure and exits with a non-zero status", func() {
+			command := exec.Command(os.Args[0], "-test.run=TestSelfCheckHelperProcess")
+			command.Env = append(os.Environ(), "NPM_START_SELF_CHECK_HELPER=1", "TMPDIR="+filepath.Join(t.TempDir(), "missing"))
+
+			output, err := command.CombinedOutput()
+			Expect(err).To(HaveOccurred())
+			Expect(string(output)).To(ContainSubstring("FAILED: create temporary directory:"))
+		})
+	})
+}
+
+func testExitCodes(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		buildpackDir string
+		workingDir   string
+		layersDir    string
+		platformDir  string
+		planPath     string
+	)
+
+	it.Before(func() {
+		var err error
+		buildpackDir, err = filepath.Abs("..")
+		Expect(err).NotTo(HaveOccurred())
+
+		workingDir = t.TempDir()
+		layersDir = t.TempDir()
+		platformDir = t.TempDir()
+
+		planPath = filepath.Join(t.TempDir(), "plan.toml")
+		Expect(os.WriteFile(planPath, nil, 0600)).To(Succeed())
+	})
+
+	run := func(env []string, args ...string) int {
+		command := exec.Command(os.Args[0], append([]string{"-test.run=TestExitCodeHelperProcess", "--"}, args...)...)
+		command.Dir = workingDir
+		command.Env = append(os.Environ(), append([]string{
+			"NPM_START_EXIT_CODE_HELPER=1",
+			"CNB_BUILDPACK_DIR=" + buildpackDir,
+			"CNB_STACK_ID=some-stack",
+		}, env...)...)
+
+		output, err := command.CombinedOutput()
+		if err == nil {
+			return 0
+		}
+
+		exitErr, ok := err.(*exec.ExitError)
+		Expect(ok).To(BeTrue(), string(output))
+		return exitErr.ExitCode()
+	}
+
+	detect := func(env ...string) int {
+		return run(env, "detect", platformDir, planPath)
+	}
+
+	build := func(env ...string) int {
+		return run(env, "build", layersDir, platformDir, planPath)
+	}
+
+	context("when detection fails", func() {
+		it("exits with 100", func() {
+			Expect(detect()).To(Equal(100))
+		})
+	})
+
+	context("when package.json is missing at build", func() {
+		it("exits with 10", func() {
+			Expect(build()).To(Equal(10))
+		})
+	})
+
+	context("when there is no start script at build", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{
+				"bin": {"first": "bin/first.js", "second": "bin/second.js"}
+			}`), 0600)).To(Succeed())
+		})
+
+		it("exits with 11", func() {
+			Expect(build()).To(Equal(11))
+		})
+	})
+
+	context("when the configuration is invalid", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{
+				"scripts": {"start": "node server.js"}
+			}`), 0600)).To(Succeed())
+		})
+
+		it("exits with 12", func() {
+			Expect(detect("BP_LIVE_RELOAD_ENABLED=not-a-bool")).To(Equal(12))
+		})
+	})
+
+	context("when the project path cannot be resolved", func() {
+		it("exits with 13", func() {
+			Expect(detect("BP_NODE_PROJECT_PATH=missing")).To(Equal(13))
+		})
+	})
+
+	context("when package.json cannot be read", func() {
+		it.Before(func() {
+			Expect(os.Mkdir(filepath.Join(workingDir, "package.json"), os.ModePerm)).To(Succeed())
+		})
+
+		it("exits with 20", func() {
+			Expect(detect()).To(Equal(20))
+		})
+	})
+}
@@ -0,0 +1,170 @@
+package npmstart
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ScriptClass describes what a package.json script appears to do.
+type ScriptClass string
+
+const (
+	// ScriptClassUnknown is a script that could not be classified, which
+	// includes every script that starts a server.
+	ScriptClassUnknown ScriptClass = "unknown"
+
+	// ScriptClassBuild is a script that only runs a build tool and exits once
+	// the build completes.
+	ScriptClassBuild ScriptClass = "build"
+
+	// ScriptClassDevServer is a script that runs the development server of a
+	// frontend framework, whose production build is a static site.
+	ScriptClassDevServer ScriptClass = "dev-server"
+)
+
+var (
+	scriptSeparator = regexp.MustCompile(`&&|\|\||;`)
+	envAssignment   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+)
+
+// scriptLaunchers are commands that run the command given in their arguments.
+var scriptLaunchers = map[string]bool{
+	"cross-env": true,
+	"env":       true,
+	"npx":       true,
+}
+
+// buildTools maps the executables of known build tools to a function that
+// reports whether the given arguments make them run a one-off build.
+var buildTools = map[string]func(args []string) bool{
+	"webpack": func(args []string) bool {
+		return !hasWatchFlag(args) && !hasSubcommand(args, "serve", "server", "s")
+	},
+	"tsc": func(args []string) bool {
+		return !hasWatchFlag(args)
+	},
+	"rollup": func(args []string) bool {
+		return !hasWatchFlag(args)
+	},
+	"vite": func(args []string) bool {
+		return hasSubcommand(args, "build") && !hasWatchFlag(args)
+	},
+	"ng": func(args []string) bool {
+		return hasSubcommand(args, "build", "b") && !hasWatchFlag(args)
+	},
+}
+
+// devServers maps the executables of known frontend frameworks to a function
+// that reports whether the given arguments make them run a development server.
+var devServers = map[string]func(args []string) bool{
+	"react-scripts": func(args []string) bool {
+		return hasSubcommand(args, "start")
+	},
+	"vite": func(args []string) bool {
+		return !hasSubcommand(args, "build", "preview", "optimize")
+	},
+	"ng": func(args []string) bool {
+		return hasSubcommand(args, "serve", "s") && !hasProdFlag(args)
+	},
+}
+
+// staticSiteConfigs lists, for each executable in devServers, the files of
+// which at least one marks a project that builds to a static site.
+var staticSiteConfigs = map[string][]string{
+	"react-scripts": {"public/index.html"},
+	"vite":          {"vite.config.js", "vite.config.mjs", "vite.config.cjs", "vite.config.ts", "vite.config.mts"},
+	"ng":            {"angular.json"},
+}
+
+// ClassifyScript classifies the given package.json script. A script is only
+// classified as ScriptClassBuild when every command in it runs a build tool
+// in a mode that exits once the build completes, and as ScriptClassDevServer
+// when its last command runs a development server and every command before
+// it runs a build tool.
+func ClassifyScript(script string) ScriptClass {
+	class, _ := classifyScript(script)
+	return class
+}
+
+// classifyScript is ClassifyScript that also returns the executable of the
+// development server of a ScriptClassDevServer script.
+func classifyScript(script string) (ScriptClass, string) {
+	commands := scriptSeparator.Split(script, -1)
+
+	var classified int
+	for i, command := range commands {
+		fields := strings.Fields(command)
+		for len(fields) > 0 && (envAssignment.MatchString(fields[0]) || scriptLaunchers[fields[0]]) {
+			fields = fields[1:]
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Executables run from node_modules/.bin, as the start script is when
+		// Build replaces npx, are classified like the tools they install.
+		name := strings.TrimPrefix(fields[0], "node_modules/.bin/")
+
+		if isDevServer, ok := devServers[name]; ok && i == len(commands)-1 && isDevServer(fields[1:]) {
+			return ScriptClassDevServer, name
+		}
+
+		isBuild, ok := buildTools[name]
+		if !ok || !isBuild(fields[1:]) {
+			return ScriptClassUnknown, ""
+		}
+		classified++
+	}
+
+	if classified == 0 {
+		return ScriptClassUnknown, ""
+	}
+
+	return ScriptClassBuild, ""
+}
+
+// hasProdFlag reports whether the arguments select a production
+// configuration, as "--prod" or "--configuration production" do for ng.
+func hasProdFlag(args []string) bool {
+	for i, arg := range args {
+		switch {
+		case arg == "--prod", arg == "--configuration=production", arg == "-c=production":
+			return true
+		case (arg == "--configuration" || arg == "-c") && i+1 < len(args) && args[i+1] == "production":
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasWatchFlag(args []string) bool {
+	for _, arg := range args {
+		if arg == "-w" || arg == "--watch" || strings.HasPrefix(arg, "--watch=") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasSubcommand reports whether the first positional argument is one of the
+// given subcommands.
+func hasSubcommand(args []string, subcommands ...string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		for _, subcommand := range subcommands {
+			if arg == subcommand {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return false
+}
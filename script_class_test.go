@@ -0,0 +1,57 @@
+package npmstart_test
+
+import (
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testScriptClass(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ClassifyScript", func() {
+		it("classifies each script", func() {
+			for script, class := range map[string]npmstart.ScriptClass{
+				"webpack":                                  npmstart.ScriptClassBuild,
+				"webpack --mode production":                npmstart.ScriptClassBuild,
+				"NODE_ENV=production webpack":              npmstart.ScriptClassBuild,
+				"cross-env NODE_ENV=production webpack":    npmstart.ScriptClassBuild,
+				"npx tsc -p tsconfig.json":                 npmstart.ScriptClassBuild,
+				"tsc && rollup -c":                         npmstart.ScriptClassBuild,
+				"rollup -c":                                npmstart.ScriptClassBuild,
+				"node_modules/.bin/tsc":                    npmstart.ScriptClassBuild,
+				"vite build":                               npmstart.ScriptClassBuild,
+				"vite build --mode production":             npmstart.ScriptClassBuild,
+				"ng build --configuration production":      npmstart.ScriptClassBuild,
+				"webpack serve":                            npmstart.ScriptClassUnknown,
+				"webpack serve --mode development":         npmstart.ScriptClassUnknown,
+				"webpack --watch":                          npmstart.ScriptClassUnknown,
+				"tsc --watch":                              npmstart.ScriptClassUnknown,
+				"rollup -c -w":                             npmstart.ScriptClassUnknown,
+				"vite":                                     npmstart.ScriptClassDevServer,
+				"vite preview":                             npmstart.ScriptClassUnknown,
+				"vite build --watch":                       npmstart.ScriptClassUnknown,
+				"ng serve":                                 npmstart.ScriptClassDevServer,
+				"ng serve --prod":                          npmstart.ScriptClassUnknown,
+				"ng serve --configuration production":      npmstart.ScriptClassUnknown,
+				"react-scripts start":                      npmstart.ScriptClassDevServer,
+				"BROWSER=none react-scripts start":         npmstart.ScriptClassDevServer,
+				"react-scripts build":                      npmstart.ScriptClassUnknown,
+				"vite dev --port 3000":                     npmstart.ScriptClassDevServer,
+				"tsc && vite":                              npmstart.ScriptClassDevServer,
+				"vite && node server.js":                   npmstart.ScriptClassUnknown,
+				"tsc && node dist/server.js":               npmstart.ScriptClassUnknown,
+				"node server.js":                           npmstart.ScriptClassUnknown,
+				"some-webpack-wrapper --mode production":   npmstart.ScriptClassUnknown,
+				"":                                         npmstart.ScriptClassUnknown,
+				"NODE_ENV=production":                      npmstart.ScriptClassUnknown,
+				"webpack --mode production; node index.js": npmstart.ScriptClassUnknown,
+			} {
+				Expect(npmstart.ClassifyScript(script)).To(Equal(class), "classifying %q", script)
+			}
+		})
+	})
+}
@@ -0,0 +1,102 @@
+package npmstart
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+const selfCheckPackageJson = `{
+  "name": "self-check",
+  "scripts": {
+    "prestart": "echo prestart",
+    "start": "node server.js",
+    "poststart": "echo poststart"
+  }
+}`
+
+// SelfCheck exercises the package.json parser, the start command builder and
+// the layer handling of Build against a trivial app in a temporary
+// directory, printing a line for each step to w. It returns an error for the
+// first step that fails. The environment of the process is not read, so the
+// outcome does not depend on where the binary runs.
+func SelfCheck(w io.Writer) error {
+	dir, err := os.MkdirTemp("", "npm-start-self-check")
+	if err != nil {
+		fmt.Fprintf(w, "FAILED: create temporary directory: %s\n", err)
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	workingDir := filepath.Join(dir, "workspace")
+	layersDir := filepath.Join(dir, "layers")
+	for _, path := range []string{workingDir, layersDir} {
+		err = os.Mkdir(path, os.ModePerm)
+		if err != nil {
+			fmt.Fprintf(w, "FAILED: create temporary directory: %s\n", err)
+			return err
+		}
+	}
+
+	err = os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(selfCheckPackageJson), 0600)
+	if err != nil {
+		fmt.Fprintf(w, "FAILED: write package.json: %s\n", err)
+		return err
+	}
+
+	pkg, err := NewPackageJsonFromPath(filepath.Join(workingDir, "package.json"))
+	if err != nil {
+		fmt.Fprintf(w, "FAILED: parse package.json: %s\n", err)
+		return err
+	}
+
+	expected := "echo prestart && node server.js && echo poststart"
+	if chain := NewStartChain(*pkg, StartChainOptions{}).String(); chain != expected {
+		err = fmt.Errorf("expected start command %q, got %q", expected, chain)
+		fmt.Fprintf(w, "FAILED: assemble start command: %s\n", err)
+		return err
+	}
+	fmt.Fprintln(w, "OK: parse package.json")
+
+	// The build environment is fixed, so that the check computes the same
+	// start command wherever the binary runs.
+	environment := mapEnvironment(nil)
+	launch, err := computeLaunch(context.Background(), launchInputs{
+		WorkingDir: workingDir,
+		LayersPath: layersDir,
+		Plan:       packit.BuildpackPlan{Entries: []packit.BuildpackPlanEntry{}},
+		Env:        environment,
+		PathParser: ProjectPathParser{env: environment},
+	}, scribe.NewEmitter(io.Discard))
+	if err != nil {
+		fmt.Fprintf(w, "FAILED: build: %s\n", err)
+		return err
+	}
+
+	if len(launch.processes) == 0 {
+		err = fmt.Errorf("no launch processes")
+		fmt.Fprintf(w, "FAILED: build: %s\n", err)
+		return err
+	}
+	fmt.Fprintf(w, "OK: build start command: %s %v\n", launch.processes[0].Command, launch.processes[0].Args)
+
+	layer, err := resetLayer(packit.Layers{Path: layersDir}, "start")
+	if err != nil {
+		fmt.Fprintf(w, "FAILED: write layer: %s\n", err)
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(layer.Path, "self-check"), nil, 0600)
+	if err != nil {
+		fmt.Fprintf(w, "FAILED: write layer: %s\n", err)
+		return err
+	}
+	fmt.Fprintln(w, "OK: write layers")
+
+	return nil
+}
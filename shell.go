@@ -0,0 +1,28 @@
+package npmstart
+
+import (
+	"regexp"
+	"strings"
+)
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes the given word such that a POSIX shell reads it back as a
+// single, literal word.
+func shellQuote(word string) string {
+	if shellSafe.MatchString(word) {
+		return word
+	}
+
+	return "'" + strings.ReplaceAll(word, "'", `'"'"'`) + "'"
+}
+
+// shellJoin quotes and joins the given words into a single command line.
+func shellJoin(words ...string) string {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		quoted = append(quoted, shellQuote(word))
+	}
+
+	return strings.Join(quoted, " ")
+}
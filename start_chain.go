@@ -0,0 +1,321 @@
+package npmstart
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StartChainSegment is a single step of the start chain.
+type StartChainSegment struct {
+	// Name identifies the segment, eg. "prestart" or "start".
+	Name string
+
+	// Command is the shell command that the segment runs.
+	Command string
+
+	// Script indicates whether the command was taken from a package.json
+	// script rather than supplied by the buildpack.
+	Script bool
+}
+
+// StartChainOptions configures how a StartChain is assembled from a
+// package.json.
+type StartChainOptions struct {
+	// Entrypoint is the node entrypoint that is run when the package.json does
+	// not declare a start script.
+	Entrypoint string
+
+	// WorkingDir, when set, is changed into before any other segment is run.
+	WorkingDir string
+
+	// RunPrepare places the prepare script at the head of the chain.
+	RunPrepare bool
+
+	// ForwardArgs forwards arguments given to the process at launch to the
+	// start segment.
+	ForwardArgs bool
+
+	// LockArgs runs the start segment through the shell even when it could be
+	// run directly, so that the process has no arguments of its own that
+	// could be replaced at launch.
+	LockArgs bool
+
+	// ForwardPort passes the value of $PORT at launch to the start segment as
+	// a --port flag.
+	ForwardPort bool
+}
+
+// StartChain is the ordered list of commands that make up the start command
+// of an application.
+type StartChain struct {
+	Segments    []StartChainSegment
+	Entrypoint  string
+	ForwardArgs bool
+	LockArgs    bool
+	ForwardPort bool
+}
+
+// NewStartChain assembles the StartChain for the given package.json. The
+// segments are ordered as they are run at launch: changing into the working
+// directory, then prepare, prestart, start, and poststart.
+func NewStartChain(pkg PackageJson, options StartChainOptions) StartChain {
+	chain := StartChain{
+		Entrypoint:  options.Entrypoint,
+		ForwardArgs: options.ForwardArgs,
+		LockArgs:    options.LockArgs,
+		ForwardPort: options.ForwardPort,
+	}
+
+	// Ideally we would like the lifecycle to support setting a custom working
+	// directory to run the launch process.  Until that happens we will cd in.
+	if options.WorkingDir != "" {
+		chain.Segments = append(chain.Segments, StartChainSegment{
+			Name:    "cd",
+			Command: fmt.Sprintf("cd %s", options.WorkingDir),
+		})
+	}
+
+	if options.RunPrepare {
+		chain.appendScript("prepare", pkg.Scripts.Prepare)
+	}
+
+	chain.appendScript("prestart", pkg.Scripts.PreStart)
+
+	if !chain.appendScript("start", pkg.Scripts.Start) {
+		chain.Segments = append(chain.Segments, StartChainSegment{Name: "start", Command: fmt.Sprintf("node %s", options.Entrypoint)})
+	}
+
+	chain.appendScript("poststart", pkg.Scripts.PostStart)
+
+	return chain
+}
+
+// NewScriptChain assembles the chain that "npm run <name>" runs for the given
+// package.json script: changing into workingDir when it is set, then
+// pre<name>, <name>, and post<name>. The segments are named after the
+// scripts.
+func NewScriptChain(pkg PackageJson, name, workingDir string) StartChain {
+	var chain StartChain
+	if workingDir != "" {
+		chain.Segments = append(chain.Segments, StartChainSegment{
+			Name:    "cd",
+			Command: fmt.Sprintf("cd %s", workingDir),
+		})
+	}
+
+	for _, script := range []string{"pre" + name, name, "post" + name} {
+		command, _ := pkg.Scripts.Script(script)
+		chain.appendScript(script, command)
+	}
+
+	return chain
+}
+
+// appendScript appends the given package.json script as a segment, with
+// surrounding whitespace and a trailing ";" removed. Scripts that are empty
+// once trimmed are left out so that they cannot leave a dangling operator in
+// the joined command. It reports whether the segment was appended.
+func (c *StartChain) appendScript(name, script string) bool {
+	command := strings.TrimSpace(script)
+	if strings.HasSuffix(command, ";") && !strings.HasSuffix(command, `\;`) {
+		command = strings.TrimSpace(strings.TrimSuffix(command, ";"))
+	}
+
+	if command == "" {
+		return false
+	}
+
+	c.Segments = append(c.Segments, StartChainSegment{Name: name, Command: command, Script: true})
+	return true
+}
+
+// Validate returns an error when a segment begins or ends with a shell
+// operator, which would turn the joined command into a syntax error or change
+// how its segments are chained.
+func (c StartChain) Validate() error {
+	return c.validate(processExcerpter())
+}
+
+// validate is Validate, quoting the script with the excerpts of a build.
+func (c StartChain) validate(excerpts excerpter) error {
+	for _, segment := range c.Segments {
+		command := strings.TrimSpace(segment.Command)
+		if command == "" {
+			return fmt.Errorf("the %s segment of the start command is empty", segment.Name)
+		}
+
+		for _, operator := range []string{"&&", "||", "|", ";", "&"} {
+			dangling := strings.HasSuffix(command, operator) && !strings.HasSuffix(command, `\`+operator)
+			if strings.HasPrefix(command, operator) || dangling {
+				return fmt.Errorf("the %s script %s has a dangling %q", segment.Name, excerpts.quote(segment.Command), operator)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Start returns the start segment of the chain.
+func (c StartChain) Start() StartChainSegment {
+	for _, segment := range c.Segments {
+		if segment.Name == "start" {
+			return segment
+		}
+	}
+
+	return StartChainSegment{}
+}
+
+// HasStartScript indicates whether the start segment comes from the
+// package.json start script.
+func (c StartChain) HasStartScript() bool {
+	return c.Start().Script
+}
+
+// Direct indicates whether the chain consists of nothing but running the node
+// entrypoint, in which case it can be executed without a shell. A chain with
+// LockArgs set is never direct.
+func (c StartChain) Direct() bool {
+	return !c.ForwardPort && !c.LockArgs && len(c.Segments) == 1 && c.Segments[0].Name == "start" && !c.Segments[0].Script
+}
+
+// CheckPortForwarding returns an error when ForwardPort is set but the start
+// segment runs a node file, which has no --port flag to receive the port.
+func (c StartChain) CheckPortForwarding() error {
+	return c.checkPortForwarding(processExcerpter())
+}
+
+// checkPortForwarding is CheckPortForwarding, quoting the start command with
+// the excerpts of a build.
+func (c StartChain) checkPortForwarding(excerpts excerpter) error {
+	if !c.ForwardPort {
+		return nil
+	}
+
+	start := c.Start().Command
+	if _, ok := portArgs(start); !ok {
+		return classify(ErrInvalidConfiguration, fmt.Errorf("BP_NPM_START_FORWARD_PORT is set but the start command %s runs a node file, which does not accept a --port flag", excerpts.quote(start)))
+	}
+
+	return nil
+}
+
+// String returns the chain as a single shell command.
+func (c StartChain) String() string {
+	var commands []string
+	for _, segment := range c.Segments {
+		command := segment.Command
+		if segment.Name == "start" && c.ForwardPort {
+			if args, ok := portArgs(command); ok {
+				command = appendArgs(command, args)
+			}
+		}
+
+		if segment.Name == "start" && c.ForwardArgs {
+			command = appendArgs(command, `"$@"`)
+		}
+
+		commands = append(commands, command)
+	}
+
+	return strings.Join(commands, " && ")
+}
+
+// Executable returns the command and arguments that run the chain.
+func (c StartChain) Executable() (string, []string) {
+	if c.Direct() {
+		return "node", []string{c.Entrypoint}
+	}
+
+	args := []string{"-c", c.String()}
+	if c.ForwardArgs {
+		args = append(args, "bash")
+	}
+
+	return "bash", args
+}
+
+// MaxInlineCommandSize is the length in bytes above which the chain is not
+// passed to the shell inline but run from a script file. Linux refuses a
+// single argument longer than 128 KiB with E2BIG, and the margin leaves room
+// for the arguments that a command hook or live reload wraps around it.
+const MaxInlineCommandSize = 100 * 1024
+
+// StartCommandScript is the file in the start layer that holds the chain
+// when it is too long to be passed inline.
+const StartCommandScript = "start-command.sh"
+
+// Inline reports whether the chain can be passed to the shell as an argument,
+// rather than having to be run from a script file.
+func (c StartChain) Inline() bool {
+	return c.Direct() || len(c.String()) <= MaxInlineCommandSize
+}
+
+// Script returns the chain as the content of a bash script, for chains that
+// are not Inline.
+func (c StartChain) Script() string {
+	return "#!/usr/bin/env bash\n" + c.String() + "\n"
+}
+
+// ScriptExecutable returns the command and arguments that run the chain from
+// the script file at the given path. Arguments given to the process at
+// launch reach the script, and hence the start segment, as "$@".
+func (c StartChain) ScriptExecutable(path string) (string, []string) {
+	return "bash", []string{path}
+}
+
+// ArgsSeparators returns the number of "--" separators in the start
+// segment, after which arguments are appended.
+func (c StartChain) ArgsSeparators() int {
+	return argsSeparators(c.Start().Command)
+}
+
+func argsSeparators(command string) int {
+	var separators int
+	for _, field := range strings.Fields(command) {
+		if field == "--" {
+			separators++
+		}
+	}
+
+	return separators
+}
+
+// appendArgs appends args to the given command. npm only passes the
+// arguments after its "--" separator on to the script it runs, so the
+// separator is added to npm commands that have none. A command that already
+// has one, as in "npm run serve -- --flag", gets the arguments after it
+// rather than a second separator.
+func appendArgs(command, args string) string {
+	fields := strings.Fields(command)
+	if len(fields) > 0 && fields[0] == "npm" && argsSeparators(command) == 0 {
+		return fmt.Sprintf("%s -- %s", command, args)
+	}
+
+	return fmt.Sprintf("%s %s", command, args)
+}
+
+// portArgs returns the arguments that pass $PORT to the given command as a
+// --port flag. The boolean return is false when the command runs a node
+// file rather than a CLI installed in node_modules.
+func portArgs(command string) (string, bool) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	switch fields[0] {
+	case "node":
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "-") {
+				continue
+			}
+
+			return `--port "${PORT}"`, strings.Contains(field, "node_modules/")
+		}
+
+		return "", false
+	}
+
+	return `--port "${PORT}"`, true
+}
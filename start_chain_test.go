@@ -0,0 +1,476 @@
+package npmstart_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testStartChain(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		options npmstart.StartChainOptions
+	)
+
+	it.Before(func() {
+		options = npmstart.StartChainOptions{
+			Entrypoint: "/workspace/server.js",
+		}
+	})
+
+	context("NewStartChain", func() {
+		it("orders every hook around the start script", func() {
+			chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+				PreStart:  "some-prestart-command",
+				Start:     "some-start-command",
+				PostStart: "some-poststart-command",
+			}}, options)
+
+			Expect(chain.Segments).To(Equal([]npmstart.StartChainSegment{
+				{Name: "prestart", Command: "some-prestart-command", Script: true},
+				{Name: "start", Command: "some-start-command", Script: true},
+				{Name: "poststart", Command: "some-poststart-command", Script: true},
+			}))
+			Expect(chain.HasStartScript()).To(BeTrue())
+			Expect(chain.Direct()).To(BeFalse())
+			Expect(chain.String()).To(Equal("some-prestart-command && some-start-command && some-poststart-command"))
+		})
+
+		it("contains only the start script when there are no hooks", func() {
+			chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+				Start: "some-start-command",
+			}}, options)
+
+			Expect(chain.Segments).To(Equal([]npmstart.StartChainSegment{
+				{Name: "start", Command: "some-start-command", Script: true},
+			}))
+			Expect(chain.Direct()).To(BeFalse())
+
+			command, args := chain.Executable()
+			Expect(command).To(Equal("bash"))
+			Expect(args).To(Equal([]string{"-c", "some-start-command"}))
+		})
+
+		it("omits a missing prestart script", func() {
+			chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+				Start:     "some-start-command",
+				PostStart: "some-poststart-command",
+			}}, options)
+
+			Expect(chain.String()).To(Equal("some-start-command && some-poststart-command"))
+		})
+
+		it("omits a missing poststart script", func() {
+			chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+				PreStart: "some-prestart-command",
+				Start:    "some-start-command",
+			}}, options)
+
+			Expect(chain.String()).To(Equal("some-prestart-command && some-start-command"))
+		})
+
+		context("when there is no start script", func() {
+			it("runs the node entrypoint directly", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{}, options)
+
+				Expect(chain.Segments).To(Equal([]npmstart.StartChainSegment{
+					{Name: "start", Command: "node /workspace/server.js"},
+				}))
+				Expect(chain.HasStartScript()).To(BeFalse())
+				Expect(chain.Direct()).To(BeTrue())
+
+				command, args := chain.Executable()
+				Expect(command).To(Equal("node"))
+				Expect(args).To(Equal([]string{"/workspace/server.js"}))
+			})
+
+			it("runs the node entrypoint through the shell when there are hooks", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					PreStart:  "some-prestart-command",
+					PostStart: "some-poststart-command",
+				}}, options)
+
+				Expect(chain.HasStartScript()).To(BeFalse())
+				Expect(chain.Direct()).To(BeFalse())
+				Expect(chain.String()).To(Equal("some-prestart-command && node /workspace/server.js && some-poststart-command"))
+			})
+		})
+
+		context("when a working directory is given", func() {
+			it.Before(func() {
+				options.WorkingDir = "/workspace/some-project-dir"
+			})
+
+			it("changes into it first", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start: "some-start-command",
+				}}, options)
+
+				Expect(chain.Segments[0]).To(Equal(npmstart.StartChainSegment{Name: "cd", Command: "cd /workspace/some-project-dir"}))
+				Expect(chain.String()).To(Equal("cd /workspace/some-project-dir && some-start-command"))
+			})
+
+			it("no longer runs the node entrypoint directly", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{}, options)
+
+				Expect(chain.Direct()).To(BeFalse())
+				Expect(chain.String()).To(Equal("cd /workspace/some-project-dir && node /workspace/server.js"))
+			})
+		})
+
+		context("when the prepare script should be run", func() {
+			it.Before(func() {
+				options.RunPrepare = true
+			})
+
+			it("places it ahead of the prestart script", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Prepare:  "some-prepare-command",
+					PreStart: "some-prestart-command",
+					Start:    "some-start-command",
+				}}, options)
+
+				Expect(chain.String()).To(Equal("some-prepare-command && some-prestart-command && some-start-command"))
+			})
+
+			it("is a no-op when there is no prepare script", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start: "some-start-command",
+				}}, options)
+
+				Expect(chain.String()).To(Equal("some-start-command"))
+			})
+		})
+
+		context("when the prepare script should not be run", func() {
+			it("leaves it out of the chain", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Prepare: "some-prepare-command",
+					Start:   "some-start-command",
+				}}, options)
+
+				Expect(chain.String()).To(Equal("some-start-command"))
+			})
+		})
+
+		context("when launch arguments are forwarded", func() {
+			it.Before(func() {
+				options.ForwardArgs = true
+			})
+
+			it("appends them to the start segment", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start:     "some-start-command",
+					PostStart: "some-poststart-command",
+				}}, options)
+
+				command, args := chain.Executable()
+				Expect(command).To(Equal("bash"))
+				Expect(args).To(Equal([]string{"-c", `some-start-command "$@" && some-poststart-command`, "bash"}))
+			})
+		})
+
+		context("when launch arguments are locked", func() {
+			it.Before(func() {
+				options.LockArgs = true
+			})
+
+			it("runs the node entrypoint through the shell", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{}, options)
+
+				command, args := chain.Executable()
+				Expect(command).To(Equal("bash"))
+				Expect(args).To(Equal([]string{"-c", "node /workspace/server.js"}))
+			})
+		})
+
+		context("when the command is too long to be passed inline", func() {
+			// assignments returns a start script of exactly size bytes made of
+			// environment assignments ahead of the start command.
+			assignments := func(size int) string {
+				script := "some-start-command"
+				for i := 0; len(script) < size; i++ {
+					script = fmt.Sprintf("VAR_%d=value %s", i, script)
+				}
+				return script[len(script)-size:]
+			}
+
+			it("passes commands of up to MaxInlineCommandSize bytes inline", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start: assignments(npmstart.MaxInlineCommandSize),
+				}}, options)
+
+				Expect(chain.Inline()).To(BeTrue())
+			})
+
+			it("runs longer commands from a script file", func() {
+				start := assignments(npmstart.MaxInlineCommandSize + 1)
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start: start,
+				}}, options)
+
+				Expect(chain.Inline()).To(BeFalse())
+				Expect(chain.Script()).To(Equal("#!/usr/bin/env bash\n" + start + "\n"))
+
+				command, args := chain.ScriptExecutable("/layers/some buildpack/start/start-command.sh")
+				Expect(command).To(Equal("bash"))
+				Expect(args).To(Equal([]string{"/layers/some buildpack/start/start-command.sh"}))
+			})
+
+			context("when launch arguments are forwarded", func() {
+				it.Before(func() {
+					options.ForwardArgs = true
+				})
+
+				it("forwards the arguments of the script", func() {
+					chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+						Start: assignments(npmstart.MaxInlineCommandSize),
+					}}, options)
+
+					Expect(chain.Inline()).To(BeFalse())
+					Expect(chain.Script()).To(HaveSuffix(`some-start-command "$@"` + "\n"))
+				})
+			})
+		})
+
+		context("when the port is forwarded", func() {
+			it.Before(func() {
+				options.ForwardPort = true
+			})
+
+			it("forwards it after npm's separator for npm run commands", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start: "npm run serve",
+				}}, options)
+
+				Expect(chain.CheckPortForwarding()).To(Succeed())
+				Expect(chain.String()).To(Equal(`npm run serve -- --port "${PORT}"`))
+			})
+
+			it("does not repeat npm's separator when it is already present", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start: "npm run serve -- --mode production",
+				}}, options)
+
+				Expect(chain.String()).To(Equal(`npm run serve -- --mode production --port "${PORT}"`))
+			})
+
+			it("forwards it directly to CLIs", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start:     "vue-cli-service serve",
+					PostStart: "some-poststart-command",
+				}}, options)
+
+				Expect(chain.CheckPortForwarding()).To(Succeed())
+				Expect(chain.String()).To(Equal(`vue-cli-service serve --port "${PORT}" && some-poststart-command`))
+			})
+
+			it("forwards it directly to CLIs run by node from node_modules", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start: "node --inspect node_modules/@vue/cli-service/bin/vue-cli-service.js serve",
+				}}, options)
+
+				Expect(chain.CheckPortForwarding()).To(Succeed())
+				Expect(chain.String()).To(Equal(`node --inspect node_modules/@vue/cli-service/bin/vue-cli-service.js serve --port "${PORT}"`))
+			})
+
+			it("rejects node files", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start: "node server.js",
+				}}, options)
+
+				Expect(chain.CheckPortForwarding()).To(MatchError(`BP_NPM_START_FORWARD_PORT is set but the start command "node server.js" runs a node file, which does not accept a --port flag`))
+			})
+
+			it("rejects the default node entrypoint", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{}, options)
+
+				Expect(chain.CheckPortForwarding()).To(MatchError(ContainSubstring("runs a node file")))
+			})
+
+			context("when launch arguments are forwarded as well", func() {
+				it.Before(func() {
+					options.ForwardArgs = true
+				})
+
+				it("places the port ahead of the launch arguments", func() {
+					chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+						Start: "npm run serve",
+					}}, options)
+
+					Expect(chain.String()).To(Equal(`npm run serve -- --port "${PORT}" "$@"`))
+				})
+			})
+		})
+
+		context("when the start script already has npm's separator", func() {
+			it("appends every forwarded argument after the last separator", func() {
+				fixtures := []struct {
+					start       string
+					forwardPort bool
+					forwardArgs bool
+					expected    string
+				}{
+					{start: "npm run serve", forwardPort: true, expected: `npm run serve -- --port "${PORT}"`},
+					{start: "npm run serve", forwardArgs: true, expected: `npm run serve -- "$@"`},
+					{start: "npm run serve", forwardPort: true, forwardArgs: true, expected: `npm run serve -- --port "${PORT}" "$@"`},
+					{start: "npm run serve -- --flag", forwardPort: true, expected: `npm run serve -- --flag --port "${PORT}"`},
+					{start: "npm run serve -- --flag", forwardArgs: true, expected: `npm run serve -- --flag "$@"`},
+					{start: "npm run serve -- --flag", forwardPort: true, forwardArgs: true, expected: `npm run serve -- --flag --port "${PORT}" "$@"`},
+					{start: "npm run serve -- --flag -- --other", forwardPort: true, expected: `npm run serve -- --flag -- --other --port "${PORT}"`},
+					{start: "npm run serve -- --flag -- --other", forwardArgs: true, expected: `npm run serve -- --flag -- --other "$@"`},
+					{start: "npm run serve -- --flag -- --other", forwardPort: true, forwardArgs: true, expected: `npm run serve -- --flag -- --other --port "${PORT}" "$@"`},
+					{start: "node server.js -- --flag", forwardArgs: true, expected: `node server.js -- --flag "$@"`},
+				}
+
+				for _, fixture := range fixtures {
+					options.ForwardPort = fixture.forwardPort
+					options.ForwardArgs = fixture.forwardArgs
+
+					chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+						Start: fixture.start,
+					}}, options)
+
+					Expect(chain.String()).To(Equal(fixture.expected), fmt.Sprintf("%+v", fixture))
+				}
+			})
+
+			it("counts the separators of the start segment", func() {
+				for start, separators := range map[string]int{
+					"npm run serve":                      0,
+					"npm run serve -- --flag":            1,
+					"npm run serve -- --flag -- --other": 2,
+				} {
+					chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+						PreStart: "some-prestart-command -- --ignored",
+						Start:    start,
+					}}, options)
+
+					Expect(chain.ArgsSeparators()).To(Equal(separators), start)
+				}
+			})
+		})
+
+		context("NewScriptChain", func() {
+			it("runs the script between its pre and post scripts, as npm run does", func() {
+				pkg := npmstart.PackageJson{Scripts: npmstart.PackageScripts{}}
+				Expect(json.Unmarshal([]byte(`{"scripts": {"prehealthcheck": "some-pre-command", "healthcheck": "some-command", "posthealthcheck": "some-post-command;"}}`), &pkg)).To(Succeed())
+
+				chain := npmstart.NewScriptChain(pkg, "healthcheck", "/some/project")
+				Expect(chain.Validate()).To(Succeed())
+				Expect(chain.Inline()).To(BeTrue())
+				Expect(chain.String()).To(Equal("cd /some/project && some-pre-command && some-command && some-post-command"))
+
+				command, args := chain.Executable()
+				Expect(command).To(Equal("bash"))
+				Expect(args).To(Equal([]string{"-c", chain.String()}))
+			})
+		})
+
+		context("when scripts are blank or padded", func() {
+			it("leaves blank scripts out and trims the others", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					PreStart:  "   ",
+					Start:     "  some-start-command;  ",
+					PostStart: "\tsome-poststart-command\n",
+				}}, options)
+
+				Expect(chain.Segments).To(Equal([]npmstart.StartChainSegment{
+					{Name: "start", Command: "some-start-command", Script: true},
+					{Name: "poststart", Command: "some-poststart-command", Script: true},
+				}))
+				Expect(chain.Validate()).To(Succeed())
+				Expect(chain.String()).To(Equal("some-start-command && some-poststart-command"))
+			})
+
+			it("treats a blank start script as missing", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					Start: " ",
+				}}, options)
+
+				Expect(chain.HasStartScript()).To(BeFalse())
+				Expect(chain.Direct()).To(BeTrue())
+			})
+
+			it("keeps an escaped trailing semicolon", func() {
+				chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+					PreStart: `find . -name '*.tmp' -exec rm {} \;`,
+					Start:    "some-start-command",
+				}}, options)
+
+				Expect(chain.Validate()).To(Succeed())
+				Expect(chain.String()).To(Equal(`find . -name '*.tmp' -exec rm {} \; && some-start-command`))
+			})
+		})
+
+		context("every combination of optional segments", func() {
+			it("joins the present segments into a minimal, valid command", func() {
+				for combination := 0; combination < 1<<5; combination++ {
+					options := npmstart.StartChainOptions{Entrypoint: "/workspace/server.js"}
+					var pkg npmstart.PackageJson
+					var expected []string
+
+					if combination&1 != 0 {
+						options.WorkingDir = "/workspace/some-project-dir"
+						expected = append(expected, "cd /workspace/some-project-dir")
+					}
+
+					if combination&2 != 0 {
+						options.RunPrepare = true
+						pkg.Scripts.Prepare = "some-prepare-command"
+						expected = append(expected, "some-prepare-command")
+					}
+
+					if combination&4 != 0 {
+						pkg.Scripts.PreStart = "some-prestart-command"
+						expected = append(expected, "some-prestart-command")
+					}
+
+					if combination&8 != 0 {
+						pkg.Scripts.Start = "some-start-command"
+						expected = append(expected, "some-start-command")
+					} else {
+						expected = append(expected, "node /workspace/server.js")
+					}
+
+					if combination&16 != 0 {
+						pkg.Scripts.PostStart = "some-poststart-command"
+						expected = append(expected, "some-poststart-command")
+					}
+
+					chain := npmstart.NewStartChain(pkg, options)
+					Expect(chain.Validate()).To(Succeed())
+					Expect(chain.String()).To(Equal(strings.Join(expected, " && ")), "combination %05b", combination)
+					Expect(chain.String()).NotTo(ContainSubstring("  "), "combination %05b", combination)
+				}
+			})
+		})
+
+		context("when a script has a dangling operator", func() {
+			it("fails validation", func() {
+				for script, operator := range map[string]string{
+					"some-prestart-command &&": "&&",
+					"&& some-prestart-command": "&&",
+					"some-prestart-command ||": "||",
+					"some-prestart-command |":  "|",
+					"; some-prestart-command":  ";",
+					"some-prestart-command &":  "&",
+				} {
+					chain := npmstart.NewStartChain(npmstart.PackageJson{Scripts: npmstart.PackageScripts{
+						PreStart: script,
+						Start:    "some-start-command",
+					}}, options)
+
+					Expect(chain.Validate()).To(MatchError(fmt.Sprintf("the prestart script %q has a dangling %q", script, operator)))
+				}
+			})
+		})
+	})
+}
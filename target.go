@@ -0,0 +1,95 @@
+package npmstart
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+)
+
+// PackagePlatforms is the value of the "os" or "cpu" field of a
+// package.json. npm accepts either a single string or a list of strings.
+type PackagePlatforms []string
+
+func (p *PackagePlatforms) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*p = PackagePlatforms{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	*p = list
+	return nil
+}
+
+// Allows reports whether value is permitted by the list, following npm's
+// semantics: a matching negated entry ("!value") excludes it, and otherwise
+// it must match a non-negated entry unless every entry is negated. The list
+// ["any"] allows everything, as does an empty list; elsewhere in a list "any"
+// is an ordinary entry that matches no target.
+func (p PackagePlatforms) Allows(value string) bool {
+	if len(p) == 0 || (len(p) == 1 && p[0] == "any") {
+		return true
+	}
+
+	var match bool
+	var negated int
+	for _, entry := range p {
+		if strings.HasPrefix(entry, "!") {
+			if strings.TrimPrefix(entry, "!") == value {
+				return false
+			}
+			negated++
+			continue
+		}
+
+		if entry == value {
+			match = true
+		}
+	}
+
+	return match || negated == len(p)
+}
+
+// npmOS and npmCPU translate Go's platform names into the names used by
+// Node.js (process.platform and process.arch) where the two differ.
+var (
+	npmOS = map[string]string{
+		"windows": "win32",
+	}
+
+	npmCPU = map[string]string{
+		"386":     "ia32",
+		"amd64":   "x64",
+		"ppc64le": "ppc64",
+	}
+)
+
+// lookupTarget returns the operating system and architecture of the build
+// target in Node.js terms, as set by $CNB_TARGET_OS and $CNB_TARGET_ARCH, or
+// those of the running binary when those are unset.
+func lookupTarget(env Environment) (string, string) {
+	targetOS := env.get("CNB_TARGET_OS")
+	if targetOS == "" {
+		targetOS = runtime.GOOS
+	}
+
+	targetArch := env.get("CNB_TARGET_ARCH")
+	if targetArch == "" {
+		targetArch = runtime.GOARCH
+	}
+
+	if name, ok := npmOS[targetOS]; ok {
+		targetOS = name
+	}
+
+	if name, ok := npmCPU[targetArch]; ok {
+		targetArch = name
+	}
+
+	return targetOS, targetArch
+}
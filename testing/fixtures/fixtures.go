@@ -0,0 +1,321 @@
+// Package fixtures generates the apps that the integration tests of
+// npm-start build, from a declarative App spec. Builders that compose
+// npm-start can import it to test against the same apps as the buildpack
+// itself; the fields of App and the files that Generate writes are kept
+// stable across releases.
+package fixtures
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultGreeting is the body that the server of an App responds with unless
+// its Greeting sets another.
+const DefaultGreeting = "Hello, World!"
+
+// App is the spec of a fixture app: an HTTP server on $PORT, started by the
+// start script of its package.json.
+type App struct {
+	// Name is the name of the package.
+	Name string
+
+	// ProjectPath is the directory, relative to the app, that holds the
+	// package.json. Env passes it as BP_NODE_PROJECT_PATH.
+	ProjectPath string
+
+	// Workspace makes the app an npm workspace whose only member is the
+	// package in ProjectPath.
+	Workspace bool
+
+	// Hooks adds prestart and poststart scripts that echo their names.
+	Hooks bool
+
+	// GracefulShutdown makes the server log "echo from SIGTERM handler" and
+	// close on SIGTERM.
+	GracefulShutdown bool
+
+	// LiveReload enables live reload through BP_LIVE_RELOAD_ENABLED.
+	LiveReload bool
+
+	// Jobs are scripts that Env adds as one-off processes through
+	// BP_NPM_START_JOBS.
+	Jobs []Job
+
+	// Greeting is the body that the server responds with; DefaultGreeting
+	// when empty.
+	Greeting string
+}
+
+// Job is a script of the package.json that runs once as a process of its
+// own.
+type Job struct {
+	Name   string
+	Script string
+}
+
+// jobName matches the script names that are usable as process types.
+var jobName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Scenarios returns the apps of the feature matrix that the integration
+// tests of npm-start build, by name.
+func Scenarios() map[string]App {
+	return map[string]App{
+		"start-command": {
+			Name:     "simple_app",
+			Hooks:    true,
+			Greeting: "hello world",
+		},
+		"graceful-shutdown": {
+			Name:             "graceful_shutdown_app",
+			GracefulShutdown: true,
+			Greeting:         "hello world",
+		},
+		"project-path": {
+			Name:        "project_path_app",
+			ProjectPath: "server",
+			Hooks:       true,
+		},
+		"live-reload": {
+			Name:        "live_reload_app",
+			ProjectPath: "server",
+			Hooks:       true,
+			LiveReload:  true,
+		},
+		"jobs": {
+			Name: "jobs_app",
+			Jobs: []Job{
+				{Name: "migrate", Script: `echo "migrating"`},
+			},
+		},
+		"workspace": {
+			Name:        "workspace_app",
+			ProjectPath: "packages/server",
+			Workspace:   true,
+		},
+	}
+}
+
+// Validate reports whether the app can be generated.
+func (a App) Validate() error {
+	if a.Name == "" {
+		return errors.New("the app has no name")
+	}
+
+	if a.ProjectPath != "" {
+		clean := path.Clean(filepath.ToSlash(a.ProjectPath))
+		if path.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("project path %q is not a directory within the app", a.ProjectPath)
+		}
+	}
+
+	if a.Workspace && a.ProjectPath == "" {
+		return errors.New("a workspace needs a project path to hold its member package")
+	}
+
+	taken := map[string]bool{"start": true}
+	if a.Hooks {
+		taken["prestart"] = true
+		taken["poststart"] = true
+	}
+
+	seen := map[string]bool{}
+	for _, job := range a.Jobs {
+		switch {
+		case !jobName.MatchString(job.Name):
+			return fmt.Errorf("job %q has a name that is not usable as a process type", job.Name)
+		case taken[job.Name]:
+			return fmt.Errorf("job %q has the name of another script", job.Name)
+		case seen[job.Name]:
+			return fmt.Errorf("job %q is listed twice", job.Name)
+		case job.Script == "":
+			return fmt.Errorf("job %q has no script", job.Name)
+		}
+		seen[job.Name] = true
+	}
+
+	return nil
+}
+
+// Env returns the build environment that the app is built with.
+func (a App) Env() map[string]string {
+	env := map[string]string{}
+	if a.ProjectPath != "" {
+		env["BP_NODE_PROJECT_PATH"] = filepath.ToSlash(a.ProjectPath)
+	}
+
+	if a.LiveReload {
+		env["BP_LIVE_RELOAD_ENABLED"] = "true"
+	}
+
+	if len(a.Jobs) > 0 {
+		var names []string
+		for _, job := range a.Jobs {
+			names = append(names, job.Name)
+		}
+		env["BP_NPM_START_JOBS"] = strings.Join(names, ",")
+	}
+
+	return env
+}
+
+// PackageJSON returns the package.json of the app, in the project path.
+func (a App) PackageJSON() ([]byte, error) {
+	err := a.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(map[string]interface{}{
+		"name":        a.Name,
+		"version":     "0.0.0",
+		"description": "a fixture app of npm-start",
+		"license":     "",
+		"scripts":     a.scripts(),
+		"dependencies": map[string]string{
+			"leftpad": "~0.0.1",
+		},
+		"engines": map[string]string{
+			"node": "~14",
+		},
+	})
+}
+
+// WorkspaceJSON returns the package.json of the workspace root, for apps
+// that are a Workspace.
+func (a App) WorkspaceJSON() ([]byte, error) {
+	err := a.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if !a.Workspace {
+		return nil, fmt.Errorf("app %q is not a workspace", a.Name)
+	}
+
+	return marshal(map[string]interface{}{
+		"name":       a.Name + "-workspace",
+		"version":    "0.0.0",
+		"private":    true,
+		"license":    "",
+		"workspaces": []string{path.Clean(filepath.ToSlash(a.ProjectPath))},
+	})
+}
+
+// Generate writes the app into dir, which is created when it does not
+// exist.
+func (a App) Generate(dir string) error {
+	pkg, err := a.PackageJSON()
+	if err != nil {
+		return err
+	}
+
+	projectDir := filepath.Join(dir, filepath.FromSlash(a.ProjectPath))
+	err = os.MkdirAll(projectDir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create the project directory: %w", err)
+	}
+
+	files := map[string][]byte{
+		filepath.Join(projectDir, "package.json"): pkg,
+		filepath.Join(projectDir, "server.js"):    []byte(a.server()),
+	}
+
+	if a.Workspace {
+		root, err := a.WorkspaceJSON()
+		if err != nil {
+			return err
+		}
+		files[filepath.Join(dir, "package.json")] = root
+	}
+
+	for name, content := range files {
+		err = os.WriteFile(name, content, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// scripts returns the scripts of the package.json of the app.
+func (a App) scripts() map[string]string {
+	scripts := map[string]string{
+		"start": `echo "start" && node server.js`,
+	}
+
+	if a.Hooks {
+		scripts["prestart"] = `echo "prestart"`
+		scripts["poststart"] = `echo "poststart"`
+	}
+
+	for _, job := range a.Jobs {
+		scripts[job.Name] = job.Script
+	}
+
+	return scripts
+}
+
+// server returns the server.js of the app.
+func (a App) server() string {
+	greeting := a.Greeting
+	if greeting == "" {
+		greeting = DefaultGreeting
+	}
+
+	// A JSON string is a valid JavaScript string literal.
+	literal, _ := json.Marshal(greeting)
+
+	lines := []string{
+		"const http = require('http');",
+		"const leftpad = require('leftpad');",
+		"",
+		"const port = process.env.PORT || 8080;",
+		"",
+		"const server = http.createServer((request, response) => {",
+		fmt.Sprintf("  response.end(%s);", literal),
+		"});",
+		"",
+	}
+
+	if a.GracefulShutdown {
+		lines = append(lines,
+			"process.once('SIGTERM', function () {",
+			"  console.log('echo from SIGTERM handler');",
+			"  server.close();",
+			"});",
+			"",
+		)
+	}
+
+	lines = append(lines,
+		"server.listen(port, (err) => {",
+		"  if (err) {",
+		"    return console.log('something bad happened', err);",
+		"  }",
+		"",
+		"  console.log(`server is listening on ${port}`);",
+		"});",
+		"",
+	)
+
+	return strings.Join(lines, "\n")
+}
+
+// marshal returns value as indented JSON with a trailing newline. The keys
+// of maps are sorted, so that the files are the same on every run.
+func marshal(value map[string]interface{}) ([]byte, error) {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(content, '\n'), nil
+}
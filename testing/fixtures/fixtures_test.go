@@ -0,0 +1,198 @@
+package fixtures_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/paketo-buildpacks/npm-start/testing/fixtures"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testApp(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		dir string
+	)
+
+	it.Before(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "fixture")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	context("PackageJSON", func() {
+		it("emits the start script and the dependency that the server requires", func() {
+			content, err := fixtures.App{Name: "some-app"}.PackageJSON()
+			Expect(err).NotTo(HaveOccurred())
+
+			var pkg struct {
+				Name         string            `json:"name"`
+				Scripts      map[string]string `json:"scripts"`
+				Dependencies map[string]string `json:"dependencies"`
+			}
+			Expect(json.Unmarshal(content, &pkg)).To(Succeed())
+			Expect(pkg.Name).To(Equal("some-app"))
+			Expect(pkg.Scripts).To(Equal(map[string]string{
+				"start": `echo "start" && node server.js`,
+			}))
+			Expect(pkg.Dependencies).To(HaveKey("leftpad"))
+		})
+
+		it("emits the hooks and the scripts of the jobs", func() {
+			content, err := fixtures.App{
+				Name:  "some-app",
+				Hooks: true,
+				Jobs:  []fixtures.Job{{Name: "migrate", Script: `echo "migrating"`}},
+			}.PackageJSON()
+			Expect(err).NotTo(HaveOccurred())
+
+			var pkg struct {
+				Scripts map[string]string `json:"scripts"`
+			}
+			Expect(json.Unmarshal(content, &pkg)).To(Succeed())
+			Expect(pkg.Scripts).To(Equal(map[string]string{
+				"prestart":  `echo "prestart"`,
+				"start":     `echo "start" && node server.js`,
+				"poststart": `echo "poststart"`,
+				"migrate":   `echo "migrating"`,
+			}))
+		})
+
+		it("is the same on every call", func() {
+			app := fixtures.Scenarios()["jobs"]
+
+			first, err := app.PackageJSON()
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := app.PackageJSON()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(Equal(first))
+		})
+	})
+
+	context("Env", func() {
+		it("configures the build for the features of the app", func() {
+			Expect(fixtures.App{
+				Name:        "some-app",
+				ProjectPath: "server",
+				LiveReload:  true,
+				Jobs: []fixtures.Job{
+					{Name: "migrate", Script: "echo migrate"},
+					{Name: "seed", Script: "echo seed"},
+				},
+			}.Env()).To(Equal(map[string]string{
+				"BP_NODE_PROJECT_PATH":   "server",
+				"BP_LIVE_RELOAD_ENABLED": "true",
+				"BP_NPM_START_JOBS":      "migrate,seed",
+			}))
+
+			Expect(fixtures.App{Name: "some-app"}.Env()).To(BeEmpty())
+		})
+	})
+
+	context("Generate", func() {
+		it("writes the package.json and server into the project path", func() {
+			app := fixtures.App{Name: "some-app", ProjectPath: "server", Greeting: "hi"}
+			Expect(app.Generate(dir)).To(Succeed())
+
+			pkg, err := npmstart.NewPackageJsonFromPath(filepath.Join(dir, "server", "package.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pkg.Scripts.Start).To(Equal(`echo "start" && node server.js`))
+
+			server, err := os.ReadFile(filepath.Join(dir, "server", "server.js"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(server)).To(ContainSubstring(`response.end("hi");`))
+			Expect(string(server)).NotTo(ContainSubstring("SIGTERM"))
+
+			Expect(filepath.Join(dir, "package.json")).NotTo(BeAnExistingFile())
+		})
+
+		it("writes a workspace root that lists the project path", func() {
+			Expect(fixtures.Scenarios()["workspace"].Generate(dir)).To(Succeed())
+
+			content, err := os.ReadFile(filepath.Join(dir, "package.json"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var root struct {
+				Private    bool     `json:"private"`
+				Workspaces []string `json:"workspaces"`
+			}
+			Expect(json.Unmarshal(content, &root)).To(Succeed())
+			Expect(root.Private).To(BeTrue())
+			Expect(root.Workspaces).To(Equal([]string{"packages/server"}))
+			Expect(filepath.Join(dir, "packages", "server", "package.json")).To(BeAnExistingFile())
+		})
+
+		it("adds a SIGTERM handler for a graceful shutdown", func() {
+			Expect(fixtures.Scenarios()["graceful-shutdown"].Generate(dir)).To(Succeed())
+
+			server, err := os.ReadFile(filepath.Join(dir, "server.js"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(server)).To(ContainSubstring("console.log('echo from SIGTERM handler');"))
+		})
+	})
+
+	context("Scenarios", func() {
+		it("generates apps that the buildpack previews with the expected processes", func() {
+			expected := map[string][]string{
+				"start-command":     {"web"},
+				"graceful-shutdown": {"web"},
+				"project-path":      {"web"},
+				"live-reload":       {"web", "no-reload"},
+				"jobs":              {"web", "migrate"},
+				"workspace":         {"web"},
+			}
+			Expect(fixtures.Scenarios()).To(HaveLen(len(expected)))
+
+			for name, app := range fixtures.Scenarios() {
+				scenarioDir := filepath.Join(dir, name)
+				Expect(app.Generate(scenarioDir)).To(Succeed(), name)
+
+				previews, err := npmstart.Preview(scenarioDir, app.Env())
+				Expect(err).NotTo(HaveOccurred(), name)
+
+				var types []string
+				for _, preview := range previews {
+					types = append(types, preview.Type)
+				}
+				Expect(types).To(Equal(expected[name]), name)
+			}
+		})
+	})
+
+	context("failure cases", func() {
+		it("rejects apps that cannot be generated", func() {
+			for _, app := range []fixtures.App{
+				{},
+				{Name: "some-app", ProjectPath: "../outside"},
+				{Name: "some-app", ProjectPath: "/absolute"},
+				{Name: "some-app", Workspace: true},
+				{Name: "some-app", Jobs: []fixtures.Job{{Name: "db:migrate", Script: "echo"}}},
+				{Name: "some-app", Jobs: []fixtures.Job{{Name: "start", Script: "echo"}}},
+				{Name: "some-app", Hooks: true, Jobs: []fixtures.Job{{Name: "prestart", Script: "echo"}}},
+				{Name: "some-app", Jobs: []fixtures.Job{{Name: "migrate", Script: "echo"}, {Name: "migrate", Script: "echo"}}},
+				{Name: "some-app", Jobs: []fixtures.Job{{Name: "migrate"}}},
+			} {
+				Expect(app.Validate()).To(HaveOccurred(), app.Name+" "+app.ProjectPath)
+				Expect(app.Generate(dir)).To(HaveOccurred())
+			}
+
+			Expect(filepath.Join(dir, "package.json")).NotTo(BeAnExistingFile())
+		})
+
+		it("fails to write a workspace root for an app that is not a workspace", func() {
+			_, err := fixtures.App{Name: "some-app"}.WorkspaceJSON()
+			Expect(err).To(MatchError(`app "some-app" is not a workspace`))
+		})
+	})
+}
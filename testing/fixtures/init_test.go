@@ -0,0 +1,14 @@
+package fixtures_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitFixtures(t *testing.T) {
+	suite := spec.New("fixtures", spec.Report(report.Terminal{}))
+	suite("App", testApp)
+	suite.Run(t)
+}
@@ -0,0 +1,447 @@
+package npmstart
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/chronos"
+)
+
+const (
+	// TraceParentEnv holds the W3C traceparent of the build that runs the
+	// buildpack, eg. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+	TraceParentEnv = "TRACEPARENT"
+
+	// OTLPEndpointEnv holds the base URL of the OTLP/HTTP collector that spans
+	// are exported to, as in the OpenTelemetry SDKs.
+	OTLPEndpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+	// OTLPExportTimeout bounds the export of the spans of a phase, so that an
+	// unreachable collector does not hold up the build.
+	OTLPExportTimeout = 500 * time.Millisecond
+)
+
+var traceParentPattern = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+// Span is a finished span of the detect or build phase, or of one of their
+// steps.
+type Span struct {
+	Name         string
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]string
+
+	// Err is the message of the error the span ended with, or empty.
+	Err string
+}
+
+// SpanExporter sends finished spans to a collector.
+//
+//go:generate faux --interface SpanExporter --output fakes/span_exporter.go
+type SpanExporter interface {
+	Export(ctx context.Context, spans []Span) error
+}
+
+// Tracer records the spans of a phase as a child of the span of the build
+// that runs the buildpack. A nil *Tracer records nothing, so that callers
+// need not check whether tracing is configured.
+type Tracer struct {
+	traceID  string
+	parentID string
+	exporter SpanExporter
+	clock    chronos.Clock
+
+	m     sync.Mutex
+	phase *Span
+	spans []Span
+}
+
+// NewTracer returns a tracer for the given traceparent. It returns an error
+// when the traceparent is malformed. Spans of a trace that the traceparent
+// marks as not sampled are not recorded.
+func NewTracer(traceParent string, exporter SpanExporter, clock chronos.Clock) (*Tracer, error) {
+	matches := traceParentPattern.FindStringSubmatch(traceParent)
+	if matches == nil || matches[1] == "ff" || strings.Trim(matches[2], "0") == "" || strings.Trim(matches[3], "0") == "" {
+		return nil, fmt.Errorf("failed to parse %s value %s: not a W3C traceparent", TraceParentEnv, traceParent)
+	}
+
+	flags, _ := strconv.ParseUint(matches[4], 16, 8)
+	if flags&1 == 0 {
+		return nil, nil
+	}
+
+	return &Tracer{
+		traceID:  matches[2],
+		parentID: matches[3],
+		exporter: exporter,
+		clock:    clock,
+	}, nil
+}
+
+// TracerFromEnvironment returns a tracer that exports to the collector at
+// OTEL_EXPORTER_OTLP_ENDPOINT as a child of TRACEPARENT. It returns nil when
+// either is unset or the traceparent is malformed, as tracing never fails a
+// build.
+func TracerFromEnvironment(getenv func(string) string) *Tracer {
+	endpoint, traceParent := getenv(OTLPEndpointEnv), getenv(TraceParentEnv)
+	if endpoint == "" || traceParent == "" {
+		return nil
+	}
+
+	tracer, err := NewTracer(traceParent, NewOTLPExporter(endpoint), chronos.DefaultClock)
+	if err != nil {
+		return nil
+	}
+
+	return tracer
+}
+
+type tracerKey struct{}
+
+// Context returns ctx with the tracer attached. The steps that Detect and
+// Build run under a context from DetectWithContext and BuildWithContext are
+// recorded as children of the span of the phase.
+func (t *Tracer) Context(ctx context.Context) context.Context {
+	if t == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// Spans returns the spans recorded and not yet exported.
+func (t *Tracer) Spans() []Span {
+	if t == nil {
+		return nil
+	}
+
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	return append([]Span(nil), t.spans...)
+}
+
+// Flush exports the recorded spans within OTLPExportTimeout.
+func (t *Tracer) Flush(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+
+	t.m.Lock()
+	spans := t.spans
+	t.spans = nil
+	t.m.Unlock()
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OTLPExportTimeout)
+	defer cancel()
+
+	return t.exporter.Export(ctx, spans)
+}
+
+func (t *Tracer) start(name, parentID string) *Span {
+	return &Span{
+		Name:         name,
+		TraceID:      t.traceID,
+		SpanID:       newSpanID(),
+		ParentSpanID: parentID,
+		Start:        t.clock.Now(),
+		Attributes:   map[string]string{},
+	}
+}
+
+func (t *Tracer) end(span *Span, err error) {
+	span.End = t.clock.Now()
+	if err != nil {
+		span.Err = err.Error()
+	}
+
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.spans = append(t.spans, *span)
+}
+
+// trace runs the phase f in a span named after it and exports the spans of
+// the phase when it returns. The error of the export is dropped.
+func (t *Tracer) trace(name string, f func() (map[string]string, error)) error {
+	span := t.start(name, t.parentID)
+
+	t.m.Lock()
+	t.phase = span
+	t.m.Unlock()
+
+	attributes, err := f()
+
+	t.m.Lock()
+	t.phase = nil
+	for key, value := range attributes {
+		span.Attributes[key] = value
+	}
+	t.m.Unlock()
+
+	t.end(span, err)
+	_ = t.Flush(context.Background())
+
+	return err
+}
+
+// TraceDetect records detect as the "detect" span of the tracer.
+func TraceDetect(tracer *Tracer, detect packit.DetectFunc) packit.DetectFunc {
+	if tracer == nil {
+		return detect
+	}
+
+	return func(context packit.DetectContext) (packit.DetectResult, error) {
+		var (
+			result packit.DetectResult
+			err    error
+		)
+
+		// packit.Fail is the outcome of the phase rather than an error of its
+		// span, and is returned as is.
+		_ = tracer.trace("detect", func() (map[string]string, error) {
+			result, err = detect(context)
+			return map[string]string{"npm_start.detect.passed": strconv.FormatBool(err == nil)}, ignoreFail(err)
+		})
+
+		return result, err
+	}
+}
+
+// TraceBuild records build as the "build" span of the tracer.
+func TraceBuild(tracer *Tracer, build packit.BuildFunc) packit.BuildFunc {
+	if tracer == nil {
+		return build
+	}
+
+	return func(context packit.BuildContext) (packit.BuildResult, error) {
+		var result packit.BuildResult
+		err := tracer.trace("build", func() (map[string]string, error) {
+			var err error
+			result, err = build(context)
+
+			var types []string
+			for _, process := range result.Launch.Processes {
+				types = append(types, process.Type)
+			}
+
+			return map[string]string{"npm_start.processes": strings.Join(types, ",")}, err
+		})
+
+		return result, err
+	}
+}
+
+func ignoreFail(err error) error {
+	fail := packit.Fail
+	if errors.As(err, &fail) {
+		return nil
+	}
+
+	return err
+}
+
+// traceStep records a step of the phase traced by the tracer in ctx, and
+// returns the function that ends it.
+func traceStep(ctx context.Context, name string) func(error) {
+	t, _ := ctx.Value(tracerKey{}).(*Tracer)
+	if t == nil {
+		return func(error) {}
+	}
+
+	t.m.Lock()
+	phase := t.phase
+	t.m.Unlock()
+
+	if phase == nil {
+		return func(error) {}
+	}
+
+	span := t.start(name, phase.SpanID)
+	return func(err error) {
+		t.end(span, err)
+	}
+}
+
+// traceAttribute sets an attribute of the span of the phase traced by the
+// tracer in ctx.
+func traceAttribute(ctx context.Context, key, value string) {
+	t, _ := ctx.Value(tracerKey{}).(*Tracer)
+	if t == nil {
+		return
+	}
+
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.phase != nil {
+		t.phase.Attributes[key] = value
+	}
+}
+
+// traceScripts records the names of the scripts of the package.json as the
+// npm_start.scripts attribute of the phase.
+func traceScripts(ctx context.Context, scripts PackageScripts) {
+	var names []string
+	for name := range scripts.all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	traceAttribute(ctx, "npm_start.scripts", strings.Join(names, ","))
+}
+
+// traceFeatures records the enabled build options as the npm_start.features
+// attribute of the phase.
+func traceFeatures(ctx context.Context, enabled map[string]bool) {
+	var names []string
+	for name, ok := range enabled {
+		if ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	traceAttribute(ctx, "npm_start.features", strings.Join(names, ","))
+}
+
+func newSpanID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// OTLPExporter exports spans to an OTLP/HTTP collector as JSON.
+type OTLPExporter struct {
+	url    string
+	client *http.Client
+}
+
+// NewOTLPExporter returns an exporter for the collector at the given base
+// URL. Spans are posted to its /v1/traces path.
+func NewOTLPExporter(endpoint string) OTLPExporter {
+	return OTLPExporter{
+		url:    strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		client: &http.Client{Timeout: OTLPExportTimeout},
+	}
+}
+
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	} `json:"status"`
+}
+
+func otlpAttributes(attributes map[string]string) []otlpAttribute {
+	var keys []string
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result []otlpAttribute
+	for _, key := range keys {
+		attribute := otlpAttribute{Key: key}
+		attribute.Value.StringValue = attributes[key]
+		result = append(result, attribute)
+	}
+
+	return result
+}
+
+// Export posts the spans to the collector.
+func (e OTLPExporter) Export(ctx context.Context, spans []Span) error {
+	var converted []otlpSpan
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              span.Name,
+			Kind:              1,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+		if span.Err != "" {
+			s.Status.Code = 2
+			s.Status.Message = span.Err
+		}
+		converted = append(converted, s)
+	}
+
+	payload := map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]string{"service.name": "npm-start"}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]string{"name": "github.com/paketo-buildpacks/npm-start"},
+						"spans": converted,
+					},
+				},
+			},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to export spans: collector responded with %s", response.Status)
+	}
+
+	return nil
+}
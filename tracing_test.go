@@ -0,0 +1,344 @@
+package npmstart_test
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/paketo-buildpacks/npm-start/fakes"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/chronos"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testTracing(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+		workingDir string
+		exporter   *fakes.SpanExporter
+		clock      chronos.Clock
+		tracer     *npmstart.Tracer
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{"scripts": {"prestart": "some-prestart-command", "start": "some-start-command"}}`), 0600)).To(Succeed())
+
+		now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+		clock = chronos.NewClock(func() time.Time {
+			now = now.Add(time.Second)
+			return now
+		})
+
+		exporter = &fakes.SpanExporter{}
+		tracer, err = npmstart.NewTracer(traceParent, exporter, clock)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	spansNamed := func(spans []npmstart.Span, name string) []npmstart.Span {
+		var named []npmstart.Span
+		for _, span := range spans {
+			if span.Name == name {
+				named = append(named, span)
+			}
+		}
+		return named
+	}
+
+	context("TraceDetect", func() {
+		it("exports the detect span with a child span per step", func() {
+			detect := npmstart.TraceDetect(tracer, npmstart.DetectWithContext(tracer.Context(gocontext.Background()), npmstart.NewProjectPathParser(), scribe.NewEmitter(io.Discard)))
+
+			_, err := detect(packit.DetectContext{WorkingDir: workingDir})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(exporter.ExportCall.CallCount).To(Equal(1))
+			spans := exporter.ExportCall.Receives.Spans
+
+			phases := spansNamed(spans, "detect")
+			Expect(phases).To(HaveLen(1))
+			phase := phases[0]
+			Expect(phase.TraceID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+			Expect(phase.ParentSpanID).To(Equal("00f067aa0ba902b7"))
+			Expect(phase.End).To(BeTemporally(">", phase.Start))
+			Expect(phase.Err).To(BeEmpty())
+			Expect(phase.Attributes).To(Equal(map[string]string{
+				"npm_start.detect.passed": "true",
+				"npm_start.scripts":       "prestart,start",
+			}))
+
+			steps := spansNamed(spans, "resolving the project path")
+			Expect(steps).To(HaveLen(1))
+			Expect(steps[0].ParentSpanID).To(Equal(phase.SpanID))
+			Expect(steps[0].TraceID).To(Equal(phase.TraceID))
+			Expect(steps[0].Start).To(BeTemporally(">", phase.Start))
+			Expect(steps[0].End).To(BeTemporally("<", phase.End))
+
+			Expect(spansNamed(spans, "reading package.json")).NotTo(BeEmpty())
+			Expect(tracer.Spans()).To(BeEmpty())
+		})
+
+		it("records a failed detection without an error status", func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "package.json"), []byte(`{}`), 0600)).To(Succeed())
+
+			detect := npmstart.TraceDetect(tracer, npmstart.DetectWithContext(tracer.Context(gocontext.Background()), npmstart.NewProjectPathParser(), scribe.NewEmitter(io.Discard)))
+
+			_, err := detect(packit.DetectContext{WorkingDir: workingDir})
+			Expect(err).To(MatchError(npmstart.NoStartScriptError))
+
+			phase := spansNamed(exporter.ExportCall.Receives.Spans, "detect")[0]
+			Expect(phase.Err).To(BeEmpty())
+			Expect(phase.Attributes).To(HaveKeyWithValue("npm_start.detect.passed", "false"))
+		})
+
+		it("does not fail when the spans cannot be exported", func() {
+			exporter.ExportCall.Returns.Error = errors.New("connection refused")
+
+			detect := npmstart.TraceDetect(tracer, npmstart.DetectWithContext(tracer.Context(gocontext.Background()), npmstart.NewProjectPathParser(), scribe.NewEmitter(io.Discard)))
+
+			_, err := detect(packit.DetectContext{WorkingDir: workingDir})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exporter.ExportCall.CallCount).To(Equal(1))
+		})
+
+		it("exports with a timeout", func() {
+			detect := npmstart.TraceDetect(tracer, npmstart.DetectWithContext(gocontext.Background(), npmstart.NewProjectPathParser(), scribe.NewEmitter(io.Discard)))
+
+			_, err := detect(packit.DetectContext{WorkingDir: workingDir})
+			Expect(err).NotTo(HaveOccurred())
+
+			deadline, ok := exporter.ExportCall.Receives.Ctx.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(time.Until(deadline)).To(BeNumerically("<=", npmstart.OTLPExportTimeout))
+		})
+	})
+
+	context("TraceBuild", func() {
+		var (
+			layersDir string
+			cnbDir    string
+		)
+
+		it.Before(func() {
+			var err error
+			layersDir, err = os.MkdirTemp("", "layers")
+			Expect(err).NotTo(HaveOccurred())
+
+			cnbDir, err = os.MkdirTemp("", "cnb")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.Mkdir(filepath.Join(cnbDir, "bin"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "wait-for-bindings"), nil, 0755)).To(Succeed())
+
+			os.Setenv("BP_NPM_START_SLIM", "true")
+		})
+
+		it.After(func() {
+			os.Unsetenv("BP_NPM_START_SLIM")
+			Expect(os.RemoveAll(layersDir)).To(Succeed())
+			Expect(os.RemoveAll(cnbDir)).To(Succeed())
+		})
+
+		it("exports the build span with the features used and the processes built", func() {
+			build := npmstart.TraceBuild(tracer, npmstart.BuildWithNode(tracer.Context(gocontext.Background()), npmstart.NewProjectPathParser(), &fakes.Executable{}, scribe.NewEmitter(io.Discard)))
+
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			spans := exporter.ExportCall.Receives.Spans
+			phase := spansNamed(spans, "build")[0]
+			Expect(phase.ParentSpanID).To(Equal("00f067aa0ba902b7"))
+			Expect(phase.Attributes).To(Equal(map[string]string{
+				"npm_start.features":  "BP_NPM_START_SLIM",
+				"npm_start.processes": "web",
+				"npm_start.scripts":   "prestart,start",
+			}))
+
+			steps := spansNamed(spans, "locating node_modules")
+			Expect(steps).NotTo(BeEmpty())
+			Expect(steps[0].ParentSpanID).To(Equal(phase.SpanID))
+		})
+
+		it("names each step of the build after what it does", func() {
+			build := npmstart.TraceBuild(tracer, npmstart.BuildWithNode(tracer.Context(gocontext.Background()), npmstart.NewProjectPathParser(), &fakes.Executable{}, scribe.NewEmitter(io.Discard)))
+
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			spans := exporter.ExportCall.Receives.Spans
+			phase := spansNamed(spans, "build")[0]
+
+			var names []string
+			for _, span := range spans {
+				if span.ParentSpanID == phase.SpanID {
+					names = append(names, span.Name)
+				}
+			}
+			Expect(names).To(Equal([]string{
+				"resolving the project path",
+				"reading package.json",
+				"locating node_modules",
+				"resolving local bin paths",
+			}))
+		})
+
+		it("records the error of a failed build", func() {
+			os.Setenv("BP_NPM_START_MAINTENANCE", "sometimes")
+			defer os.Unsetenv("BP_NPM_START_MAINTENANCE")
+
+			build := npmstart.TraceBuild(tracer, npmstart.BuildWithNode(tracer.Context(gocontext.Background()), npmstart.NewProjectPathParser(), &fakes.Executable{}, scribe.NewEmitter(io.Discard)))
+
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).To(HaveOccurred())
+
+			phase := spansNamed(exporter.ExportCall.Receives.Spans, "build")[0]
+			Expect(phase.Err).To(Equal(err.Error()))
+		})
+	})
+
+	context("NewTracer", func() {
+		it("does not record a trace that is not sampled", func() {
+			tracer, err := npmstart.NewTracer("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", exporter, clock)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tracer).To(BeNil())
+		})
+
+		it("rejects malformed traceparents", func() {
+			for _, value := range []string{
+				"",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+				"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			} {
+				_, err := npmstart.NewTracer(value, exporter, clock)
+				Expect(err).To(MatchError(ContainSubstring("not a W3C traceparent")), value)
+			}
+		})
+	})
+
+	context("TracerFromEnvironment", func() {
+		it("returns nil unless both the endpoint and the traceparent are set", func() {
+			for _, env := range []map[string]string{
+				{},
+				{npmstart.TraceParentEnv: traceParent},
+				{npmstart.OTLPEndpointEnv: "http://collector:4318"},
+				{npmstart.OTLPEndpointEnv: "http://collector:4318", npmstart.TraceParentEnv: "malformed"},
+			} {
+				Expect(npmstart.TracerFromEnvironment(func(name string) string { return env[name] })).To(BeNil())
+			}
+		})
+
+		it("leaves Detect untraced without a tracer", func() {
+			var tracer *npmstart.Tracer
+
+			detect := npmstart.TraceDetect(tracer, npmstart.DetectWithContext(tracer.Context(gocontext.Background()), npmstart.NewProjectPathParser(), scribe.NewEmitter(io.Discard)))
+
+			_, err := detect(packit.DetectContext{WorkingDir: workingDir})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tracer.Spans()).To(BeNil())
+		})
+	})
+
+	context("OTLPExporter", func() {
+		it("posts the spans to the traces path of the collector", func() {
+			var (
+				path    string
+				payload struct {
+					ResourceSpans []struct {
+						ScopeSpans []struct {
+							Spans []map[string]interface{} `json:"spans"`
+						} `json:"scopeSpans"`
+					} `json:"resourceSpans"`
+				}
+			)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+				Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+			}))
+			defer server.Close()
+
+			err := npmstart.NewOTLPExporter(server.URL+"/").Export(gocontext.Background(), []npmstart.Span{
+				{
+					Name:         "detect",
+					TraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+					SpanID:       "b7ad6b7169203331",
+					ParentSpanID: "00f067aa0ba902b7",
+					Start:        time.Unix(1, 0),
+					End:          time.Unix(2, 0),
+					Attributes:   map[string]string{"npm_start.scripts": "start"},
+					Err:          "some-error",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(path).To(Equal("/v1/traces"))
+			span := payload.ResourceSpans[0].ScopeSpans[0].Spans[0]
+			Expect(span).To(HaveKeyWithValue("name", "detect"))
+			Expect(span).To(HaveKeyWithValue("traceId", "4bf92f3577b34da6a3ce929d0e0e4736"))
+			Expect(span).To(HaveKeyWithValue("spanId", "b7ad6b7169203331"))
+			Expect(span).To(HaveKeyWithValue("parentSpanId", "00f067aa0ba902b7"))
+			Expect(span).To(HaveKeyWithValue("startTimeUnixNano", "1000000000"))
+			Expect(span).To(HaveKeyWithValue("endTimeUnixNano", "2000000000"))
+			Expect(span).To(HaveKeyWithValue("attributes", []interface{}{
+				map[string]interface{}{"key": "npm_start.scripts", "value": map[string]interface{}{"stringValue": "start"}},
+			}))
+			Expect(span).To(HaveKeyWithValue("status", map[string]interface{}{"code": float64(2), "message": "some-error"}))
+		})
+
+		it("returns an error when the collector rejects the spans", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			err := npmstart.NewOTLPExporter(server.URL).Export(gocontext.Background(), []npmstart.Span{{Name: "detect"}})
+			Expect(err).To(MatchError("failed to export spans: collector responded with 503 Service Unavailable"))
+		})
+
+		it("gives up quickly when the collector is unreachable", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			server.Close()
+
+			start := time.Now()
+			err := npmstart.NewOTLPExporter(server.URL).Export(gocontext.Background(), []npmstart.Span{{Name: "detect"}})
+			Expect(err).To(MatchError(ContainSubstring("failed to export spans")))
+			Expect(time.Since(start)).To(BeNumerically("<", npmstart.OTLPExportTimeout+time.Second))
+		})
+	})
+}
@@ -0,0 +1,7 @@
+package npmstart
+
+// WaitForBindings is the name of the exec.d helper, shipped in the buildpack
+// bin directory, that holds back the launch of every process until the
+// services named by BPL_NPM_START_WAIT_FOR_BINDINGS are reachable. It does
+// nothing when the variable is unset.
+const WaitForBindings = "wait-for-bindings"
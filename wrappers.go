@@ -0,0 +1,79 @@
+package npmstart
+
+import (
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// Command is an executable and its arguments, as a direct process runs them.
+type Command struct {
+	Executable string
+	Args       []string
+}
+
+// String returns the command as a shell command line.
+func (c Command) String() string {
+	return shellJoin(append([]string{c.Executable}, c.Args...)...)
+}
+
+const (
+	// WrapperScriptFile moves a start command that is too long to be passed
+	// inline into a script file.
+	WrapperScriptFile = "script-file"
+
+	// WrapperCommandHook rewrites the command with BP_NPM_START_COMMAND_HOOK.
+	WrapperCommandHook = "command-hook"
+
+	// WrapperLiveReload restarts the command when files change.
+	WrapperLiveReload = "live-reload"
+)
+
+// Wrapper is a transform of the start command by a single feature. Wrap is
+// only called when Enabled is set.
+type Wrapper struct {
+	Name    string
+	Enabled bool
+	Wrap    func(Command) (Command, error)
+}
+
+// WrapperPipeline is a list of wrappers, innermost first: each wrapper wraps
+// the command that the wrappers before it produced.
+type WrapperPipeline []Wrapper
+
+// Apply returns the command wrapped by every enabled wrapper of the pipeline,
+// in order. When trace is set, the command is logged after each wrapper.
+func (p WrapperPipeline) Apply(command Command, trace bool, logger scribe.Emitter) (Command, error) {
+	return p.apply(command, trace, processExcerpter(), logger)
+}
+
+// apply is Apply, logging the command with the excerpts of a build.
+func (p WrapperPipeline) apply(command Command, trace bool, excerpts excerpter, logger scribe.Emitter) (Command, error) {
+	if trace {
+		logger.Process("Wrapping the start command (BP_NPM_START_TRACE_WRAPPERS=true)")
+		logger.Subprocess("start: %s", excerpts.excerpt(command.String()))
+	}
+
+	for _, wrapper := range p {
+		if !wrapper.Enabled {
+			if trace {
+				logger.Subprocess("%s: not enabled", wrapper.Name)
+			}
+			continue
+		}
+
+		var err error
+		command, err = wrapper.Wrap(command)
+		if err != nil {
+			return Command{}, err
+		}
+
+		if trace {
+			logger.Subprocess("%s: %s", wrapper.Name, excerpts.excerpt(command.String()))
+		}
+	}
+
+	if trace {
+		logger.Break()
+	}
+
+	return command, nil
+}
@@ -0,0 +1,78 @@
+package npmstart_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	npmstart "github.com/paketo-buildpacks/npm-start"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testWrappers(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		buffer *bytes.Buffer
+		logger scribe.Emitter
+	)
+
+	it.Before(func() {
+		buffer = bytes.NewBuffer(nil)
+		logger = scribe.NewEmitter(buffer)
+	})
+
+	wrapWith := func(executable string) func(npmstart.Command) (npmstart.Command, error) {
+		return func(c npmstart.Command) (npmstart.Command, error) {
+			return npmstart.Command{Executable: executable, Args: append([]string{c.Executable}, c.Args...)}, nil
+		}
+	}
+
+	it("applies the enabled wrappers innermost first", func() {
+		pipeline := npmstart.WrapperPipeline{
+			{Name: "inner", Enabled: true, Wrap: wrapWith("inner")},
+			{Name: "skipped", Enabled: false, Wrap: wrapWith("skipped")},
+			{Name: "outer", Enabled: true, Wrap: wrapWith("outer")},
+		}
+
+		command, err := pipeline.Apply(npmstart.Command{Executable: "node", Args: []string{"server.js"}}, false, logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(command).To(Equal(npmstart.Command{Executable: "outer", Args: []string{"inner", "node", "server.js"}}))
+		Expect(buffer.String()).To(BeEmpty())
+	})
+
+	it("logs the command after each wrapper when tracing", func() {
+		pipeline := npmstart.WrapperPipeline{
+			{Name: "inner", Enabled: true, Wrap: wrapWith("inner")},
+			{Name: "skipped", Enabled: false, Wrap: wrapWith("skipped")},
+			{Name: "outer", Enabled: true, Wrap: wrapWith("outer")},
+		}
+
+		_, err := pipeline.Apply(npmstart.Command{Executable: "node", Args: []string{"some server.js"}}, true, logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal(`  Wrapping the start command (BP_NPM_START_TRACE_WRAPPERS=true)
+    start: node 'some server.js'
+    inner: inner node 'some server.js'
+    skipped: not enabled
+    outer: outer inner node 'some server.js'
+
+`))
+	})
+
+	it("stops at the first wrapper that fails", func() {
+		pipeline := npmstart.WrapperPipeline{
+			{Name: "failing", Enabled: true, Wrap: func(npmstart.Command) (npmstart.Command, error) {
+				return npmstart.Command{}, errors.New("some-error")
+			}},
+			{Name: "outer", Enabled: true, Wrap: func(npmstart.Command) (npmstart.Command, error) {
+				panic("not reached")
+			}},
+		}
+
+		_, err := pipeline.Apply(npmstart.Command{Executable: "node"}, false, logger)
+		Expect(err).To(MatchError("some-error"))
+	})
+}